@@ -0,0 +1,143 @@
+package httpx
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// BasicAuth returns the username and password from the request's
+// Authorization header, if it uses HTTP Basic authentication (RFC 7617).
+func (r *Request) BasicAuth() (username, password string, ok bool) {
+	auth := r.Header.Get("Authorization")
+	const prefix = "Basic "
+	if len(auth) < len(prefix) || !strings.EqualFold(auth[:len(prefix)], prefix) {
+		return "", "", false
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(auth[len(prefix):]))
+	if err != nil {
+		return "", "", false
+	}
+	username, password, ok = strings.Cut(string(raw), ":")
+	if !ok {
+		return "", "", false
+	}
+	return username, password, true
+}
+
+// SetBasicAuth sets the Authorization header to use HTTP Basic authentication
+// with the provided credentials. The username must not contain a colon.
+func (r *Request) SetBasicAuth(username, password string) {
+	cred := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+	r.Header.Set("Authorization", "Basic "+cred)
+}
+
+// BearerToken returns the token from an "Authorization: Bearer <token>"
+// header (RFC 6750 §2.1).
+func (r *Request) BearerToken() (token string, ok bool) {
+	auth := r.Header.Get("Authorization")
+	const prefix = "Bearer "
+	if len(auth) < len(prefix) || !strings.EqualFold(auth[:len(prefix)], prefix) {
+		return "", false
+	}
+	token = strings.TrimSpace(auth[len(prefix):])
+	return token, token != ""
+}
+
+// CheckBasicAuth reports whether r carries Basic credentials equal to
+// username and password. The comparison is constant-time with respect to
+// the contents of both credentials.
+func CheckBasicAuth(r *Request, username, password string) bool {
+	u, p, ok := r.BasicAuth()
+	if !ok {
+		return false
+	}
+	// Bitwise & so both comparisons always run.
+	return secureCompare(u, username)&secureCompare(p, password) == 1
+}
+
+// CheckBearerToken reports whether r carries a bearer token equal to want,
+// using a constant-time comparison.
+func CheckBearerToken(r *Request, want string) bool {
+	tok, ok := r.BearerToken()
+	return ok && secureCompare(tok, want) == 1
+}
+
+// secureCompare compares a and b in constant time, independent of their
+// lengths, by comparing fixed-size digests. It returns 1 on equality.
+func secureCompare(a, b string) int {
+	ha := sha256.Sum256([]byte(a))
+	hb := sha256.Sum256([]byte(b))
+	return subtle.ConstantTimeCompare(ha[:], hb[:])
+}
+
+// BasicChallenge returns a WWW-Authenticate value for Basic auth in realm.
+// A realm holding control characters fails with ErrInvalidValue.
+func BasicChallenge(realm string) (string, error) {
+	q, err := quoteString(realm)
+	if err != nil {
+		return "", err
+	}
+	return `Basic realm=` + q + `, charset="UTF-8"`, nil
+}
+
+// BearerChallenge returns a WWW-Authenticate value for Bearer auth
+// (RFC 6750 §3). errCode (e.g. "invalid_token") may be empty. Values
+// holding control characters fail with ErrInvalidValue.
+func BearerChallenge(realm, errCode string) (string, error) {
+	q, err := quoteString(realm)
+	if err != nil {
+		return "", err
+	}
+	v := `Bearer realm=` + q
+	if errCode != "" {
+		if q, err = quoteString(errCode); err != nil {
+			return "", err
+		}
+		v += `, error=` + q
+	}
+	return v, nil
+}
+
+// quoteString returns s as a quoted-string (RFC 9110 §5.6.4), escaping
+// only '"' and '\'. Control characters other than HTAB cannot appear in
+// one and are rejected with ErrInvalidValue.
+func quoteString(s string) (string, error) {
+	var b strings.Builder
+	b.Grow(len(s) + 2)
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c < 0x20 && c != '\t', c == 0x7f:
+			return "", fmt.Errorf("%w: control character in %q", ErrInvalidValue, s)
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+	b.WriteByte('"')
+	return b.String(), nil
+}
+
+// WriteUnauthorized writes a 401 response carrying the given
+// WWW-Authenticate challenges.
+func WriteUnauthorized(ctx context.Context, w io.Writer, challenges ...string) error {
+	const body = "Unauthorized\n"
+	h := Header{}
+	for _, c := range challenges {
+		h.Add("WWW-Authenticate", c)
+	}
+	h.Set("Content-Type", "text/plain; charset=utf-8")
+	h.Set("Content-Length", strconv.Itoa(len(body)))
+	return WriteResponse(ctx, w, &Response{
+		StatusCode: StatusUnauthorized,
+		Header:     h,
+		Body:       strings.NewReader(body),
+	})
+}
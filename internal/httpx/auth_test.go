@@ -0,0 +1,99 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestBasicAuthRoundTrip(t *testing.T) {
+	r := &Request{Header: Header{}}
+	if _, _, ok := r.BasicAuth(); ok {
+		t.Fatal("expected no credentials")
+	}
+	r.SetBasicAuth("alice", "s3:cret")
+	if got := r.Header.Get("Authorization"); got != "Basic YWxpY2U6czM6Y3JldA==" {
+		t.Fatalf("Authorization = %q", got)
+	}
+	u, p, ok := r.BasicAuth()
+	if !ok || u != "alice" || p != "s3:cret" {
+		t.Fatalf("BasicAuth = %q %q %v", u, p, ok)
+	}
+	if !CheckBasicAuth(r, "alice", "s3:cret") {
+		t.Fatal("CheckBasicAuth should accept matching credentials")
+	}
+	if CheckBasicAuth(r, "alice", "wrong") || CheckBasicAuth(r, "bob", "s3:cret") {
+		t.Fatal("CheckBasicAuth should reject mismatches")
+	}
+}
+
+func TestBasicAuthMalformed(t *testing.T) {
+	cases := []string{"Basic !!!", "Basic bm9jb2xvbg==", "Bearer abc", "Basi"}
+	for _, c := range cases {
+		r := &Request{Header: Header{"Authorization": {c}}}
+		if _, _, ok := r.BasicAuth(); ok {
+			t.Fatalf("expected failure for %q", c)
+		}
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	r := &Request{Header: Header{"Authorization": {"bearer  tok123"}}}
+	tok, ok := r.BearerToken()
+	if !ok || tok != "tok123" {
+		t.Fatalf("BearerToken = %q %v", tok, ok)
+	}
+	if !CheckBearerToken(r, "tok123") || CheckBearerToken(r, "tok124") {
+		t.Fatal("CheckBearerToken mismatch")
+	}
+	r.Header.Set("Authorization", "Bearer ")
+	if _, ok := r.BearerToken(); ok {
+		t.Fatal("empty token must not be accepted")
+	}
+}
+
+func TestWriteUnauthorized(t *testing.T) {
+	var buf bytes.Buffer
+	basic, err := BasicChallenge("admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bearer, err := BearerChallenge("api", "invalid_token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteUnauthorized(context.Background(), &buf, basic, bearer); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	if !strings.HasPrefix(got, "HTTP/1.1 401 ") {
+		t.Fatalf("bad status: %q", got)
+	}
+	if !strings.Contains(got, "Www-Authenticate: Basic realm=\"admin\", charset=\"UTF-8\"\r\n") {
+		t.Fatalf("missing basic challenge: %q", got)
+	}
+	if !strings.Contains(got, "Www-Authenticate: Bearer realm=\"api\", error=\"invalid_token\"\r\n") {
+		t.Fatalf("missing bearer challenge: %q", got)
+	}
+}
+
+func TestChallengeQuoting(t *testing.T) {
+	// Only '"' and '\' are escaped; non-ASCII text passes through as is.
+	got, err := BasicChallenge(`a "b" \ café`)
+	if err != nil || got != `Basic realm="a \"b\" \\ café", charset="UTF-8"` {
+		t.Fatalf("got %q %v", got, err)
+	}
+	if got, err := BearerChallenge("api\tv1", ""); err != nil || got != "Bearer realm=\"api\tv1\"" {
+		t.Fatalf("HTAB: %q %v", got, err)
+	}
+	for _, realm := range []string{"a\r\nSet-Cookie: x", "nul\x00", "del\x7f"} {
+		if _, err := BasicChallenge(realm); !errors.Is(err, ErrInvalidValue) {
+			t.Fatalf("%q: got %v", realm, err)
+		}
+	}
+	if _, err := BearerChallenge("api", "bad\n"); !errors.Is(err, ErrInvalidValue) {
+		t.Fatalf("CTL in error code: %v", err)
+	}
+}
@@ -0,0 +1,238 @@
+package httpx
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultMaxFormBytes caps url-encoded form bodies read by Bind.
+const DefaultMaxFormBytes = 1 << 20
+
+// ErrBindTarget indicates that Bind was given something other than a
+// pointer to a struct.
+var ErrBindTarget = errors.New("httpx: bind target must be a non-nil struct pointer")
+
+// FieldError describes one parameter that could not be bound.
+type FieldError struct {
+	Source string `json:"source"` // "query", "form" or "path"
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// BindError collects every field that failed to bind, so a client learns
+// about all of its mistakes in one round trip.
+type BindError struct {
+	Fields []FieldError
+}
+
+func (e *BindError) Error() string {
+	parts := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		parts[i] = f.Source + " " + f.Name + ": " + f.Reason
+	}
+	return "httpx: bind: " + strings.Join(parts, "; ")
+}
+
+// WriteProblem answers with a 400 application/problem+json document
+// (RFC 9457) listing the failed fields under "errors".
+func (e *BindError) WriteProblem(ctx context.Context, w io.Writer) error {
+	b, err := json.Marshal(struct {
+		Type   string       `json:"type"`
+		Title  string       `json:"title"`
+		Status int          `json:"status"`
+		Errors []FieldError `json:"errors"`
+	}{"about:blank", "Invalid request parameters", StatusBadRequest, e.Fields})
+	if err != nil {
+		return err
+	}
+	return writeBytes(ctx, w, StatusBadRequest, "application/problem+json", b)
+}
+
+// Bind populates the struct pointed to by v from r. Fields opt in with a
+// tag naming their source and parameter:
+//
+//	Page  int       `query:"page"`
+//	Tags  []string  `query:"tag"`
+//	Email string    `form:"email,required"`
+//	ID    int64     `path:"id"`
+//
+// Query values come from r.URL, form values from an
+// application/x-www-form-urlencoded body (read up to DefaultMaxFormBytes),
+// and path values from params, as extracted by whatever routes the
+// request. Supported field types are strings, bools, integers, floats,
+// time.Duration, encoding.TextUnmarshaler, pointers to these, and slices
+// of them. Absent parameters leave the field untouched unless marked
+// required.
+//
+// Conversion failures are collected into a *BindError rather than
+// stopping at the first one.
+func Bind(r *Request, v any, params map[string]string) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return ErrBindTarget
+	}
+	rv = rv.Elem()
+
+	var query url.Values
+	if r.URL != nil {
+		query, _ = url.ParseQuery(r.URL.RawQuery)
+	}
+	var form url.Values
+	formRead := false
+
+	var berr BindError
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		for _, src := range [...]string{"query", "form", "path"} {
+			tag, ok := sf.Tag.Lookup(src)
+			if !ok {
+				continue
+			}
+			name, opts, _ := strings.Cut(tag, ",")
+			if name == "" {
+				name = sf.Name
+			}
+			required := opts == "required"
+
+			var vals []string
+			switch src {
+			case "query":
+				vals = query[name]
+			case "form":
+				if !formRead {
+					formRead = true
+					var err error
+					if form, err = readForm(r); err != nil {
+						berr.Fields = append(berr.Fields, FieldError{"form", "", err.Error()})
+					}
+				}
+				vals = form[name]
+			case "path":
+				if p, ok := params[name]; ok {
+					vals = []string{p}
+				}
+			}
+			if len(vals) == 0 {
+				if required {
+					berr.Fields = append(berr.Fields, FieldError{src, name, "is required"})
+				}
+				continue
+			}
+			if err := setField(rv.Field(i), vals); err != nil {
+				berr.Fields = append(berr.Fields, FieldError{src, name, err.Error()})
+			}
+		}
+	}
+	if len(berr.Fields) > 0 {
+		return &berr
+	}
+	return nil
+}
+
+// readForm reads and parses a url-encoded request body. Bodies of any
+// other type yield no values.
+func readForm(r *Request) (url.Values, error) {
+	mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mt != "application/x-www-form-urlencoded" || r.Body == nil {
+		return nil, nil
+	}
+	b, err := io.ReadAll(io.LimitReader(r.Body, DefaultMaxFormBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > DefaultMaxFormBytes {
+		return nil, ErrBodyTooLarge
+	}
+	return url.ParseQuery(string(b))
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// setField converts vals into f. Slices take every value; other kinds take
+// the first.
+func setField(f reflect.Value, vals []string) error {
+	if f.Kind() == reflect.Slice && !f.Type().Implements(textUnmarshalerType) {
+		s := reflect.MakeSlice(f.Type(), len(vals), len(vals))
+		for i, v := range vals {
+			if err := setValue(s.Index(i), v); err != nil {
+				return err
+			}
+		}
+		f.Set(s)
+		return nil
+	}
+	return setValue(f, vals[0])
+}
+
+func setValue(f reflect.Value, s string) error {
+	if f.Kind() == reflect.Pointer {
+		p := reflect.New(f.Type().Elem())
+		if err := setValue(p.Elem(), s); err != nil {
+			return err
+		}
+		f.Set(p)
+		return nil
+	}
+	if f.CanAddr() && f.Addr().Type().Implements(textUnmarshalerType) {
+		if err := f.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+			return fmt.Errorf("invalid value %q", s)
+		}
+		return nil
+	}
+	if f.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", s)
+		}
+		f.SetInt(int64(d))
+		return nil
+	}
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", s)
+		}
+		f.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, f.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", s)
+		}
+		f.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, f.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid unsigned integer %q", s)
+		}
+		f.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, f.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid number %q", s)
+		}
+		f.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported field type %s", f.Type())
+	}
+	return nil
+}
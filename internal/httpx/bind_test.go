@@ -0,0 +1,93 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+)
+
+type bindTarget struct {
+	Page    int           `query:"page"`
+	Tags    []string      `query:"tag"`
+	Verbose *bool         `query:"v"`
+	Wait    time.Duration `query:"wait"`
+	Addr    netip.Addr    `query:"addr"`
+	Email   string        `form:"email,required"`
+	Score   float64       `form:"score"`
+	ID      uint32        `path:"id"`
+	hidden  string        `query:"hidden"`
+}
+
+func bindRequest(query, ctype, body string) *Request {
+	r := &Request{URL: &URL{Path: "/", RawQuery: query}, Header: Header{}}
+	if ctype != "" {
+		r.Header.Set("Content-Type", ctype)
+		r.Body = io.NopCloser(strings.NewReader(body))
+	}
+	return r
+}
+
+func TestBind(t *testing.T) {
+	r := bindRequest("page=3&tag=a&tag=b&v=true&wait=1.5s&addr=10.0.0.1&hidden=x",
+		"application/x-www-form-urlencoded", "email=a%40b.c&score=9.5")
+	var v bindTarget
+	if err := Bind(r, &v, map[string]string{"id": "42"}); err != nil {
+		t.Fatal(err)
+	}
+	if v.Page != 3 || len(v.Tags) != 2 || v.Tags[1] != "b" || v.Verbose == nil || !*v.Verbose ||
+		v.Wait != 1500*time.Millisecond || v.Addr.String() != "10.0.0.1" ||
+		v.Email != "a@b.c" || v.Score != 9.5 || v.ID != 42 || v.hidden != "" {
+		t.Fatalf("bound %+v", v)
+	}
+
+	// Absent optional fields are left as they were.
+	v = bindTarget{Page: 1}
+	if err := Bind(bindRequest("", "application/x-www-form-urlencoded", "email=x"), &v, nil); err != nil || v.Page != 1 {
+		t.Fatalf("%+v %v", v, err)
+	}
+}
+
+func TestBindErrors(t *testing.T) {
+	r := bindRequest("page=x&v=maybe&addr=nope", "", "")
+	var v bindTarget
+	err := Bind(r, &v, map[string]string{"id": "-1"})
+	var be *BindError
+	if !errors.As(err, &be) {
+		t.Fatalf("got %v", err)
+	}
+	got := map[string]bool{}
+	for _, f := range be.Fields {
+		got[f.Source+":"+f.Name] = true
+	}
+	for _, want := range []string{"query:page", "query:v", "query:addr", "form:email", "path:id"} {
+		if !got[want] {
+			t.Fatalf("missing %s in %v", want, be.Fields)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := be.WriteProblem(context.Background(), &buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.HasPrefix(out, "HTTP/1.1 400 Bad Request\r\n") || !strings.Contains(out, "Content-Type: application/problem+json") {
+		t.Fatalf("unexpected response %q", out)
+	}
+	var doc struct {
+		Status int
+		Errors []FieldError
+	}
+	if err := json.Unmarshal([]byte(out[strings.Index(out, "\r\n\r\n")+4:]), &doc); err != nil || doc.Status != 400 || len(doc.Errors) != len(be.Fields) {
+		t.Fatalf("problem body %+v %v", doc, err)
+	}
+
+	if err := Bind(r, v, nil); !errors.Is(err, ErrBindTarget) {
+		t.Fatalf("non-pointer target: %v", err)
+	}
+}
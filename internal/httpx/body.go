@@ -3,8 +3,10 @@ package httpx
 import (
 	"bufio"
 	"context"
+	"crypto/sha256"
 	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"strconv"
 	"strings"
@@ -18,6 +20,7 @@ var (
 	ErrBadChunk          = errors.New("httpx: invalid chunk encoding")
 	ErrLengthMismatch    = errors.New("httpx: content-length mismatch")
 	ErrUnexpectedTrailer = errors.New("httpx: unexpected trailer")
+	ErrBodyNotDrained    = errors.New("httpx: unread body exceeds drain limit")
 )
 
 // -----------------------------------------------------------------------------
@@ -28,12 +31,20 @@ var (
 //
 // It returns an io.ReadCloser representing the body stream and the expected
 // Content-Length (if known; otherwise -1).
+//
+// A limit set with WithBodyLimit on the request's context, or failing that
+// on ctx, takes precedence over maxSize.
 func NewBodyReader(ctx context.Context, req *Request, r io.Reader, maxSize int64) (io.ReadCloser, int64, error) {
 	h := req.Header
+	if n, ok := BodyLimit(req.Context()); ok {
+		maxSize = n
+	} else if n, ok := BodyLimit(ctx); ok {
+		maxSize = n
+	}
 
 	// 1. Transfer-Encoding: chunked
 	if strings.EqualFold(h.Get("Transfer-Encoding"), "chunked") {
-		return newChunkedReader(ctx, r, maxSize, h), -1, nil
+		return newChunkedReaderWith(ctx, r, maxSize, h, req.limit), -1, nil
 	}
 
 	// 2. Content-Length: fixed-length body
@@ -52,6 +63,64 @@ func NewBodyReader(ctx context.Context, req *Request, r io.Reader, maxSize int64
 	return newCloseReader(ctx, r, maxSize), -1, nil
 }
 
+type bodyLimitKey struct{}
+
+// WithBodyLimit returns a copy of ctx carrying a body size cap for
+// NewBodyReader, overriding the server-wide default. Routers attach it per
+// route, e.g. 1 MiB for JSON APIs and several GiB for uploads. A
+// non-positive n lifts the cap entirely.
+func WithBodyLimit(ctx context.Context, n int64) context.Context {
+	if n < 0 {
+		n = 0
+	}
+	return context.WithValue(ctx, bodyLimitKey{}, n)
+}
+
+// BodyLimit returns the cap set by WithBodyLimit, if any. Zero means
+// unlimited.
+func BodyLimit(ctx context.Context) (int64, bool) {
+	n, ok := ctx.Value(bodyLimitKey{}).(int64)
+	return n, ok
+}
+
+// DiscardBody reads and discards what is left of r's body, up to max bytes,
+// so the connection can be reused for the next request. It returns nil once
+// the body is fully consumed, ErrBodyNotDrained if more than max bytes
+// remain (the connection should then be closed), or the read error.
+func DiscardBody(r *Request, max int64) error {
+	if r.Body == nil {
+		return nil
+	}
+	_, err := io.CopyN(io.Discard, r.Body, max)
+	switch {
+	case err == io.EOF:
+		return nil
+	case err != nil:
+		return err
+	}
+	// Exactly max bytes read; the body may still end right here. Readers
+	// such as the chunked one return (0, nil) while stepping over framing,
+	// so probe a few times before concluding anything.
+	var probe [1]byte
+	for i := 0; i < maxEmptyProbes; i++ {
+		n, err := r.Body.Read(probe[:])
+		switch {
+		case n > 0:
+			return ErrBodyNotDrained
+		case err == io.EOF:
+			return nil
+		case err != nil:
+			return err
+		}
+	}
+	// Still no data and no EOF; be conservative.
+	return ErrBodyNotDrained
+}
+
+// maxEmptyProbes bounds the zero-length reads DiscardBody tolerates while
+// probing for the end of a body.
+const maxEmptyProbes = 8
+
 // -----------------------------------------------------------------------------
 // fixedReader (Content-Length)
 // -----------------------------------------------------------------------------
@@ -111,6 +180,40 @@ func (f *fixedReader) Read(p []byte) (int, error) {
 	return n, err
 }
 
+// WriteTo implements io.WriterTo so io.Copy from a body hands the remaining
+// bytes to w in one call (letting w use ReadFrom, e.g. splice between TCP
+// connections) instead of cycling through a 32 KiB copy buffer. The context
+// is checked once before copying; cancellation mid-copy relies on the
+// connection's deadlines.
+func (f *fixedReader) WriteTo(w io.Writer) (int64, error) {
+	if err := f.ctx.Err(); err != nil {
+		return 0, err
+	}
+	if f.n <= 0 {
+		return 0, nil
+	}
+
+	want := f.n
+	tooLarge := f.limit > 0 && f.readTotal+want > f.limit
+	if tooLarge {
+		want = f.limit - f.readTotal
+	}
+
+	n, err := io.CopyN(w, f.r, want)
+	f.n -= n
+	f.readTotal += n
+
+	switch {
+	case err == io.EOF:
+		return n, ErrLengthMismatch
+	case err != nil:
+		return n, err
+	case tooLarge:
+		return n, ErrBodyTooLarge
+	}
+	return n, nil
+}
+
 func (f *fixedReader) Close() error { return nil }
 
 // -----------------------------------------------------------------------------
@@ -135,16 +238,31 @@ type chunkedReader struct {
 	limit     int64
 	readTotal int64
 	header    Header
+	parse     ParseLimits // chunk-extension and trailer caps, strictness
+	sum       hash.Hash   // running body checksum under parse.Checksum
+	wantSum   string      // ChecksumTrailerField value from the trailer
+	off       int64       // wire bytes consumed, for error offsets
 }
 
 func newChunkedReader(ctx context.Context, src io.Reader, limit int64, hdr Header) io.ReadCloser {
-	return &chunkedReader{
+	return newChunkedReaderWith(ctx, src, limit, hdr, ParseLimits{})
+}
+
+// newChunkedReaderWith is newChunkedReader applying the chunk-related
+// fields of a parsing profile.
+func newChunkedReaderWith(ctx context.Context, src io.Reader, limit int64, hdr Header, parse ParseLimits) io.ReadCloser {
+	c := &chunkedReader{
 		ctx:    ctx,
 		r:      bufio.NewReader(src),
 		state:  stateChunkHeader,
 		limit:  limit,
 		header: hdr,
+		parse:  parse,
+	}
+	if parse.Checksum != ChecksumIgnore {
+		c.sum = sha256.New()
 	}
+	return c
 }
 
 func (c *chunkedReader) Read(p []byte) (int, error) {
@@ -159,9 +277,10 @@ func (c *chunkedReader) Read(p []byte) (int, error) {
 		return 0, io.EOF
 
 	case stateChunkHeader:
+		start := c.off
 		size, err := c.nextChunkSize()
 		if err != nil {
-			return 0, err
+			return 0, &OffsetError{Op: "chunk size", Offset: start, Err: err}
 		}
 		if size == 0 {
 			c.state = stateTrailer
@@ -183,13 +302,20 @@ func (c *chunkedReader) Read(p []byte) (int, error) {
 		n, err := c.r.Read(p)
 		c.remain -= int64(n)
 		c.readTotal += int64(n)
+		c.off += int64(n)
+		if c.sum != nil {
+			c.sum.Write(p[:n])
+		}
 
 		if c.limit > 0 && c.readTotal > c.limit {
 			return n, ErrBodyTooLarge
 		}
 
 		if err != nil {
-			return n, err
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF // the chunk was cut short
+			}
+			return n, &OffsetError{Op: "read chunk data", Offset: c.off, Err: err}
 		}
 		if c.remain == 0 {
 			c.state = stateChunkCRLF
@@ -198,12 +324,10 @@ func (c *chunkedReader) Read(p []byte) (int, error) {
 
 	case stateChunkCRLF:
 		line, err := c.r.ReadString('\n')
-		if err != nil {
-			return 0, ErrBadChunk
-		}
-		if line != "\r\n" {
-			return 0, ErrBadChunk
+		if err != nil || line != "\r\n" {
+			return 0, &OffsetError{Op: "chunk data", Offset: c.off, Err: ErrBadChunk}
 		}
+		c.off += int64(len(line))
 		c.state = stateChunkHeader
 		return 0, nil
 
@@ -211,6 +335,9 @@ func (c *chunkedReader) Read(p []byte) (int, error) {
 		if err := c.readTrailers(); err != nil {
 			return 0, err
 		}
+		if err := c.verifyChecksum(); err != nil {
+			return 0, err
+		}
 		c.state = stateDone
 		return 0, io.EOF
 
@@ -221,12 +348,34 @@ func (c *chunkedReader) Read(p []byte) (int, error) {
 
 func (c *chunkedReader) Close() error { return nil }
 
-// nextChunkSize parses "<hex-size>\r\n"
+// nextChunkSize parses "<hex-size>[;ext]\r\n".
 func (c *chunkedReader) nextChunkSize() (int64, error) {
-	line, err := c.r.ReadString('\n')
+	max := 0
+	if c.parse.MaxChunkExtBytes > 0 {
+		// Room for the largest hex size, the extensions and the CRLF.
+		max = 16 + c.parse.MaxChunkExtBytes + 2
+	}
+	line, err := readChunkLine(c.r, max)
+	if err == errChunkLineTooLong {
+		return 0, ErrChunkExtTooLong
+	}
 	if err != nil {
 		return 0, err
 	}
+	c.off += int64(len(line))
+	if c.parse.Strict {
+		if !strings.HasSuffix(line, "\r\n") {
+			return 0, ErrBadChunk
+		}
+		if err := checkStrictLine([]byte(line[:len(line)-2])); err != nil {
+			return 0, err
+		}
+		size, _, _ := strings.Cut(line[:len(line)-2], ";")
+		size = strings.TrimRight(size, " \t")
+		if !isHexString(size) {
+			return 0, ErrChunkSizeSyntax
+		}
+	}
 	line = strings.TrimSpace(line)
 	if line == "" {
 		return 0, ErrBadChunk
@@ -234,7 +383,10 @@ func (c *chunkedReader) nextChunkSize() (int64, error) {
 
 	// ignore chunk extensions ("; name=value")
 	if semi := strings.IndexByte(line, ';'); semi >= 0 {
-		line = line[:semi]
+		if c.parse.MaxChunkExtBytes > 0 && len(line)-semi > c.parse.MaxChunkExtBytes {
+			return 0, ErrChunkExtTooLong
+		}
+		line = strings.TrimRight(line[:semi], " \t")
 	}
 
 	size, err := strconv.ParseInt(line, 16, 64)
@@ -244,23 +396,58 @@ func (c *chunkedReader) nextChunkSize() (int64, error) {
 	return size, nil
 }
 
+// isHexString reports whether s is a non-empty run of hex digits.
+func isHexString(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+			return false
+		}
+	}
+	return s != ""
+}
+
 // readTrailers parses optional trailer headers after the final 0-sized chunk.
+// Errors are *OffsetError values.
 func (c *chunkedReader) readTrailers() error {
+	remaining := c.parse.MaxTrailerBytes
 	for {
-		line, err := c.r.ReadString('\n')
+		start := c.off
+		line, err := readChunkLine(c.r, remaining)
+		if err == errChunkLineTooLong {
+			return &OffsetError{Op: "trailer", Offset: start, Err: ErrTrailerTooLarge}
+		}
 		if err != nil {
-			return ErrUnexpectedTrailer
+			return &OffsetError{Op: "read trailer", Offset: start, Err: ErrUnexpectedTrailer}
 		}
+		c.off += int64(len(line))
 		if line == "\r\n" {
 			return nil // blank line terminates trailer section
 		}
+		if c.parse.MaxTrailerBytes > 0 {
+			remaining -= len(line)
+			if remaining <= 0 {
+				return &OffsetError{Op: "trailer", Offset: start, Err: ErrTrailerTooLarge}
+			}
+		}
 		line = strings.TrimSuffix(line, "\r\n")
+		if c.parse.Strict {
+			if err := checkStrictLine([]byte(line)); err != nil {
+				return &OffsetError{Op: "trailer", Offset: start, Err: err}
+			}
+		}
 		i := strings.IndexByte(line, ':')
 		if i <= 0 {
-			return ErrUnexpectedTrailer
+			return &OffsetError{Op: "trailer", Offset: start, Err: ErrUnexpectedTrailer}
+		}
+		if c.parse.Strict && (line[i-1] == ' ' || line[i-1] == '\t') {
+			return &OffsetError{Op: "trailer", Offset: start + int64(i) - 1, Field: line[:i], Err: ErrSpaceBeforeColon}
 		}
 		key := CanonicalHeaderKey(line[:i])
 		val := strings.TrimSpace(line[i+1:])
+		if key == ChecksumTrailerField {
+			c.wantSum = val
+		}
 		c.header.Add(key, val)
 	}
 }
@@ -307,4 +494,28 @@ func (c *closeReader) Read(p []byte) (int, error) {
 	return n, err
 }
 
+// WriteTo implements io.WriterTo; see fixedReader.WriteTo. As with Read,
+// a body longer than the limit is truncated at the limit.
+func (c *closeReader) WriteTo(w io.Writer) (int64, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	var n int64
+	var err error
+	if c.limit > 0 {
+		remaining := c.limit - c.readTotal
+		if remaining <= 0 {
+			return 0, nil
+		}
+		n, err = io.CopyN(w, c.r, remaining)
+		if err == io.EOF {
+			err = nil
+		}
+	} else {
+		n, err = io.Copy(w, c.r)
+	}
+	c.readTotal += n
+	return n, err
+}
+
 func (c *closeReader) Close() error { return nil }
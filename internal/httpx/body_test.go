@@ -3,6 +3,7 @@ package httpx
 import (
 	"bytes"
 	"context"
+	"errors"
 	"io"
 	"strings"
 	"testing"
@@ -122,3 +123,127 @@ func TestContextCancelDuringRead(t *testing.T) {
 		t.Fatal("expected ctx.Err() to be non-nil")
 	}
 }
+
+// -----------------------------------------------------------------------------
+// WriterTo fast paths
+// -----------------------------------------------------------------------------
+
+func TestFixedReaderWriteTo(t *testing.T) {
+	var buf bytes.Buffer
+	fr := newFixedReader(context.Background(), strings.NewReader("hello world!!"), 11, 0)
+	n, err := io.Copy(&buf, fr)
+	if err != nil || n != 11 || buf.String() != "hello world" {
+		t.Fatalf("n=%d err=%v body=%q", n, err, buf.String())
+	}
+
+	buf.Reset()
+	fr = newFixedReader(context.Background(), strings.NewReader("abc"), 5, 0)
+	if _, err := io.Copy(&buf, fr); err != ErrLengthMismatch {
+		t.Fatalf("expected ErrLengthMismatch, got %v", err)
+	}
+
+	buf.Reset()
+	fr = newFixedReader(context.Background(), strings.NewReader("abcdef"), 6, 4)
+	if _, err := io.Copy(&buf, fr); err != ErrBodyTooLarge {
+		t.Fatalf("expected ErrBodyTooLarge, got %v", err)
+	}
+	if buf.String() != "abcd" {
+		t.Fatalf("copied %q past the limit", buf.String())
+	}
+}
+
+func TestCloseReaderWriteTo(t *testing.T) {
+	var buf bytes.Buffer
+	cr := newCloseReader(context.Background(), strings.NewReader("abcdef"), 4)
+	n, err := io.Copy(&buf, cr)
+	if err != nil || n != 4 || buf.String() != "abcd" {
+		t.Fatalf("n=%d err=%v body=%q", n, err, buf.String())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	cr = newCloseReader(ctx, strings.NewReader("abc"), 0)
+	if _, err := io.Copy(io.Discard, cr); err == nil {
+		t.Fatal("expected context error")
+	}
+}
+
+// -----------------------------------------------------------------------------
+// DiscardBody tests
+// -----------------------------------------------------------------------------
+
+func TestDiscardBody(t *testing.T) {
+	// Keep-alive stream: a 10-byte body followed by the next request.
+	src := strings.NewReader("0123456789GET / HTTP/1.1\r\n")
+	req := &Request{Body: newFixedReader(context.Background(), src, 10, 0)}
+	io.ReadFull(req.Body, make([]byte, 3)) // handler read part of it
+
+	if err := DiscardBody(req, 7); err != nil {
+		t.Fatalf("residue within the cap should drain: %v", err)
+	}
+	rest, _ := io.ReadAll(src)
+	if string(rest) != "GET / HTTP/1.1\r\n" {
+		t.Fatalf("drain consumed past the body: %q", rest)
+	}
+}
+
+func TestDiscardBodyOverCap(t *testing.T) {
+	req := &Request{Body: newFixedReader(context.Background(), strings.NewReader("0123456789"), 10, 0)}
+	if err := DiscardBody(req, 4); err != ErrBodyNotDrained {
+		t.Fatalf("expected ErrBodyNotDrained, got %v", err)
+	}
+
+	chunked := "5\r\nhello\r\n0\r\n\r\n"
+	req = &Request{Body: newChunkedReader(context.Background(), strings.NewReader(chunked), 0, Header{})}
+	if err := DiscardBody(req, 64); err != nil {
+		t.Fatalf("chunked body should drain: %v", err)
+	}
+
+	// A chunked body of exactly max bytes: the probe steps over the
+	// framing after the data before reaching EOF.
+	req = &Request{Body: newChunkedReader(context.Background(), strings.NewReader(chunked), 0, Header{})}
+	if err := DiscardBody(req, 5); err != nil {
+		t.Fatalf("chunked body of exactly max bytes should drain: %v", err)
+	}
+	req = &Request{Body: newChunkedReader(context.Background(), strings.NewReader("5\r\nhello\r\n1\r\n!\r\n0\r\n\r\n"), 0, Header{})}
+	if err := DiscardBody(req, 5); err != ErrBodyNotDrained {
+		t.Fatalf("expected ErrBodyNotDrained past max, got %v", err)
+	}
+
+	if err := DiscardBody(&Request{}, 0); err != nil {
+		t.Fatalf("no body: %v", err)
+	}
+}
+
+func TestBodyLimitFromContext(t *testing.T) {
+	newReq := func(ctx context.Context) *Request {
+		r := &Request{Header: Header{}}
+		r.Header.Set("Content-Length", "2048")
+		return r.WithContext(ctx)
+	}
+	src := strings.NewReader(strings.Repeat("x", 2048))
+
+	// The server default would reject the body; the route allows it.
+	req := newReq(WithBodyLimit(context.Background(), 4096))
+	if _, _, err := NewBodyReader(context.Background(), req, src, 1024); err != nil {
+		t.Fatalf("route limit not applied: %v", err)
+	}
+	// And the reverse: a tighter route limit wins over a generous default.
+	req = newReq(WithBodyLimit(context.Background(), 1024))
+	if _, _, err := NewBodyReader(context.Background(), req, src, 1<<20); !errors.Is(err, ErrBodyTooLarge) {
+		t.Fatalf("got %v", err)
+	}
+	// A limit on the ctx argument applies when the request carries none.
+	req = newReq(context.Background())
+	if _, _, err := NewBodyReader(WithBodyLimit(context.Background(), 1024), req, src, 0); !errors.Is(err, ErrBodyTooLarge) {
+		t.Fatalf("got %v", err)
+	}
+	// Zero lifts the cap.
+	req = newReq(WithBodyLimit(context.Background(), 0))
+	if _, _, err := NewBodyReader(context.Background(), req, src, 1024); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := BodyLimit(context.Background()); ok {
+		t.Fatal("no limit expected")
+	}
+}
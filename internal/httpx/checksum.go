@@ -0,0 +1,69 @@
+package httpx
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+// ChecksumTrailerField carries the base64 SHA-256 of a chunked body as a
+// trailer. Response.ChecksumTrailer emits it; ParseLimits.Checksum checks
+// it on incoming chunked bodies.
+const ChecksumTrailerField = "X-Checksum-Sha256"
+
+// Checksum trailer errors. Both map to 400 Bad Request.
+var (
+	ErrChecksumMismatch = errors.New("httpx: chunked body does not match its checksum trailer")
+	ErrChecksumMissing  = errors.New("httpx: chunked body has no checksum trailer")
+)
+
+// ChecksumPolicy selects how a chunked body reader treats a
+// ChecksumTrailerField trailer.
+type ChecksumPolicy int
+
+const (
+	// ChecksumIgnore does not hash the body; the trailer, if any, is
+	// merged into the header like any other.
+	ChecksumIgnore ChecksumPolicy = iota
+	// ChecksumVerify checks the trailer when the client sends one.
+	ChecksumVerify
+	// ChecksumRequire also rejects chunked bodies that end without one.
+	ChecksumRequire
+)
+
+// verifyChecksum compares the running hash of a chunked body with the
+// trailer value received, applying the reader's policy.
+func (c *chunkedReader) verifyChecksum() error {
+	if c.sum == nil {
+		return nil
+	}
+	if c.wantSum == "" {
+		if c.parse.Checksum == ChecksumRequire {
+			return ErrChecksumMissing
+		}
+		return nil
+	}
+	want, err := base64.StdEncoding.DecodeString(c.wantSum)
+	if err != nil || len(want) != sha256.Size {
+		return ErrChecksumMismatch
+	}
+	if subtle.ConstantTimeCompare(c.sum.Sum(nil), want) != 1 {
+		return ErrChecksumMismatch
+	}
+	return nil
+}
+
+// trailerDeclared reports whether h announces name in a Trailer field.
+func trailerDeclared(h Header, name string) bool {
+	name = CanonicalHeaderKey(name)
+	for _, t := range h.Values("Trailer") {
+		for _, n := range strings.Split(t, ",") {
+			if CanonicalHeaderKey(strings.TrimSpace(n)) == name {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/andycostintoma/httpx/internal/netx"
+)
+
+func TestChecksumTrailerWrite(t *testing.T) {
+	resp := &Response{StatusCode: StatusOK, Header: Header{}, Body: strings.NewReader("hello"), ChecksumTrailer: true}
+	var buf bytes.Buffer
+	if err := WriteResponse(context.Background(), &buf, resp); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	// sha256("hello") in base64.
+	want := "5\r\nhello\r\n0\r\nX-Checksum-Sha256: LPJNul+wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ=\r\n\r\n"
+	if !strings.Contains(out, "Transfer-Encoding: chunked\r\n") || !strings.Contains(out, "Trailer: X-Checksum-Sha256\r\n") ||
+		!strings.HasSuffix(out, want) {
+		t.Fatalf("got %q", out)
+	}
+
+	// Fixed-length bodies cannot carry it.
+	resp = &Response{StatusCode: StatusOK, Header: Header{"Content-Length": {"5"}}, Body: strings.NewReader("hello"), ChecksumTrailer: true}
+	buf.Reset()
+	if err := WriteResponse(context.Background(), &buf, resp); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "Checksum") {
+		t.Fatalf("got %q", buf.String())
+	}
+}
+
+func readChunkedWithPolicy(t *testing.T, wire string, policy ChecksumPolicy) ([]byte, error) {
+	t.Helper()
+	raw := "PUT /u HTTP/1.1\r\nTransfer-Encoding: chunked\r\n\r\n" + wire
+	rd := netx.NewCRLFFastReader(strings.NewReader(raw))
+	req, err := ParseRequest(rd, ParseLimits{MaxLineBytes: 1024, Checksum: policy})
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _, err := NewBodyReader(context.Background(), req, rd, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return io.ReadAll(body)
+}
+
+func TestChecksumTrailerVerify(t *testing.T) {
+	const good = "X-Checksum-Sha256: LPJNul+wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ=\r\n"
+	const bad = "X-Checksum-Sha256: AAAAul+wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ=\r\n"
+	for _, c := range []struct {
+		trailer string
+		policy  ChecksumPolicy
+		want    error
+	}{
+		{good, ChecksumVerify, nil},
+		{good, ChecksumRequire, nil},
+		{bad, ChecksumVerify, ErrChecksumMismatch},
+		{"X-Checksum-Sha256: bm90IGEgc3Vt\r\n", ChecksumVerify, ErrChecksumMismatch},
+		{"", ChecksumVerify, nil},
+		{"", ChecksumRequire, ErrChecksumMissing},
+		{bad, ChecksumIgnore, nil},
+	} {
+		b, err := readChunkedWithPolicy(t, "3\r\nhel\r\n2\r\nlo\r\n0\r\n"+c.trailer+"\r\n", c.policy)
+		if !errors.Is(err, c.want) {
+			t.Fatalf("%q policy %d: got %v, want %v", c.trailer, c.policy, err, c.want)
+		}
+		if err == nil && string(b) != "hello" {
+			t.Fatalf("body %q", b)
+		}
+	}
+}
+
+func TestTrailerDeclared(t *testing.T) {
+	h := Header{"Trailer": {"Expires, x-checksum-sha256"}}
+	if !trailerDeclared(h, ChecksumTrailerField) || trailerDeclared(h, "Repr-Digest") {
+		t.Fatal("trailerDeclared")
+	}
+}
@@ -0,0 +1,52 @@
+package httpx
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+)
+
+// ConnInfo describes the connection a request arrived on. The accepting
+// side attaches it with WithConnInfo before handing the request on.
+type ConnInfo struct {
+	Listener   string // name of the listener, e.g. from ActivationListenersByName
+	LocalAddr  net.Addr
+	RemoteAddr net.Addr
+	TLS        *tls.ConnectionState // nil for plain-text connections
+}
+
+// RouteInfo describes how a request was routed. The router attaches it
+// with WithRoute once it has picked a route.
+type RouteInfo struct {
+	Pattern string            // the route pattern that matched, e.g. "/users/{id}"
+	Params  map[string]string // path parameters extracted from the match
+}
+
+type (
+	connInfoKey struct{}
+	routeKey    struct{}
+)
+
+// WithConnInfo returns a copy of ctx carrying info.
+func WithConnInfo(ctx context.Context, info *ConnInfo) context.Context {
+	return context.WithValue(ctx, connInfoKey{}, info)
+}
+
+// ConnInfoFromContext returns the connection metadata attached to ctx, or
+// nil if there is none.
+func ConnInfoFromContext(ctx context.Context) *ConnInfo {
+	info, _ := ctx.Value(connInfoKey{}).(*ConnInfo)
+	return info
+}
+
+// WithRoute returns a copy of ctx carrying route.
+func WithRoute(ctx context.Context, route *RouteInfo) context.Context {
+	return context.WithValue(ctx, routeKey{}, route)
+}
+
+// RouteFromContext returns the routing metadata attached to ctx, or nil
+// if there is none.
+func RouteFromContext(ctx context.Context) *RouteInfo {
+	route, _ := ctx.Value(routeKey{}).(*RouteInfo)
+	return route
+}
@@ -0,0 +1,28 @@
+package httpx
+
+import (
+	"context"
+	"net"
+	"testing"
+)
+
+func TestContextMetadata(t *testing.T) {
+	ctx := context.Background()
+	if ConnInfoFromContext(ctx) != nil || RouteFromContext(ctx) != nil {
+		t.Fatal("empty context should carry no metadata")
+	}
+
+	remote := &net.TCPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 5555}
+	ctx = WithConnInfo(ctx, &ConnInfo{Listener: "http", RemoteAddr: remote})
+	ctx = WithRoute(ctx, &RouteInfo{Pattern: "/users/{id}", Params: map[string]string{"id": "7"}})
+
+	r := (&Request{}).WithContext(ctx)
+	info := ConnInfoFromContext(r.Context())
+	if info == nil || info.Listener != "http" || info.RemoteAddr != remote || info.TLS != nil {
+		t.Fatalf("conn info %+v", info)
+	}
+	route := RouteFromContext(r.Context())
+	if route == nil || route.Pattern != "/users/{id}" || route.Params["id"] != "7" {
+		t.Fatalf("route %+v", route)
+	}
+}
@@ -0,0 +1,112 @@
+package httpx
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/andycostintoma/httpx/internal/netx"
+)
+
+// Divergence describes one field on which httpx and net/http disagree.
+type Divergence struct {
+	Field  string // "error", "method", "target", "proto", "header:<Key>" or "body-length"
+	Httpx  string
+	Stdlib string
+}
+
+// DiffParse parses raw, a complete request as received on the wire, with
+// both ParseRequest and net/http's ReadRequest and reports every field on
+// which they disagree. It is meant for canary validation: feed it a copy of
+// incoming bytes and log the result, without affecting the live request.
+//
+// Bodies are read in full (up to maxBody bytes for httpx, when positive) to
+// compare framing.
+func DiffParse(raw []byte, limits ParseLimits, maxBody int64) []Divergence {
+	var out []Divergence
+	add := func(field, ours, theirs string) {
+		if ours != theirs {
+			out = append(out, Divergence{Field: field, Httpx: ours, Stdlib: theirs})
+		}
+	}
+
+	rd := netx.NewCRLFFastReader(bytes.NewReader(raw))
+	ours, oerr := ParseRequest(rd, limits)
+	theirs, serr := http.ReadRequest(bufio.NewReader(bytes.NewReader(raw)))
+	if (oerr == nil) != (serr == nil) {
+		add("error", errString(oerr), errString(serr))
+		return out
+	}
+	if oerr != nil {
+		return nil // both rejected it; the messages are bound to differ
+	}
+
+	add("method", ours.Method, theirs.Method)
+	add("target", ours.RequestURI, theirs.RequestURI)
+	add("proto", ours.Proto, theirs.Proto)
+
+	// net/http lifts Host and Transfer-Encoding out of the header map;
+	// put them back so both sides describe the same wire fields.
+	std := Header(theirs.Header.Clone())
+	if std == nil {
+		std = Header{}
+	}
+	if theirs.Host != "" {
+		std.Set("Host", theirs.Host)
+	}
+	if len(theirs.TransferEncoding) > 0 {
+		std.Set("Transfer-Encoding", strings.Join(theirs.TransferEncoding, ", "))
+	}
+	for _, k := range unionKeys(ours.Header, std) {
+		add("header:"+k, strings.Join(ours.Header[k], ", "), strings.Join(std[k], ", "))
+	}
+
+	body, _, err := NewBodyReader(context.Background(), ours, rd, maxBody)
+	ourLen := "error: " + errString(err)
+	if err == nil {
+		ourLen = countBody(body)
+	}
+	add("body-length", ourLen, countBody(theirs.Body))
+	return out
+}
+
+// countBody drains r and returns its length, or the read error.
+func countBody(r io.Reader) string {
+	if r == nil {
+		return "0"
+	}
+	n, err := io.Copy(io.Discard, r)
+	if err != nil {
+		return "error: " + err.Error()
+	}
+	return strconv.FormatInt(n, 10)
+}
+
+func errString(err error) string {
+	if err == nil {
+		return "<nil>"
+	}
+	return err.Error()
+}
+
+// unionKeys returns the sorted union of the keys of a and b.
+func unionKeys(a, b Header) []string {
+	seen := make(map[string]struct{}, len(a)+len(b))
+	for k := range a {
+		seen[k] = struct{}{}
+	}
+	for k := range b {
+		seen[k] = struct{}{}
+	}
+	keys := make([]string, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,35 @@
+package httpx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiffParseAgrees(t *testing.T) {
+	cases := []string{
+		"GET /a?b=1 HTTP/1.1\r\nHost: ex.com\r\nAccept: */*\r\n\r\n",
+		"POST /up HTTP/1.1\r\nHost: ex.com\r\nContent-Length: 5\r\n\r\nhello",
+		"POST /up HTTP/1.1\r\nHost: ex.com\r\nTransfer-Encoding: chunked\r\n\r\n5\r\nhello\r\n0\r\n\r\n",
+	}
+	for _, raw := range cases {
+		if d := DiffParse([]byte(raw), ParseLimits{MaxLineBytes: 4096}, 0); len(d) != 0 {
+			t.Fatalf("unexpected divergence for %q: %+v", raw, d)
+		}
+	}
+}
+
+func TestDiffParseReportsDivergence(t *testing.T) {
+	// net/http accepts any token as a method; httpx can be restricted.
+	raw := "PROPFIND / HTTP/1.1\r\nHost: ex.com\r\n\r\n"
+	d := DiffParse([]byte(raw), ParseLimits{MaxLineBytes: 4096, Methods: NewMethodRegistry()}, 0)
+	if len(d) != 1 || d[0].Field != "error" || !strings.Contains(d[0].Httpx, "method") {
+		t.Fatalf("expected an error divergence, got %+v", d)
+	}
+}
+
+func TestDiffParseBothReject(t *testing.T) {
+	raw := "GET / HTTP/1.1\r\nHost: ex.com\r\nNoColon\r\n\r\n"
+	if d := DiffParse([]byte(raw), ParseLimits{MaxLineBytes: 4096}, 0); len(d) != 0 {
+		t.Fatalf("both parsers reject %q, got %+v", raw, d)
+	}
+}
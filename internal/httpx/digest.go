@@ -0,0 +1,326 @@
+package httpx
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Digest authentication errors. ErrDigestStale means the credentials were
+// computed over an expired nonce; answer with a challenge carrying
+// stale=true so the client retries without prompting the user.
+var (
+	ErrDigestMissing   = errors.New("httpx: no digest credentials")
+	ErrDigestMalformed = errors.New("httpx: malformed digest credentials")
+	ErrDigestStale     = errors.New("httpx: stale digest nonce")
+	ErrDigestReplay    = errors.New("httpx: replayed digest nonce count")
+	ErrDigestMismatch  = errors.New("httpx: digest response mismatch")
+)
+
+// DefaultDigestNonceTTL is how long a DigestAuth nonce stays valid.
+const DefaultDigestNonceTTL = 5 * time.Minute
+
+// maxDigestNonces bounds outstanding nonces so unauthenticated clients
+// cannot grow the table without limit; the oldest are evicted first.
+const maxDigestNonces = 1 << 14
+
+// DigestAuth implements the server side of HTTP Digest authentication
+// (RFC 7616) with qop=auth. It issues nonces, tracks their nonce counts
+// to reject replays, and verifies responses.
+type DigestAuth struct {
+	Realm     string
+	Algorithm string        // "SHA-256" (the default) or "MD5"
+	NonceTTL  time.Duration // DefaultDigestNonceTTL if zero
+
+	// Now returns the current time; time.Now if nil.
+	Now func() time.Time
+
+	mu     sync.Mutex
+	nonces map[string]*digestNonce
+	order  []string
+	opaque string
+}
+
+type digestNonce struct {
+	expires time.Time
+	nc      uint64
+}
+
+// Challenge issues a fresh nonce and returns the WWW-Authenticate value
+// offering it. Pass stale=true after Verify returned ErrDigestStale. A
+// Realm holding control characters fails with ErrInvalidValue.
+func (d *DigestAuth) Challenge(stale bool) (string, error) {
+	realm, err := quoteString(d.Realm)
+	if err != nil {
+		return "", err
+	}
+	nonce := randomHex(16)
+	d.mu.Lock()
+	if d.nonces == nil {
+		d.nonces = make(map[string]*digestNonce)
+		d.opaque = randomHex(16)
+	}
+	d.prune()
+	d.nonces[nonce] = &digestNonce{expires: d.now().Add(d.ttl())}
+	d.order = append(d.order, nonce)
+	opaque := d.opaque
+	d.mu.Unlock()
+
+	v := `Digest realm=` + realm + `, qop="auth", algorithm=` + d.algorithm() +
+		`, nonce="` + nonce + `", opaque="` + opaque + `"`
+	if stale {
+		v += `, stale=true`
+	}
+	return v, nil
+}
+
+// Verify checks r's Digest credentials. password looks up the clear-text
+// password for a username; ok is false for unknown users. On success it
+// returns the authenticated username.
+func (d *DigestAuth) Verify(r *Request, password func(username string) (string, bool)) (string, error) {
+	auth := r.Header.Get("Authorization")
+	scheme, rest, _ := strings.Cut(auth, " ")
+	if !strings.EqualFold(scheme, "Digest") {
+		return "", ErrDigestMissing
+	}
+	p, err := parseAuthParams(rest)
+	if err != nil {
+		return "", err
+	}
+	user, nonce, uri, nc, cnonce, resp := p["username"], p["nonce"], p["uri"], p["nc"], p["cnonce"], p["response"]
+	if user == "" || nonce == "" || cnonce == "" || resp == "" || p["qop"] != "auth" ||
+		p["realm"] != d.Realm || (p["algorithm"] != "" && !strings.EqualFold(p["algorithm"], d.algorithm())) {
+		return "", ErrDigestMalformed
+	}
+	if uri != r.RequestURI {
+		return "", fmt.Errorf("%w: uri %q does not match request", ErrDigestMalformed, uri)
+	}
+	count, err := strconv.ParseUint(nc, 16, 64)
+	if err != nil || len(nc) != 8 {
+		return "", ErrDigestMalformed
+	}
+	pass, ok := password(user)
+	if !ok {
+		return "", ErrDigestMismatch
+	}
+
+	h := d.newHash()
+	ha1 := hashHex(h, user+":"+d.Realm+":"+pass)
+	ha2 := hashHex(h, r.Method+":"+uri)
+	want := hashHex(h, ha1+":"+nonce+":"+nc+":"+cnonce+":auth:"+ha2)
+	if secureCompare(want, strings.ToLower(resp)) != 1 {
+		return "", ErrDigestMismatch
+	}
+
+	// Only a correct response may consume a nonce count.
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	n, ok := d.nonces[nonce]
+	switch {
+	case !ok || d.now().After(n.expires):
+		return "", ErrDigestStale
+	case count <= n.nc:
+		return "", ErrDigestReplay
+	}
+	n.nc = count
+	return user, nil
+}
+
+// prune drops expired nonces and, past the cap, the oldest live ones.
+// d.mu must be held.
+func (d *DigestAuth) prune() {
+	now := d.now()
+	keep := d.order[:0]
+	for i, nonce := range d.order {
+		n := d.nonces[nonce]
+		if now.After(n.expires) || len(d.order)-i > maxDigestNonces-1 {
+			delete(d.nonces, nonce)
+			continue
+		}
+		keep = append(keep, nonce)
+	}
+	d.order = keep
+}
+
+func (d *DigestAuth) now() time.Time {
+	if d.Now != nil {
+		return d.Now()
+	}
+	return time.Now()
+}
+
+func (d *DigestAuth) ttl() time.Duration {
+	if d.NonceTTL > 0 {
+		return d.NonceTTL
+	}
+	return DefaultDigestNonceTTL
+}
+
+func (d *DigestAuth) algorithm() string {
+	if strings.EqualFold(d.Algorithm, "MD5") {
+		return "MD5"
+	}
+	return "SHA-256"
+}
+
+func (d *DigestAuth) newHash() hash.Hash { return digestHash(d.algorithm()) }
+
+// -----------------------------------------------------------------------------
+// Client side
+// -----------------------------------------------------------------------------
+
+// DigestChallenge is a parsed Digest WWW-Authenticate challenge. Reuse it
+// across requests to the same protection space so the nonce count
+// advances instead of costing a 401 round trip each time.
+type DigestChallenge struct {
+	Realm     string
+	Nonce     string
+	Opaque    string
+	Algorithm string
+	Stale     bool
+
+	mu sync.Mutex
+	nc uint64
+}
+
+// ParseDigestChallenge parses a WWW-Authenticate value offering Digest
+// authentication with qop=auth and an MD5 or SHA-256 algorithm.
+func ParseDigestChallenge(v string) (*DigestChallenge, error) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(v), " ")
+	if !strings.EqualFold(scheme, "Digest") {
+		return nil, ErrDigestMissing
+	}
+	p, err := parseAuthParams(rest)
+	if err != nil {
+		return nil, err
+	}
+	c := &DigestChallenge{
+		Realm:     p["realm"],
+		Nonce:     p["nonce"],
+		Opaque:    p["opaque"],
+		Algorithm: strings.ToUpper(p["algorithm"]),
+		Stale:     strings.EqualFold(p["stale"], "true"),
+	}
+	if c.Algorithm == "" {
+		c.Algorithm = "MD5"
+	}
+	qopAuth := false
+	for _, q := range strings.Split(p["qop"], ",") {
+		qopAuth = qopAuth || strings.TrimSpace(q) == "auth"
+	}
+	if c.Nonce == "" || !qopAuth || (c.Algorithm != "MD5" && c.Algorithm != "SHA-256") {
+		return nil, fmt.Errorf("%w: unsupported challenge", ErrDigestMalformed)
+	}
+	return c, nil
+}
+
+// Authorize sets r's Authorization header to a response to c for the
+// given credentials. A username, realm, nonce, URI or opaque value holding
+// control characters, or an Algorithm that is not a token, fails with
+// ErrInvalidValue, leaving r unchanged.
+func (c *DigestChallenge) Authorize(r *Request, username, password string) error {
+	uri := r.RequestURI
+	if uri == "" && r.URL != nil {
+		uri = r.URL.Path
+		if r.URL.RawQuery != "" {
+			uri += "?" + r.URL.RawQuery
+		}
+	}
+	if !isToken(c.Algorithm) {
+		return fmt.Errorf("%w: digest algorithm %q", ErrInvalidValue, c.Algorithm)
+	}
+	var q [5]string
+	for i, s := range []string{username, c.Realm, uri, c.Opaque, c.Nonce} {
+		var err error
+		if q[i], err = quoteString(s); err != nil {
+			return err
+		}
+	}
+
+	c.mu.Lock()
+	c.nc++
+	nc := fmt.Sprintf("%08x", c.nc)
+	c.mu.Unlock()
+
+	cnonce := randomHex(16)
+	h := digestHash(c.Algorithm)
+	ha1 := hashHex(h, username+":"+c.Realm+":"+password)
+	ha2 := hashHex(h, r.Method+":"+uri)
+	resp := hashHex(h, ha1+":"+c.Nonce+":"+nc+":"+cnonce+":auth:"+ha2)
+
+	v := `Digest username=` + q[0] + `, realm=` + q[1] +
+		`, nonce=` + q[4] + `, uri=` + q[2] + `, algorithm=` + c.Algorithm +
+		`, qop=auth, nc=` + nc + `, cnonce="` + cnonce + `", response="` + resp + `"`
+	if c.Opaque != "" {
+		v += `, opaque=` + q[3]
+	}
+	if r.Header == nil {
+		r.Header = Header{}
+	}
+	r.Header.Set("Authorization", v)
+	return nil
+}
+
+func digestHash(alg string) hash.Hash {
+	if alg == "MD5" {
+		return md5.New()
+	}
+	return sha256.New()
+}
+
+func hashHex(h hash.Hash, s string) string {
+	h.Reset()
+	h.Write([]byte(s))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// parseAuthParams parses a comma-separated auth-param list
+// (name=token or name="quoted"), lower-casing names.
+func parseAuthParams(s string) (map[string]string, error) {
+	p := make(map[string]string)
+	for {
+		s = strings.TrimLeft(s, " \t,")
+		if s == "" {
+			return p, nil
+		}
+		eq := strings.IndexByte(s, '=')
+		if eq <= 0 {
+			return nil, ErrDigestMalformed
+		}
+		name := strings.ToLower(strings.TrimSpace(s[:eq]))
+		s = strings.TrimLeft(s[eq+1:], " \t")
+		var val string
+		if strings.HasPrefix(s, `"`) {
+			var err error
+			if val, s, err = readQuoted(s); err != nil {
+				return nil, ErrDigestMalformed
+			}
+		} else {
+			end := strings.IndexByte(s, ',')
+			if end < 0 {
+				end = len(s)
+			}
+			val, s = strings.TrimSpace(s[:end]), s[end:]
+		}
+		if _, dup := p[name]; dup {
+			return nil, ErrDigestMalformed
+		}
+		p[name] = val
+	}
+}
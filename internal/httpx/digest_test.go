@@ -0,0 +1,147 @@
+package httpx
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func digestRequest(method, uri string) *Request {
+	return &Request{requestLine: requestLine{Method: method, RequestURI: uri}, Header: Header{}}
+}
+
+func mustChallenge(t *testing.T, d *DigestAuth, stale bool) string {
+	t.Helper()
+	ch, err := d.Challenge(stale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ch
+}
+
+func TestDigestAuthRoundTrip(t *testing.T) {
+	for _, alg := range []string{"", "MD5"} {
+		d := &DigestAuth{Realm: "cams", Algorithm: alg}
+		users := func(u string) (string, bool) { return "s3cret", u == "admin" }
+
+		c, err := ParseDigestChallenge(mustChallenge(t, d, false))
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := digestRequest(MethodGet, "/snapshot?ch=1")
+		if err := c.Authorize(r, "admin", "s3cret"); err != nil {
+			t.Fatal(err)
+		}
+		if user, err := d.Verify(r, users); err != nil || user != "admin" {
+			t.Fatalf("%s: %q %v", alg, user, err)
+		}
+
+		// Replaying the same Authorization header is rejected.
+		if _, err := d.Verify(r, users); !errors.Is(err, ErrDigestReplay) {
+			t.Fatalf("replay: %v", err)
+		}
+		// The next request with the same challenge advances nc and passes.
+		r = digestRequest(MethodPost, "/ptz")
+		c.Authorize(r, "admin", "s3cret")
+		if _, err := d.Verify(r, users); err != nil {
+			t.Fatalf("second request: %v", err)
+		}
+
+		r = digestRequest(MethodGet, "/")
+		c.Authorize(r, "admin", "wrong")
+		if _, err := d.Verify(r, users); !errors.Is(err, ErrDigestMismatch) {
+			t.Fatalf("bad password: %v", err)
+		}
+	}
+}
+
+func TestDigestAuthStale(t *testing.T) {
+	now := time.Unix(1000, 0)
+	d := &DigestAuth{Realm: "r", NonceTTL: time.Minute, Now: func() time.Time { return now }}
+	c, _ := ParseDigestChallenge(mustChallenge(t, d, false))
+	r := digestRequest(MethodGet, "/")
+	c.Authorize(r, "u", "p")
+	now = now.Add(2 * time.Minute)
+	if _, err := d.Verify(r, func(string) (string, bool) { return "p", true }); !errors.Is(err, ErrDigestStale) {
+		t.Fatalf("got %v", err)
+	}
+	if ch := mustChallenge(t, d, true); !strings.HasSuffix(ch, "stale=true") {
+		t.Fatal(ch)
+	}
+}
+
+func TestDigestVerifyMalformed(t *testing.T) {
+	d := &DigestAuth{Realm: "r"}
+	users := func(string) (string, bool) { return "p", true }
+	if _, err := d.Verify(digestRequest(MethodGet, "/"), users); !errors.Is(err, ErrDigestMissing) {
+		t.Fatalf("got %v", err)
+	}
+	c, _ := ParseDigestChallenge(mustChallenge(t, d, false))
+	r := digestRequest(MethodGet, "/a")
+	c.Authorize(r, "u", "p")
+	r.RequestURI = "/b"
+	if _, err := d.Verify(r, users); !errors.Is(err, ErrDigestMalformed) {
+		t.Fatalf("uri mismatch: %v", err)
+	}
+	r.Header.Set("Authorization", `Digest username="u", nonce="x`)
+	if _, err := d.Verify(r, users); !errors.Is(err, ErrDigestMalformed) {
+		t.Fatalf("unterminated quote: %v", err)
+	}
+}
+
+func TestParseDigestChallenge(t *testing.T) {
+	c, err := ParseDigestChallenge(`Digest realm="a b", qop="auth,auth-int", nonce="n1", opaque="o", stale=TRUE`)
+	if err != nil || c.Realm != "a b" || c.Nonce != "n1" || c.Opaque != "o" || !c.Stale || c.Algorithm != "MD5" {
+		t.Fatalf("%+v %v", c, err)
+	}
+	for _, v := range []string{
+		`Basic realm="x"`,
+		`Digest realm="x", nonce="n"`,
+		`Digest realm="x", qop="auth", nonce="n", algorithm=SHA-512-256`,
+	} {
+		if _, err := ParseDigestChallenge(v); err == nil {
+			t.Fatalf("%q should be rejected", v)
+		}
+	}
+}
+
+func TestDigestQuoting(t *testing.T) {
+	d := &DigestAuth{Realm: `say "hi" \ bye`}
+	ch := mustChallenge(t, d, false)
+	if !strings.HasPrefix(ch, `Digest realm="say \"hi\" \\ bye",`) {
+		t.Fatalf("challenge %q", ch)
+	}
+	c, err := ParseDigestChallenge(ch)
+	if err != nil || c.Realm != d.Realm {
+		t.Fatalf("round trip: %+v %v", c, err)
+	}
+
+	if _, err := (&DigestAuth{Realm: "a\nb"}).Challenge(false); !errors.Is(err, ErrInvalidValue) {
+		t.Fatalf("CTL in realm: %v", err)
+	}
+	r := digestRequest(MethodGet, "/")
+	if err := c.Authorize(r, "u\r\nX-Injected: 1", "p"); !errors.Is(err, ErrInvalidValue) || r.Header.Get("Authorization") != "" {
+		t.Fatalf("CTL in username: %v", err)
+	}
+}
+
+func TestDigestAuthorizeEscapesServerValues(t *testing.T) {
+	c, err := ParseDigestChallenge(`Digest realm="r", qop="auth", nonce="n\", qop=\"auth-int", opaque="o"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := digestRequest(MethodGet, "/")
+	if err := c.Authorize(r, "u", "p"); err != nil {
+		t.Fatal(err)
+	}
+	p, err := parseAuthParams(strings.TrimPrefix(r.Header.Get("Authorization"), "Digest "))
+	if err != nil || p["nonce"] != c.Nonce || p["qop"] != "auth" {
+		t.Fatalf("nonce injected params: %v %v", p, err)
+	}
+
+	c.Algorithm = "MD5, qop=auth-int"
+	if err := c.Authorize(digestRequest(MethodGet, "/"), "u", "p"); !errors.Is(err, ErrInvalidValue) {
+		t.Fatalf("non-token algorithm: %v", err)
+	}
+}
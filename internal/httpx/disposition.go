@@ -0,0 +1,244 @@
+package httpx
+
+import (
+	"errors"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrInvalidDisposition indicates a malformed Content-Disposition value.
+var ErrInvalidDisposition = errors.New("httpx: invalid content disposition")
+
+// AttachmentDisposition returns a Content-Disposition value that makes the
+// browser save the response as filename.
+func AttachmentDisposition(filename string) string {
+	return FormatContentDisposition("attachment", map[string]string{"filename": filename})
+}
+
+// FormatContentDisposition builds a Content-Disposition value (RFC 6266)
+// from a disposition type and parameters, emitted in sorted order. Values
+// are sent as tokens when possible and quoted otherwise; control characters
+// are replaced so the result is always a safe field value. A value with
+// non-ASCII characters is sent twice: as a percent-encoded RFC 8187
+// ext-value under name* for modern clients and as an ASCII approximation
+// under the plain name for old ones.
+func FormatContentDisposition(kind string, params map[string]string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(kind))
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := params[k]
+		k = strings.ToLower(strings.TrimSuffix(k, "*"))
+		if !isToken(k) {
+			continue
+		}
+		b.WriteString("; ")
+		b.WriteString(k)
+		b.WriteByte('=')
+		if isASCII(v) {
+			writeParamValue(&b, v)
+			continue
+		}
+		writeParamValue(&b, asciiFallback(v))
+		b.WriteString("; ")
+		b.WriteString(k)
+		b.WriteString("*=UTF-8''")
+		b.WriteString(encodeExtValue(v))
+	}
+	return b.String()
+}
+
+// ParseContentDisposition parses a Content-Disposition value, as found on
+// responses and multipart parts. The type and parameter names are
+// lower-cased. Ext-values (filename*=) are decoded and take precedence over
+// the plain parameter of the same name, which they are stored under.
+func ParseContentDisposition(v string) (kind string, params map[string]string, err error) {
+	kind, rest, _ := strings.Cut(v, ";")
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	if !isToken(kind) {
+		return "", nil, ErrInvalidDisposition
+	}
+	params = make(map[string]string)
+	ext := make(map[string]bool)
+	for {
+		rest = strings.TrimLeft(rest, " \t;")
+		if rest == "" {
+			break
+		}
+		eq := strings.IndexByte(rest, '=')
+		if eq < 0 {
+			return "", nil, ErrInvalidDisposition
+		}
+		name := strings.ToLower(strings.TrimSpace(rest[:eq]))
+		rest = strings.TrimLeft(rest[eq+1:], " \t")
+
+		var val string
+		if strings.HasPrefix(rest, `"`) {
+			val, rest, err = readQuoted(rest)
+			if err != nil {
+				return "", nil, err
+			}
+		} else {
+			end := strings.IndexByte(rest, ';')
+			if end < 0 {
+				end = len(rest)
+			}
+			val, rest = strings.TrimSpace(rest[:end]), rest[end:]
+		}
+
+		if base, ok := strings.CutSuffix(name, "*"); ok {
+			dec, err := decodeExtValue(val)
+			if err != nil {
+				return "", nil, err
+			}
+			name, val = base, dec
+			ext[name] = true
+		} else if ext[name] {
+			continue
+		}
+		if !isToken(name) {
+			return "", nil, ErrInvalidDisposition
+		}
+		if _, dup := params[name]; dup && !ext[name] {
+			return "", nil, ErrInvalidDisposition
+		}
+		params[name] = val
+	}
+	return kind, params, nil
+}
+
+func isToken(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isTokenByte(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// writeParamValue writes v as a token or, failing that, a quoted-string
+// with control characters replaced by '_'.
+func writeParamValue(b *strings.Builder, v string) {
+	if isToken(v) {
+		b.WriteString(v)
+		return
+	}
+	b.WriteByte('"')
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		switch {
+		case c < 0x20 || c == 0x7f:
+			c = '_'
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+	b.WriteByte('"')
+}
+
+// asciiFallback replaces every non-ASCII rune of v with '_'.
+func asciiFallback(v string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= utf8.RuneSelf {
+			return '_'
+		}
+		return r
+	}, v)
+}
+
+// encodeExtValue percent-encodes v for an RFC 8187 ext-value, leaving only
+// attr-chars unescaped.
+func encodeExtValue(v string) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		if isAttrChar(c) {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hex[c>>4])
+		b.WriteByte(hex[c&0xf])
+	}
+	return b.String()
+}
+
+func isAttrChar(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	}
+	return strings.IndexByte("!#$&+-.^_`|~", c) >= 0
+}
+
+// decodeExtValue decodes charset'lang'pct-encoded. UTF-8 and ISO-8859-1 are
+// the charsets RFC 8187 requires recipients to support.
+func decodeExtValue(v string) (string, error) {
+	charset, rest, ok := strings.Cut(v, "'")
+	if !ok {
+		return "", ErrInvalidDisposition
+	}
+	_, enc, ok := strings.Cut(rest, "'")
+	if !ok {
+		return "", ErrInvalidDisposition
+	}
+	raw, err := url.PathUnescape(enc)
+	if err != nil {
+		return "", ErrInvalidDisposition
+	}
+	switch strings.ToLower(charset) {
+	case "utf-8":
+		if !utf8.ValidString(raw) {
+			return "", ErrInvalidDisposition
+		}
+		return raw, nil
+	case "iso-8859-1":
+		r := make([]rune, len(raw))
+		for i := 0; i < len(raw); i++ {
+			r[i] = rune(raw[i])
+		}
+		return string(r), nil
+	}
+	return "", ErrInvalidDisposition
+}
+
+// readQuoted reads a quoted-string at the start of s and returns its
+// unescaped content and the remainder.
+func readQuoted(s string) (string, string, error) {
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"':
+			return b.String(), s[i+1:], nil
+		case '\\':
+			if i+1 == len(s) {
+				return "", "", ErrInvalidDisposition
+			}
+			i++
+			b.WriteByte(s[i])
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", "", ErrInvalidDisposition
+}
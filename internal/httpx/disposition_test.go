@@ -0,0 +1,72 @@
+package httpx
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFormatContentDisposition(t *testing.T) {
+	cases := []struct {
+		kind   string
+		params map[string]string
+		want   string
+	}{
+		{"attachment", map[string]string{"filename": "report.pdf"}, "attachment; filename=report.pdf"},
+		{"attachment", map[string]string{"filename": `my "q" file.txt`}, `attachment; filename="my \"q\" file.txt"`},
+		{"form-data", map[string]string{"name": "f", "filename": "a b"}, `form-data; filename="a b"; name=f`},
+		{"attachment", map[string]string{"filename": "€ rates.txt"}, `attachment; filename="_ rates.txt"; filename*=UTF-8''%E2%82%AC%20rates.txt`},
+		{"attachment", map[string]string{"filename": "a\r\nSet-Cookie: x"}, `attachment; filename="a__Set-Cookie: x"`},
+		{"inline", nil, "inline"},
+	}
+	for _, c := range cases {
+		if got := FormatContentDisposition(c.kind, c.params); got != c.want {
+			t.Fatalf("got %q want %q", got, c.want)
+		}
+	}
+	if got := AttachmentDisposition("x.csv"); got != "attachment; filename=x.csv" {
+		t.Fatal(got)
+	}
+}
+
+func TestParseContentDisposition(t *testing.T) {
+	kind, p, err := ParseContentDisposition(`Attachment; FILENAME="a \"b\".txt"; size=10`)
+	if err != nil || kind != "attachment" || p["filename"] != `a "b".txt` || p["size"] != "10" {
+		t.Fatalf("%q %v %v", kind, p, err)
+	}
+
+	// The ext-value wins regardless of order.
+	for _, v := range []string{
+		`attachment; filename*=UTF-8''%E2%82%AC%20rates.txt; filename="_ rates.txt"`,
+		`attachment; filename="_ rates.txt"; filename*=utf-8'en'%E2%82%AC%20rates.txt`,
+	} {
+		_, p, err := ParseContentDisposition(v)
+		if err != nil || p["filename"] != "€ rates.txt" {
+			t.Fatalf("%q: %v %v", v, p, err)
+		}
+	}
+	_, p, err = ParseContentDisposition(`attachment; filename*=iso-8859-1''%A3`)
+	if err != nil || p["filename"] != "£" {
+		t.Fatalf("latin-1: %v %v", p, err)
+	}
+
+	// Round trip through the builder.
+	want := "naïve file.txt"
+	_, p, err = ParseContentDisposition(FormatContentDisposition("form-data", map[string]string{"name": "up", "filename": want}))
+	if err != nil || p["filename"] != want || p["name"] != "up" {
+		t.Fatalf("round trip: %v %v", p, err)
+	}
+
+	bad := []string{
+		``,
+		`attachment; filename`,
+		`attachment; filename="open`,
+		`attachment; filename*=UTF-8%20x`,
+		`attachment; filename*=KOI8-R''x`,
+		`attachment; filename=a; filename=b`,
+	}
+	for _, v := range bad {
+		if _, _, err := ParseContentDisposition(v); !errors.Is(err, ErrInvalidDisposition) {
+			t.Fatalf("%q: got %v", v, err)
+		}
+	}
+}
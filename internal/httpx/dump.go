@@ -0,0 +1,96 @@
+package httpx
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// DefaultDumpRedact lists the fields DumpRequest and DumpResponse mask when
+// DumpOptions.Redact is nil.
+var DefaultDumpRedact = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// DumpOptions configures DumpRequest and DumpResponse.
+type DumpOptions struct {
+	// Body includes the message body. The body is read in full and
+	// replaced with an equivalent in-memory reader, so the message can
+	// still be processed afterwards.
+	Body bool
+
+	// Redact names fields whose values are replaced with "[REDACTED]".
+	// DefaultDumpRedact is used if nil; pass an empty slice to show all.
+	Redact []string
+}
+
+// DumpRequest returns a wire-like rendering of r for debugging: the request
+// line, header fields in sorted order, and optionally the body.
+func DumpRequest(r *Request, opts DumpOptions) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString(r.requestLine.String())
+	b.WriteString("\r\n")
+	h := r.Header
+	if r.Host != "" && h.Get("Host") == "" {
+		h = h.Clone()
+		if h == nil {
+			h = Header{}
+		}
+		h.Set("Host", r.Host)
+	}
+	dumpHeader(&b, h, opts)
+	if !opts.Body || r.Body == nil {
+		return b.Bytes(), nil
+	}
+	body, err := io.ReadAll(r.Body)
+	r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	b.Write(body)
+	return b.Bytes(), err
+}
+
+// DumpResponse is DumpRequest for responses.
+func DumpResponse(resp *Response, opts DumpOptions) ([]byte, error) {
+	var b bytes.Buffer
+	proto := resp.Proto
+	if proto == "" {
+		proto = "HTTP/1.1"
+	}
+	status := resp.Status
+	if status == "" {
+		status = StatusText(resp.StatusCode)
+	}
+	b.WriteString(proto + " " + strconv.Itoa(resp.StatusCode) + " " + status + "\r\n")
+	dumpHeader(&b, resp.Header, opts)
+	if !opts.Body || resp.Body == nil {
+		return b.Bytes(), nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body = bytes.NewReader(body)
+	b.Write(body)
+	return b.Bytes(), err
+}
+
+func dumpHeader(b *bytes.Buffer, h Header, opts DumpOptions) {
+	redact := opts.Redact
+	if redact == nil {
+		redact = DefaultDumpRedact
+	}
+	masked := make(map[string]bool, len(redact))
+	for _, k := range redact {
+		masked[CanonicalHeaderKey(k)] = true
+	}
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range h[k] {
+			if masked[CanonicalHeaderKey(k)] {
+				v = "[REDACTED]"
+			}
+			b.WriteString(k + ": " + v + "\r\n")
+		}
+	}
+	b.WriteString("\r\n")
+}
@@ -0,0 +1,56 @@
+package httpx
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDumpRequest(t *testing.T) {
+	r := &Request{
+		requestLine: requestLine{Method: MethodPost, RequestURI: "/login", Proto: "HTTP/1.1"},
+		Header:      Header{},
+		Host:        "example.com",
+		Body:        io.NopCloser(strings.NewReader("user=a")),
+	}
+	r.Header.Set("Authorization", "Basic c2VjcmV0")
+	r.Header.Set("Accept", "*/*")
+
+	got, err := DumpRequest(r, DumpOptions{Body: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "POST /login HTTP/1.1\r\nAccept: */*\r\nAuthorization: [REDACTED]\r\nHost: example.com\r\n\r\nuser=a"
+	if string(got) != want {
+		t.Fatalf("got %q", got)
+	}
+	// The body is still readable after dumping.
+	if b, _ := io.ReadAll(r.Body); string(b) != "user=a" {
+		t.Fatalf("body consumed: %q", b)
+	}
+	if r.Header.Get("Host") != "" {
+		t.Fatal("dump must not modify the request header")
+	}
+
+	got, _ = DumpRequest(r, DumpOptions{Redact: []string{}})
+	if !strings.Contains(string(got), "Authorization: Basic c2VjcmV0") || strings.HasSuffix(string(got), "user=a") {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestDumpResponse(t *testing.T) {
+	resp := &Response{StatusCode: StatusNotFound, Header: Header{}, Body: strings.NewReader("nope")}
+	resp.Header.Set("Set-Cookie", "sid=1")
+	resp.Header.Set("X-Trace", "abc")
+	got, err := DumpResponse(resp, DumpOptions{Body: true, Redact: []string{"x-trace"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "HTTP/1.1 404 Not Found\r\nSet-Cookie: sid=1\r\nX-Trace: [REDACTED]\r\n\r\nnope"
+	if string(got) != want {
+		t.Fatalf("got %q", got)
+	}
+	if b, _ := io.ReadAll(resp.Body); string(b) != "nope" {
+		t.Fatalf("body consumed: %q", b)
+	}
+}
@@ -1 +1,193 @@
 package httpx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/andycostintoma/httpx/internal/netx"
+)
+
+// Error is an error carrying the response it should produce. Parsers
+// return it wrapping their sentinel errors, so errors.Is keeps working
+// while callers get the status and a stable code without matching
+// messages. Handlers may return one too.
+type Error struct {
+	Code    string // stable identifier for logs and clients, e.g. "header_too_large"
+	Status  int    // HTTP status to answer with
+	Message string // safe to show to the client
+	Err     error  // underlying cause; may be nil
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return "httpx: " + e.Message
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// WriteProblem answers with an application/problem+json document
+// (RFC 9457) describing e. The underlying cause is not exposed.
+func (e *Error) WriteProblem(ctx context.Context, w io.Writer) error {
+	b, err := json.Marshal(struct {
+		Type   string `json:"type"`
+		Title  string `json:"title"`
+		Status int    `json:"status"`
+		Code   string `json:"code,omitempty"`
+	}{"about:blank", e.Message, e.Status, e.Code})
+	if err != nil {
+		return err
+	}
+	return writeBytes(ctx, w, e.Status, "application/problem+json", b)
+}
+
+// OffsetError records where in a message parsing failed: the operation,
+// the byte offset from the start of the request (or of the body, for
+// chunked framing errors), the line for failures in the head, and the
+// header or trailer field involved, if any. Retrieve it with errors.As to
+// pinpoint malformed input in logs.
+type OffsetError struct {
+	Op     string // e.g. "request line", "header", "chunk size"
+	Offset int64  // byte offset of the failure
+	Line   int64  // 1-based line within the head; zero if not tracked
+	Field  string // field name, when the failure concerns one field
+	Err    error
+}
+
+func (e *OffsetError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Op)
+	if e.Field != "" {
+		fmt.Fprintf(&b, " %q", e.Field)
+	}
+	if e.Line > 0 {
+		fmt.Fprintf(&b, " at line %d, byte %d: %v", e.Line, e.Offset, e.Err)
+	} else {
+		fmt.Fprintf(&b, " at byte %d: %v", e.Offset, e.Err)
+	}
+	return b.String()
+}
+
+func (e *OffsetError) Unwrap() error { return e.Err }
+
+// errorClasses maps known errors to the response they call for. The
+// first entry the error matches with errors.Is wins, so more specific
+// errors come first.
+var errorClasses = []struct {
+	err    error
+	code   string
+	status int
+}{
+	{ErrTLSHandshake, "tls_on_plaintext", StatusBadRequest},
+	{ErrURITooLong, "uri_too_long", StatusRequestURITooLong},
+	{ErrMethodNotImplemented, "method_not_implemented", StatusNotImplemented},
+	{ErrInvalidMethod, "invalid_method", StatusBadRequest},
+
+	{ErrHeaderTooLarge, "header_too_large", StatusRequestHeaderFieldsTooLarge},
+	{ErrKeyTooLarge, "header_too_large", StatusRequestHeaderFieldsTooLarge},
+	{ErrValueTooLarge, "header_too_large", StatusRequestHeaderFieldsTooLarge},
+	{ErrTotalValuesTooLarge, "header_too_large", StatusRequestHeaderFieldsTooLarge},
+	{netx.ErrLineTooLong, "header_too_large", StatusRequestHeaderFieldsTooLarge},
+	{ErrInvalidFieldName, "malformed_header", StatusBadRequest},
+	{ErrInvalidValue, "malformed_header", StatusBadRequest},
+	{ErrMalformedHeader, "malformed_header", StatusBadRequest},
+	{ErrObsFold, "malformed_header", StatusBadRequest},
+
+	{ErrBodyTooLarge, "body_too_large", StatusRequestEntityTooLarge},
+	{ErrTraceTooLarge, "body_too_large", StatusRequestEntityTooLarge},
+	{ErrBadChunk, "malformed_body", StatusBadRequest},
+	{ErrLengthMismatch, "malformed_body", StatusBadRequest},
+	{ErrUnexpectedTrailer, "malformed_body", StatusBadRequest},
+	{ErrChecksumMismatch, "integrity_mismatch", StatusBadRequest},
+	{ErrChecksumMissing, "integrity_mismatch", StatusBadRequest},
+	{ErrBodyDigestMismatch, "integrity_mismatch", StatusBadRequest},
+	{ErrInvalidDigestField, "integrity_mismatch", StatusBadRequest},
+	{ErrUnsupportedMediaType, "unsupported_media_type", StatusUnsupportedMediaType},
+	{ErrEmptyBody, "empty_body", StatusBadRequest},
+	{ErrMalformedJSON, "malformed_json", StatusBadRequest},
+	{ErrRangeNotSatisfiable, "range_not_satisfiable", StatusRequestedRangeNotSatisfiable},
+
+	{ErrHostNotAllowed, "misdirected_request", StatusMisdirectedRequest},
+	{ErrProxyLoop, "loop_detected", StatusLoopDetected},
+	{ErrTooManyHops, "loop_detected", StatusLoopDetected},
+
+	{ErrDigestMissing, "unauthorized", StatusUnauthorized},
+	{ErrDigestMalformed, "unauthorized", StatusUnauthorized},
+	{ErrDigestStale, "unauthorized", StatusUnauthorized},
+	{ErrDigestReplay, "unauthorized", StatusUnauthorized},
+	{ErrDigestMismatch, "unauthorized", StatusUnauthorized},
+	{ErrSignatureMissing, "unauthorized", StatusUnauthorized},
+	{ErrSignatureMalformed, "unauthorized", StatusUnauthorized},
+	{ErrSignatureUnknownKey, "unauthorized", StatusUnauthorized},
+	{ErrSignaturePolicy, "unauthorized", StatusUnauthorized},
+	{ErrSignatureExpired, "unauthorized", StatusUnauthorized},
+	{ErrSignatureMismatch, "unauthorized", StatusUnauthorized},
+	{ErrContentHashMismatch, "unauthorized", StatusUnauthorized},
+	{ErrURLNotSigned, "forbidden", StatusForbidden},
+	{ErrURLExpired, "forbidden", StatusForbidden},
+	{ErrURLBadSignature, "forbidden", StatusForbidden},
+
+	{netx.ErrTooManyConns, "rate_limited", StatusTooManyRequests},
+	{netx.ErrRequestRate, "rate_limited", StatusTooManyRequests},
+	{os.ErrDeadlineExceeded, "request_timeout", StatusRequestTimeout},
+	{context.DeadlineExceeded, "request_timeout", StatusRequestTimeout},
+}
+
+// AsError returns err as an *Error: the one in its chain if any, else
+// one classifying the first known error it wraps. Unknown errors map to
+// 500 with code "internal". AsError(nil) is nil.
+func AsError(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return classifyError(err, StatusInternalServerError, "internal")
+}
+
+// ErrorStatus returns the status code AsError assigns to err, or 200 for
+// a nil error.
+func ErrorStatus(err error) int {
+	if err == nil {
+		return StatusOK
+	}
+	return AsError(err).Status
+}
+
+// classifyError builds an *Error for err from errorClasses, falling back
+// to status and code.
+func classifyError(err error, status int, code string) *Error {
+	var be *BindError
+	if errors.As(err, &be) {
+		status, code = StatusBadRequest, "invalid_parameters"
+	} else {
+		for _, c := range errorClasses {
+			if errors.Is(err, c.err) {
+				status, code = c.status, c.code
+				break
+			}
+		}
+	}
+	return &Error{Code: code, Status: status, Message: StatusText(status), Err: err}
+}
+
+// parseError wraps an error from request parsing as an *Error. Malformed
+// input that matches no specific class is a 400.
+func parseError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return err
+	}
+	return classifyError(err, StatusBadRequest, "malformed_request")
+}
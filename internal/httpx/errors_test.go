@@ -0,0 +1,134 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/andycostintoma/httpx/internal/netx"
+)
+
+func TestParseRequestErrorStatus(t *testing.T) {
+	for _, c := range []struct {
+		raw    string
+		limits ParseLimits
+		status int
+		is     error
+	}{
+		{"GET / HTTP/1.1\r\nBad Name: x\r\n\r\n", ParseLimits{MaxLineBytes: 1024}, StatusBadRequest, ErrInvalidFieldName},
+		{"GET /" + strings.Repeat("a", 100) + " HTTP/1.1\r\n\r\n", ParseLimits{MaxLineBytes: 1024, MaxURIBytes: 10}, StatusRequestURITooLong, ErrURITooLong},
+		{"GET / HTTP/1.1\r\nX: " + strings.Repeat("a", 100) + "\r\n\r\n", ParseLimits{MaxLineBytes: 64}, StatusRequestHeaderFieldsTooLarge, netx.ErrLineTooLong},
+		{"\x16\x03\x01\x00", ParseLimits{MaxLineBytes: 1024}, StatusBadRequest, ErrTLSHandshake},
+		{"", ParseLimits{MaxLineBytes: 1024}, StatusBadRequest, io.EOF},
+	} {
+		_, err := ParseRequest(netx.NewCRLFFastReader(strings.NewReader(c.raw)), c.limits)
+		var e *Error
+		if !errors.As(err, &e) {
+			t.Fatalf("%q: %T %v is not an *Error", c.raw, err, err)
+		}
+		if e.Status != c.status || !errors.Is(err, c.is) {
+			t.Fatalf("%q: got %d %v, want %d wrapping %v", c.raw, e.Status, err, c.status, c.is)
+		}
+	}
+}
+
+func TestAsError(t *testing.T) {
+	if AsError(nil) != nil || ErrorStatus(nil) != StatusOK {
+		t.Fatal("nil error")
+	}
+	e := AsError(fmt.Errorf("read body: %w", ErrBodyTooLarge))
+	if e.Status != StatusRequestEntityTooLarge || e.Code != "body_too_large" || e.Message != "Request Entity Too Large" {
+		t.Fatalf("got %+v", e)
+	}
+	if got := ErrorStatus(ErrSpaceBeforeColon); got != StatusBadRequest {
+		t.Fatalf("wrapped sentinel: %d", got)
+	}
+	for _, err := range []error{ErrBareCR, ErrNULByte} {
+		if e := AsError(err); e.Code != "malformed_header" || e.Status != StatusBadRequest {
+			t.Fatalf("%v: got %+v", err, e)
+		}
+	}
+	if got := ErrorStatus(&BindError{}); got != StatusBadRequest {
+		t.Fatalf("bind error: %d", got)
+	}
+	if e := AsError(errors.New("boom")); e.Status != StatusInternalServerError || e.Code != "internal" {
+		t.Fatalf("got %+v", e)
+	}
+
+	// An *Error in the chain is returned as is.
+	own := &Error{Code: "quota", Status: StatusForbidden, Message: "Quota exceeded"}
+	if AsError(fmt.Errorf("handler: %w", own)) != own || own.Error() != "httpx: Quota exceeded" {
+		t.Fatal("own error")
+	}
+}
+
+func TestErrorWriteProblem(t *testing.T) {
+	var buf bytes.Buffer
+	e := AsError(ErrUnsupportedMediaType)
+	if err := e.WriteProblem(context.Background(), &buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.HasPrefix(out, "HTTP/1.1 415 ") || !strings.Contains(out, "application/problem+json") ||
+		!strings.HasSuffix(out, `{"type":"about:blank","title":"Unsupported Media Type","status":415,"code":"unsupported_media_type"}`) {
+		t.Fatalf("got %q", out)
+	}
+}
+
+func TestOffsetError(t *testing.T) {
+	raw := "GET / HTTP/1.1\r\nHost: x\r\nBad:Name: y\r\nX-B@d: z\r\n\r\n"
+	_, err := ParseRequest(netx.NewCRLFFastReader(strings.NewReader(raw)), ParseLimits{MaxLineBytes: 1024})
+	var oe *OffsetError
+	if !errors.As(err, &oe) {
+		t.Fatalf("%T %v", err, err)
+	}
+	// "X-B@d" starts at byte 38; the '@' is its fourth byte.
+	if oe.Op != "header" || oe.Field != "X-B@d" || oe.Offset != 41 || oe.Line != 4 || !errors.Is(err, ErrInvalidFieldName) {
+		t.Fatalf("got %+v", oe)
+	}
+	if raw[oe.Offset] != '@' {
+		t.Fatalf("offset %d points at %q", oe.Offset, raw[oe.Offset])
+	}
+
+	_, err = ParseRequest(netx.NewCRLFFastReader(strings.NewReader("GET / HTTP/1.1\r\nHost: x")), ParseLimits{MaxLineBytes: 1024})
+	if !errors.As(err, &oe) || oe.Op != "read header" || oe.Offset != 16 || !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("got %v", err)
+	}
+
+	// Chunked framing errors are located within the body.
+	body := "3\r\nabc\r\nzz\r\n"
+	cr := newChunkedReader(context.Background(), strings.NewReader(body), 0, Header{})
+	_, err = io.ReadAll(cr)
+	if !errors.As(err, &oe) || oe.Op != "chunk size" || oe.Offset != 8 || !errors.Is(err, ErrBadChunk) {
+		t.Fatalf("got %v", err)
+	}
+	if got := err.Error(); got != "chunk size at byte 8: httpx: invalid chunk encoding" {
+		t.Fatalf("message %q", got)
+	}
+
+	cr = newChunkedReader(context.Background(), strings.NewReader("5\r\nab"), 0, Header{})
+	if _, err := io.ReadAll(cr); !errors.As(err, &oe) || oe.Offset != 5 || !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("truncated chunk: %v", err)
+	}
+}
+
+func TestOffsetErrorPipelined(t *testing.T) {
+	// Offsets are relative to the failing message, and exact with bare LF.
+	raw := "GET /a HTTP/1.1\r\nHost: x\r\n\r\nGET /b HTTP/1.1\nHost: x\nBad Name: y\n\n"
+	r := netx.NewCRLFFastReader(strings.NewReader(raw))
+	if _, err := ParseRequest(r, ParseLimits{MaxLineBytes: 1024}); err != nil {
+		t.Fatal(err)
+	}
+	_, err := ParseRequest(r, ParseLimits{MaxLineBytes: 1024})
+	var oe *OffsetError
+	if !errors.As(err, &oe) || oe.Offset != 27 || oe.Line != 3 {
+		t.Fatalf("got %v", err)
+	}
+	if got := err.Error(); got != `header "Bad Name" at line 3, byte 27: httpx: invalid header field name: "Bad Name"` {
+		t.Fatalf("message %q", got)
+	}
+}
@@ -0,0 +1,80 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// StrongETag returns a strong entity tag for body: a quoted, truncated
+// SHA-256 digest. Equal bodies always get equal tags.
+func StrongETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`
+}
+
+// ETagMatch reports whether an If-None-Match field value matches etag,
+// using weak comparison as RFC 9110 §13.1.2 requires: W/ prefixes are
+// ignored on both sides. "*" matches any current representation.
+func ETagMatch(ifNoneMatch, etag string) bool {
+	ifNoneMatch = strings.TrimSpace(ifNoneMatch)
+	if ifNoneMatch == "" || etag == "" {
+		return false
+	}
+	if ifNoneMatch == "*" {
+		return true
+	}
+	want := strings.TrimPrefix(etag, "W/")
+	for _, cand := range strings.Split(ifNoneMatch, ",") {
+		cand = strings.TrimPrefix(strings.TrimSpace(cand), "W/")
+		if cand == want {
+			return true
+		}
+	}
+	return false
+}
+
+// notModifiedFields are the fields a 304 keeps from the full response
+// (RFC 9110 §15.4.5).
+var notModifiedFields = []string{"Cache-Control", "Content-Location", "Date", "ETag", "Expires", "Vary"}
+
+// WriteCacheable writes body as a complete response with a strong ETag and
+// Content-Length. If r carries an If-None-Match that matches, a bodiless
+// 304 Not Modified is sent instead, keeping only the validator and caching
+// fields of h. It suits dynamic endpoints whose output is cheap to render
+// but worth revalidating: render into a buffer, then hand it over.
+//
+// An ETag already set in h is kept rather than recomputed.
+func WriteCacheable(ctx context.Context, w io.Writer, r *Request, code int, h Header, body []byte) error {
+	if h == nil {
+		h = Header{}
+	}
+	etag := h.Get("ETag")
+	if etag == "" {
+		etag = StrongETag(body)
+		h.Set("ETag", etag)
+	}
+
+	if r != nil && r.Header != nil && code == StatusOK &&
+		(r.Method == MethodGet || r.Method == MethodHead) && ETagMatch(r.Header.Get("If-None-Match"), etag) {
+		nm := Header{}
+		for _, k := range notModifiedFields {
+			if v := h.Values(k); len(v) > 0 {
+				nm[CanonicalHeaderKey(k)] = v
+			}
+		}
+		return WriteResponse(ctx, w, &Response{StatusCode: StatusNotModified, Header: nm, Request: r})
+	}
+
+	h.Set("Content-Length", strconv.Itoa(len(body)))
+	return WriteResponse(ctx, w, &Response{
+		StatusCode: code,
+		Header:     h,
+		Body:       bytes.NewReader(body),
+		Request:    r,
+	})
+}
@@ -0,0 +1,72 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestStrongETag(t *testing.T) {
+	a, b := StrongETag([]byte("hello")), StrongETag([]byte("hello"))
+	if a != b || !strings.HasPrefix(a, `"`) || !strings.HasSuffix(a, `"`) {
+		t.Fatalf("unexpected tags %s %s", a, b)
+	}
+	if StrongETag([]byte("hellp")) == a {
+		t.Fatal("different bodies must get different tags")
+	}
+}
+
+func TestETagMatch(t *testing.T) {
+	cases := []struct {
+		inm, etag string
+		want      bool
+	}{
+		{`"a"`, `"a"`, true},
+		{`W/"a"`, `"a"`, true},
+		{`"x", "a"`, `W/"a"`, true},
+		{`*`, `"a"`, true},
+		{`"b"`, `"a"`, false},
+		{``, `"a"`, false},
+	}
+	for _, c := range cases {
+		if got := ETagMatch(c.inm, c.etag); got != c.want {
+			t.Fatalf("ETagMatch(%q, %q) = %v", c.inm, c.etag, got)
+		}
+	}
+}
+
+func TestWriteCacheable(t *testing.T) {
+	body := []byte(`{"n":1}`)
+	h := Header{}
+	h.Set("Content-Type", "application/json")
+	h.Set("Cache-Control", "no-cache")
+
+	var buf bytes.Buffer
+	r := &Request{requestLine: requestLine{Method: MethodGet}, Header: Header{}}
+	if err := WriteCacheable(context.Background(), &buf, r, StatusOK, h, body); err != nil {
+		t.Fatal(err)
+	}
+	etag := StrongETag(body)
+	got := buf.String()
+	if !strings.HasPrefix(got, "HTTP/1.1 200 OK\r\n") || !strings.Contains(got, "Etag: "+etag+"\r\n") ||
+		!strings.Contains(got, "Content-Length: 7\r\n") || !strings.HasSuffix(got, string(body)) {
+		t.Fatalf("unexpected response %q", got)
+	}
+
+	// Revalidation with the same tag gets a 304 without body or content fields.
+	buf.Reset()
+	r.Header.Set("If-None-Match", etag)
+	h = Header{}
+	h.Set("Content-Type", "application/json")
+	h.Set("Cache-Control", "no-cache")
+	if err := WriteCacheable(context.Background(), &buf, r, StatusOK, h, body); err != nil {
+		t.Fatal(err)
+	}
+	got = buf.String()
+	if !strings.HasPrefix(got, "HTTP/1.1 304 Not Modified\r\n") || !strings.HasSuffix(got, "\r\n\r\n") ||
+		strings.Contains(got, "Content-Type") || !strings.Contains(got, "Cache-Control: no-cache") ||
+		!strings.Contains(got, "Etag: "+etag+"\r\n") {
+		t.Fatalf("unexpected 304 %q", got)
+	}
+}
@@ -0,0 +1,70 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	"github.com/andycostintoma/httpx/internal/netx"
+)
+
+// The Fuzz* functions are pure entry points for external fuzzing engines
+// (go-fuzz, libFuzzer harnesses, OSS-Fuzz). Each parses data with bounded
+// limits and returns 1 when the input was accepted, so the engine can
+// prioritise it, and 0 otherwise. They panic only if a parser breaks one
+// of its own invariants. Native Go fuzz targets in fuzz_test.go call them.
+
+// fuzzMaxBody bounds how much body the fuzz entry points will read.
+const fuzzMaxBody = 1 << 20
+
+// FuzzParseRequest parses data as a request head followed by its body.
+func FuzzParseRequest(data []byte) int {
+	br := bytes.NewReader(data)
+	r := netx.NewCRLFFastReader(br)
+	req, err := ParseRequest(r, ParseLimits{MaxLineBytes: 8 << 10, MaxHeaderBytes: 64 << 10})
+	if err != nil {
+		return 0
+	}
+	if !isValidMethod(req.Method) || req.URL == nil {
+		panic("httpx: ParseRequest accepted an invalid request line")
+	}
+	for k := range req.Header {
+		if !isValidFieldName(k) {
+			panic("httpx: ParseRequest accepted an invalid field name")
+		}
+	}
+	body, _, err := NewBodyReader(context.Background(), req, r, fuzzMaxBody)
+	if err != nil {
+		return 0
+	}
+	if _, err := io.Copy(io.Discard, body); err != nil {
+		return 0
+	}
+	return 1
+}
+
+// FuzzChunkedReader decodes data as a chunked body with trailers.
+func FuzzChunkedReader(data []byte) int {
+	h := Header{}
+	cr := newChunkedReader(context.Background(), bytes.NewReader(data), fuzzMaxBody, h)
+	n, err := io.Copy(io.Discard, cr)
+	if err != nil {
+		return 0
+	}
+	if n > fuzzMaxBody {
+		panic("httpx: chunked reader exceeded its limit")
+	}
+	return 1
+}
+
+// FuzzParseRequestURI parses data as a request-target.
+func FuzzParseRequestURI(data []byte) int {
+	u, err := ParseRequestURI(string(data))
+	if err != nil {
+		return 0
+	}
+	if u.Path == "" {
+		panic("httpx: ParseRequestURI returned an empty path")
+	}
+	return 1
+}
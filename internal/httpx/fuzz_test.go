@@ -0,0 +1,76 @@
+package httpx
+
+import "testing"
+
+// fuzzRequestSeeds are well-formed requests plus known smuggling and
+// desync payloads, used as the seed corpus for FuzzRequest.
+var fuzzRequestSeeds = []string{
+	"GET / HTTP/1.1\r\nHost: a\r\n\r\n",
+	"POST /u HTTP/1.1\r\nHost: a\r\nContent-Length: 3\r\n\r\nabc",
+	"POST / HTTP/1.1\r\nHost: a\r\nTransfer-Encoding: chunked\r\n\r\n3\r\nabc\r\n0\r\nX-T: 1\r\n\r\n",
+	// CL.TE and TE.CL conflicts.
+	"POST / HTTP/1.1\r\nHost: a\r\nContent-Length: 4\r\nTransfer-Encoding: chunked\r\n\r\n0\r\n\r\nG",
+	"POST / HTTP/1.1\r\nHost: a\r\nTransfer-Encoding: chunked\r\nContent-Length: 6\r\n\r\n0\r\n\r\nX",
+	// Obfuscated Transfer-Encoding.
+	"POST / HTTP/1.1\r\nHost: a\r\nTransfer-Encoding : chunked\r\n\r\n0\r\n\r\n",
+	"POST / HTTP/1.1\r\nHost: a\r\nTransfer-Encoding: xchunked\r\n\r\n0\r\n\r\n",
+	"POST / HTTP/1.1\r\nHost: a\r\nTransfer-Encoding:\tchunked\r\n\r\n0\r\n\r\n",
+	// Duplicate and malformed lengths.
+	"POST / HTTP/1.1\r\nHost: a\r\nContent-Length: 1\r\nContent-Length: 2\r\n\r\nab",
+	"POST / HTTP/1.1\r\nHost: a\r\nContent-Length: +1\r\n\r\na",
+	// Bare CR/LF, NUL and folding.
+	"GET / HTTP/1.1\rHost: a\r\n\r\n",
+	"GET / HTTP/1.1\nHost: a\n\n",
+	"GET / HTTP/1.1\r\nHost: a\x00b\r\n\r\n",
+	"GET / HTTP/1.1\r\nX: a\r\n b\r\n\r\n",
+	// Chunk-size tricks.
+	"POST / HTTP/1.1\r\nTransfer-Encoding: chunked\r\n\r\n+3\r\nabc\r\n0\r\n\r\n",
+	"POST / HTTP/1.1\r\nTransfer-Encoding: chunked\r\n\r\n3;ext=\"x\"\r\nabc\r\n0\r\n\r\n",
+	"POST / HTTP/1.1\r\nTransfer-Encoding: chunked\r\n\r\nffffffffffffffff1\r\n",
+	"\x16\x03\x01\x00",
+	"GET /\r\n",
+}
+
+func FuzzRequest(f *testing.F) {
+	for _, s := range fuzzRequestSeeds {
+		f.Add([]byte(s))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) { FuzzParseRequest(data) })
+}
+
+func FuzzChunked(f *testing.F) {
+	for _, s := range []string{
+		"0\r\n\r\n",
+		"3\r\nabc\r\n0\r\n\r\n",
+		"3;a=b\r\nabc\r\n0\r\nTrailer: x\r\n\r\n",
+		"+3\r\nabc\r\n0\r\n\r\n",
+		"0x3\r\nabc\r\n0\r\n\r\n",
+		"3\nabc\n0\n\n",
+		"8000000000000000\r\n",
+	} {
+		f.Add([]byte(s))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) { FuzzChunkedReader(data) })
+}
+
+func FuzzRequestURI(f *testing.F) {
+	for _, s := range []string{"/", "*", "/a?b=c", "http://h", "https://H:443/p?q", "//x", "/%zz", "http://", "/a b"} {
+		f.Add([]byte(s))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) { FuzzParseRequestURI(data) })
+}
+
+func TestFuzzEntryPoints(t *testing.T) {
+	if FuzzParseRequest([]byte(fuzzRequestSeeds[1])) != 1 {
+		t.Fatal("valid request rejected")
+	}
+	if FuzzParseRequest([]byte("\x16\x03\x01\x00")) != 0 {
+		t.Fatal("TLS record accepted")
+	}
+	if FuzzChunkedReader([]byte("3\r\nabc\r\n0\r\n\r\n")) != 1 || FuzzChunkedReader([]byte("zz\r\n")) != 0 {
+		t.Fatal("chunked entry point")
+	}
+	if FuzzParseRequestURI([]byte("/x")) != 1 || FuzzParseRequestURI([]byte("")) != 0 {
+		t.Fatal("uri entry point")
+	}
+}
@@ -1,11 +1,12 @@
 package httpx
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
-	"strings"
-	"unicode"
+
+	"github.com/andycostintoma/httpx/internal/netx"
 )
 
 type Header map[string][]string
@@ -18,35 +19,168 @@ var (
 	ErrKeyTooLarge         = errors.New("httpx: header key too long")
 	ErrValueTooLarge       = errors.New("httpx: header value too long")
 	ErrTotalValuesTooLarge = errors.New("httpx: total header values too large")
+	ErrMalformedHeader     = errors.New("httpx: malformed header line")
+	ErrObsFold             = errors.New("httpx: obsolete header line folding")
 )
 
 // CanonicalHeaderKey returns the canonical format of the HTTP header key,
-// identical to textproto.CanonicalMIMEHeaderKey from the stdlib.
+// identical to textproto.CanonicalMIMEHeaderKey from the stdlib: the first
+// letter and any letter following a hyphen are upper case, the rest lower
+// case. Keys containing bytes that are not valid in a field name are
+// returned unchanged.
+//
+// Already-canonical keys and common header keys are returned without
+// allocating.
 func CanonicalHeaderKey(s string) string {
-	if s == "" {
-		return ""
+	upper := true
+	change := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !isTokenByte(c) {
+			return s
+		}
+		if upper && 'a' <= c && c <= 'z' || !upper && 'A' <= c && c <= 'Z' {
+			change = true
+		}
+		upper = c == '-'
 	}
-	parts := strings.Split(s, "-")
-	for i, p := range parts {
-		if p == "" {
-			continue
+	if !change {
+		return s
+	}
+	return canonicalSlow(s)
+}
+
+// canonicalSlow canonicalizes s, a valid key known to need changes.
+func canonicalSlow(s string) string {
+	var stack [64]byte
+	var b []byte
+	if len(s) <= len(stack) {
+		b = stack[:len(s)]
+	} else {
+		b = make([]byte, len(s))
+	}
+	copy(b, s)
+	canonicalizeInPlace(b)
+	if k, ok := commonHeader[string(b)]; ok {
+		return k
+	}
+	return string(b)
+}
+
+// canonicalHeaderKeyBytes is CanonicalHeaderKey for a byte slice, used by the
+// parser to avoid converting the raw name to a string first. b is not
+// modified. Common keys are returned interned without allocating.
+func canonicalHeaderKeyBytes(b []byte) string {
+	for _, x := range b {
+		if !isTokenByte(x) {
+			return string(b)
 		}
-		runes := []rune(p)
-		runes[0] = unicode.ToUpper(runes[0])
-		for j := 1; j < len(runes); j++ {
-			runes[j] = unicode.ToLower(runes[j])
+	}
+	var stack [64]byte
+	var c []byte
+	if len(b) <= len(stack) {
+		c = stack[:len(b)]
+	} else {
+		c = make([]byte, len(b))
+	}
+	copy(c, b)
+	canonicalizeInPlace(c)
+	if k, ok := commonHeader[string(c)]; ok {
+		return k
+	}
+	return string(c)
+}
+
+// canonicalizeInPlace rewrites ASCII letters in b to canonical case.
+func canonicalizeInPlace(b []byte) {
+	upper := true
+	for i, c := range b {
+		if upper && 'a' <= c && c <= 'z' {
+			b[i] = c - ('a' - 'A')
+		} else if !upper && 'A' <= c && c <= 'Z' {
+			b[i] = c + ('a' - 'A')
 		}
-		parts[i] = string(runes)
+		upper = c == '-'
 	}
-	return strings.Join(parts, "-")
 }
 
+// commonHeader interns the canonical forms of frequently seen header keys,
+// so parsing and lookups of these keys never allocate a key string.
+var commonHeader = func() map[string]string {
+	keys := []string{
+		"Accept",
+		"Accept-Charset",
+		"Accept-Encoding",
+		"Accept-Language",
+		"Accept-Ranges",
+		"Access-Control-Allow-Origin",
+		"Age",
+		"Allow",
+		"Authorization",
+		"Cache-Control",
+		"Connection",
+		"Content-Disposition",
+		"Content-Encoding",
+		"Content-Language",
+		"Content-Length",
+		"Content-Location",
+		"Content-Range",
+		"Content-Type",
+		"Cookie",
+		"Date",
+		"Etag",
+		"Expect",
+		"Expires",
+		"Forwarded",
+		"Host",
+		"If-Match",
+		"If-Modified-Since",
+		"If-None-Match",
+		"If-Range",
+		"If-Unmodified-Since",
+		"Last-Modified",
+		"Location",
+		"Origin",
+		"Pragma",
+		"Priority",
+		"Range",
+		"Referer",
+		"Retry-After",
+		"Server",
+		"Set-Cookie",
+		"Te",
+		"Trailer",
+		"Transfer-Encoding",
+		"Upgrade",
+		"User-Agent",
+		"Vary",
+		"Via",
+		"Www-Authenticate",
+		"X-Forwarded-For",
+		"X-Forwarded-Host",
+		"X-Forwarded-Proto",
+		"X-Request-Id",
+	}
+	m := make(map[string]string, len(keys))
+	for _, k := range keys {
+		m[k] = k
+	}
+	return m
+}()
+
 // Add appends a value to the header key, canonicalizing the key first.
 func (h Header) Add(key, value string) {
 	k := CanonicalHeaderKey(key)
 	h[k] = append(h[k], value)
 }
 
+// AddBytes is Add for raw key and value bytes, as produced by the parser.
+// Common keys are interned, so only the value string is allocated.
+func (h Header) AddBytes(key, value []byte) {
+	k := canonicalHeaderKeyBytes(key)
+	h[k] = append(h[k], string(value))
+}
+
 // Set replaces any existing values for key with a single value.
 func (h Header) Set(key, value string) {
 	k := CanonicalHeaderKey(key)
@@ -118,22 +252,27 @@ func isValidFieldName(s string) bool {
 		return false
 	}
 	for i := 0; i < len(s); i++ {
-		c := s[i]
-		switch {
-		case c >= 'A' && c <= 'Z',
-			c >= 'a' && c <= 'z',
-			c >= '0' && c <= '9',
-			c == '!', c == '#', c == '$', c == '%', c == '&', c == '\'',
-			c == '*', c == '+', c == '-', c == '.', c == '^', c == '_',
-			c == '`', c == '|', c == '~':
-			continue
-		default:
+		if !isTokenByte(s[i]) {
 			return false
 		}
 	}
 	return true
 }
 
+// isTokenByte reports whether c is an RFC 7230 tchar.
+func isTokenByte(c byte) bool {
+	switch {
+	case c >= 'A' && c <= 'Z',
+		c >= 'a' && c <= 'z',
+		c >= '0' && c <= '9',
+		c == '!', c == '#', c == '$', c == '%', c == '&', c == '\'',
+		c == '*', c == '+', c == '-', c == '.', c == '^', c == '_',
+		c == '`', c == '|', c == '~':
+		return true
+	}
+	return false
+}
+
 // isValidValue checks that a value contains only printable ASCII or HTAB,
 // per RFC 7230 §3.2.6 (no CTL except HTAB).
 func isValidValue(s string) bool {
@@ -178,3 +317,95 @@ func ValidateHeader(h Header, lim HeaderLimits) error {
 	}
 	return nil
 }
+
+// -----------------------------------------------------------------------------
+// Parsing
+// -----------------------------------------------------------------------------
+
+// ObsFoldMode selects how obsolete header line folding (a header line that
+// begins with SP or HTAB, continuing the previous one) is treated.
+type ObsFoldMode int
+
+const (
+	// ObsFoldReject fails parsing with ErrObsFold; servers answer 400.
+	ObsFoldReject ObsFoldMode = iota
+	// ObsFoldUnfold replaces each fold with a single SP (RFC 7230 §3.2.4).
+	ObsFoldUnfold
+)
+
+// readHeader reads header lines up to and including the blank line that ends
+// the header section. When limits.MaxHeaderBytes is set it bounds the whole
+// section; otherwise each line is bounded by limits.MaxLineBytes.
+func readHeader(r *netx.CRLFFastReader, limits ParseLimits) (Header, error) {
+	h := make(Header)
+	if err := readHeaderInto(h, r, limits, r.Offset(), r.Line()); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// readHeaderInto is readHeader adding fields to an existing, empty Header.
+// base and baseLine are r's Offset and Line at the start of the message;
+// errors are *OffsetError values locating the failure relative to them.
+func readHeaderInto(h Header, r *netx.CRLFFastReader, limits ParseLimits, base, baseLine int64) error {
+	remaining := limits.MaxHeaderBytes
+	for {
+		// Position of the line about to be read, within the message.
+		off, lineNo := r.Offset()-base, r.Line()-baseLine+1
+		fail := func(at int64, field string, err error) error {
+			return &OffsetError{Op: "header", Offset: off + at, Line: lineNo, Field: field, Err: err}
+		}
+
+		max := limits.MaxLineBytes
+		if limits.MaxHeaderBytes > 0 {
+			if remaining <= 0 {
+				return fail(0, "", fmt.Errorf("%w: header section exceeds %d bytes", netx.ErrLineTooLong, limits.MaxHeaderBytes))
+			}
+			max = remaining
+		}
+
+		var line []byte
+		var err error
+		if limits.ObsFold == ObsFoldUnfold {
+			line, err = r.ReadContinuedLine(max)
+		} else {
+			line, _, err = r.ReadLine(max)
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return &OffsetError{Op: "read header", Offset: off, Line: lineNo, Err: err}
+		}
+		if len(line) == 0 {
+			return nil
+		}
+		remaining -= len(line) + 2
+
+		// Leading whitespace marks a fold. In unfold mode folds are already
+		// merged, so this is whitespace before the first field: also invalid.
+		if line[0] == ' ' || line[0] == '\t' {
+			return fail(0, "", ErrObsFold)
+		}
+
+		if limits.Strict {
+			if err := checkStrictLine(line); err != nil {
+				return fail(0, "", err)
+			}
+		}
+		colon := bytes.IndexByte(line, ':')
+		if colon <= 0 {
+			return fail(0, "", fmt.Errorf("%w: %q", ErrMalformedHeader, line))
+		}
+		name := line[:colon]
+		if c := name[len(name)-1]; c == ' ' || c == '\t' {
+			return fail(int64(colon)-1, string(name), fmt.Errorf("%w: %q", ErrSpaceBeforeColon, name))
+		}
+		for i, c := range name {
+			if !isTokenByte(c) {
+				return fail(int64(i), string(name), fmt.Errorf("%w: %q", ErrInvalidFieldName, name))
+			}
+		}
+		h.AddBytes(name, bytes.Trim(line[colon+1:], " \t"))
+	}
+}
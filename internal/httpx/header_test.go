@@ -112,3 +112,51 @@ func TestCanonicalHeaderKeyBehavior(t *testing.T) {
 		}
 	}
 }
+
+func TestCanonicalHeaderKeyInvalidUnchanged(t *testing.T) {
+	// Mirrors textproto: keys with non-token bytes are returned as-is.
+	for _, k := range []string{"bad key", "x:y", "ümlaut"} {
+		if got := CanonicalHeaderKey(k); got != k {
+			t.Fatalf("CanonicalHeaderKey(%q) = %q, want unchanged", k, got)
+		}
+	}
+}
+
+func TestCanonicalHeaderKeyNoAlloc(t *testing.T) {
+	for _, k := range []string{"Content-Type", "content-type", "USER-AGENT", "X-Custom-Id"} {
+		k := k
+		if n := testing.AllocsPerRun(100, func() { _ = CanonicalHeaderKey(k) }); n != 0 {
+			t.Fatalf("CanonicalHeaderKey(%q) allocates %v times", k, n)
+		}
+	}
+}
+
+func BenchmarkCanonicalHeaderKey(b *testing.B) {
+	keys := []string{"Content-Type", "content-length", "x-request-id", "ACCEPT"}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = CanonicalHeaderKey(keys[i%len(keys)])
+	}
+}
+
+func TestHeaderAddBytes(t *testing.T) {
+	h := Header{}
+	h.AddBytes([]byte("content-type"), []byte("text/plain"))
+	h.AddBytes([]byte("X-CUSTOM"), []byte("a"))
+	h.AddBytes([]byte("x-custom"), []byte("b"))
+	if got := h.Get("Content-Type"); got != "text/plain" {
+		t.Fatalf("Content-Type = %q", got)
+	}
+	if v := h.Values("X-Custom"); len(v) != 2 || v[0] != "a" || v[1] != "b" {
+		t.Fatalf("X-Custom = %#v", v)
+	}
+}
+
+func TestCommonHeaderKeyBytesNoAlloc(t *testing.T) {
+	for _, k := range [][]byte{[]byte("content-length"), []byte("Host"), []byte("IF-NONE-MATCH")} {
+		k := k
+		if n := testing.AllocsPerRun(100, func() { _ = canonicalHeaderKeyBytes(k) }); n != 0 {
+			t.Fatalf("canonicalHeaderKeyBytes(%q) allocates %v times", k, n)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package httpx
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultHealthCheckTimeout bounds a health check registered without its
+// own timeout.
+const DefaultHealthCheckTimeout = 5 * time.Second
+
+// HealthCheckFunc reports a dependency's health; a nil error means healthy.
+// It must honor ctx, which is cancelled when the check's timeout expires.
+type HealthCheckFunc func(ctx context.Context) error
+
+// HealthCheckOptions configures a registered check.
+type HealthCheckOptions struct {
+	Timeout time.Duration // DefaultHealthCheckTimeout if zero
+
+	// ReadinessOnly excludes the check from liveness. Use it for
+	// dependencies (databases, upstreams) whose failure should take the
+	// instance out of rotation but not get it restarted.
+	ReadinessOnly bool
+}
+
+// Health is a registry of named checks behind liveness (/healthz) and
+// readiness (/readyz) probes. The zero value is ready to use.
+type Health struct {
+	mu       sync.RWMutex
+	checks   map[string]healthCheck
+	draining atomic.Bool
+}
+
+type healthCheck struct {
+	fn   HealthCheckFunc
+	opts HealthCheckOptions
+}
+
+// HealthReport is the JSON document written by the probe helpers.
+type HealthReport struct {
+	Status string                       `json:"status"` // "ok", "fail" or "draining"
+	Checks map[string]HealthCheckResult `json:"checks,omitempty"`
+}
+
+// HealthCheckResult is the outcome of a single check.
+type HealthCheckResult struct {
+	Status   string `json:"status"` // "ok" or "fail"
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// OK reports whether the probe passed.
+func (r HealthReport) OK() bool { return r.Status == "ok" }
+
+// Register adds or replaces the check called name.
+func (h *Health) Register(name string, fn HealthCheckFunc, opts HealthCheckOptions) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.checks == nil {
+		h.checks = make(map[string]healthCheck)
+	}
+	h.checks[name] = healthCheck{fn: fn, opts: opts}
+}
+
+// Unregister removes the check called name.
+func (h *Health) Unregister(name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.checks, name)
+}
+
+// SetDraining marks the instance as shutting down: readiness fails from
+// now on so load balancers stop routing new traffic while in-flight
+// requests finish. Call it at the start of a graceful shutdown, before
+// closing listeners. Liveness is unaffected.
+func (h *Health) SetDraining(draining bool) { h.draining.Store(draining) }
+
+// Draining reports whether SetDraining(true) is in effect.
+func (h *Health) Draining() bool { return h.draining.Load() }
+
+// Live runs the liveness checks concurrently.
+func (h *Health) Live(ctx context.Context) HealthReport { return h.run(ctx, false) }
+
+// Ready runs every check concurrently. While draining it fails without
+// running any.
+func (h *Health) Ready(ctx context.Context) HealthReport {
+	if h.Draining() {
+		return HealthReport{Status: "draining"}
+	}
+	return h.run(ctx, true)
+}
+
+func (h *Health) run(ctx context.Context, readiness bool) HealthReport {
+	h.mu.RLock()
+	names := make([]string, 0, len(h.checks))
+	for name, c := range h.checks {
+		if readiness || !c.opts.ReadinessOnly {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	checks := make([]healthCheck, len(names))
+	for i, name := range names {
+		checks[i] = h.checks[name]
+	}
+	h.mu.RUnlock()
+
+	results := make([]HealthCheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = runHealthCheck(ctx, c)
+		}()
+	}
+	wg.Wait()
+
+	report := HealthReport{Status: "ok", Checks: make(map[string]HealthCheckResult, len(names))}
+	for i, name := range names {
+		report.Checks[name] = results[i]
+		if results[i].Status != "ok" {
+			report.Status = "fail"
+		}
+	}
+	return report
+}
+
+// runHealthCheck runs c under its timeout. A check that ignores its
+// context is reported as failed once the timeout passes; it keeps running
+// in the background, so checks must not block forever.
+func runHealthCheck(ctx context.Context, c healthCheck) HealthCheckResult {
+	timeout := c.opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultHealthCheckTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				done <- fmt.Errorf("panic: %v", p)
+			}
+		}()
+		done <- c.fn(ctx)
+	}()
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	res := HealthCheckResult{Status: "ok", Duration: time.Since(start).Round(time.Microsecond).String()}
+	if err != nil {
+		res.Status, res.Error = "fail", err.Error()
+	}
+	return res
+}
+
+// WriteHealthz answers a liveness probe: 200 with the report when every
+// liveness check passes, 503 otherwise.
+func (h *Health) WriteHealthz(ctx context.Context, w io.Writer) error {
+	return writeHealthReport(ctx, w, h.Live(ctx))
+}
+
+// WriteReadyz answers a readiness probe like WriteHealthz, running all
+// checks and failing while draining.
+func (h *Health) WriteReadyz(ctx context.Context, w io.Writer) error {
+	return writeHealthReport(ctx, w, h.Ready(ctx))
+}
+
+func writeHealthReport(ctx context.Context, w io.Writer, r HealthReport) error {
+	code := StatusOK
+	if !r.OK() {
+		code = StatusServiceUnavailable
+	}
+	return EncodeJSON(ctx, w, code, r, EncodeJSONOptions{})
+}
@@ -0,0 +1,76 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHealthLiveAndReady(t *testing.T) {
+	var h Health
+	if r := h.Ready(context.Background()); !r.OK() {
+		t.Fatalf("empty registry should be ready: %+v", r)
+	}
+
+	dbErr := errors.New("connection refused")
+	h.Register("self", func(context.Context) error { return nil }, HealthCheckOptions{})
+	h.Register("db", func(context.Context) error { return dbErr }, HealthCheckOptions{ReadinessOnly: true})
+
+	live := h.Live(context.Background())
+	if !live.OK() || len(live.Checks) != 1 {
+		t.Fatalf("liveness must skip readiness-only checks: %+v", live)
+	}
+	ready := h.Ready(context.Background())
+	if ready.OK() || ready.Checks["db"].Error != dbErr.Error() || ready.Checks["self"].Status != "ok" {
+		t.Fatalf("readiness %+v", ready)
+	}
+
+	h.Unregister("db")
+	if !h.Ready(context.Background()).OK() {
+		t.Fatal("ready after removing failing check")
+	}
+	h.SetDraining(true)
+	if r := h.Ready(context.Background()); r.Status != "draining" {
+		t.Fatalf("got %+v", r)
+	}
+	if !h.Live(context.Background()).OK() {
+		t.Fatal("draining must not fail liveness")
+	}
+}
+
+func TestHealthTimeoutAndPanic(t *testing.T) {
+	var h Health
+	block := make(chan struct{})
+	defer close(block)
+	h.Register("stuck", func(context.Context) error { <-block; return nil }, HealthCheckOptions{Timeout: 10 * time.Millisecond})
+	h.Register("boom", func(context.Context) error { panic("oops") }, HealthCheckOptions{})
+
+	start := time.Now()
+	r := h.Live(context.Background())
+	if time.Since(start) > time.Second {
+		t.Fatal("timeout not enforced")
+	}
+	if r.Checks["stuck"].Error != context.DeadlineExceeded.Error() || !strings.Contains(r.Checks["boom"].Error, "oops") {
+		t.Fatalf("got %+v", r)
+	}
+}
+
+func TestHealthWriteProbes(t *testing.T) {
+	var h Health
+	var buf bytes.Buffer
+	if err := h.WriteHealthz(context.Background(), &buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "HTTP/1.1 200 OK\r\n") || !strings.HasSuffix(buf.String(), `{"status":"ok"}`+"\n") {
+		t.Fatalf("got %q", buf.String())
+	}
+	buf.Reset()
+	h.SetDraining(true)
+	h.WriteReadyz(context.Background(), &buf)
+	if !strings.HasPrefix(buf.String(), "HTTP/1.1 503 Service Unavailable\r\n") || !strings.Contains(buf.String(), `"draining"`) {
+		t.Fatalf("got %q", buf.String())
+	}
+}
@@ -0,0 +1,127 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WriteJSON encodes v as JSON and writes it as a complete response with the
+// given status code, using EncodeJSON's default framing.
+func WriteJSON(ctx context.Context, w io.Writer, code int, v any) error {
+	return EncodeJSON(ctx, w, code, v, EncodeJSONOptions{})
+}
+
+// WriteText writes s as a text/plain response.
+func WriteText(ctx context.Context, w io.Writer, code int, s string) error {
+	return writeBytes(ctx, w, code, "text/plain; charset=utf-8", []byte(s))
+}
+
+// WriteHTML writes s as a text/html response.
+func WriteHTML(ctx context.Context, w io.Writer, code int, s string) error {
+	return writeBytes(ctx, w, code, "text/html; charset=utf-8", []byte(s))
+}
+
+// NoContent writes a bodiless 204 response.
+func NoContent(ctx context.Context, w io.Writer) error {
+	return WriteResponse(ctx, w, &Response{StatusCode: StatusNoContent, Header: Header{}})
+}
+
+// WriteOptions answers an OPTIONS request with a bodiless 204 response whose
+// Allow header lists methods. For "OPTIONS *" pass the methods the server
+// supports as a whole, e.g. from MethodRegistry.Methods.
+func WriteOptions(ctx context.Context, w io.Writer, methods []string) error {
+	h := Header{}
+	h.Set("Allow", strings.Join(methods, ", "))
+	return WriteResponse(ctx, w, &Response{StatusCode: StatusNoContent, Header: h})
+}
+
+// Redirect replies to r with a redirect to target. Relative targets are
+// resolved against r's path, and unsafe bytes in the Location value are
+// percent-encoded. code should be one of 301, 302, 303, 307 or 308.
+func Redirect(w io.Writer, r *Request, target string, code int) error {
+	if code < 300 || code > 399 {
+		return fmt.Errorf("httpx: invalid redirect code %d", code)
+	}
+	loc := escapeLocation(resolveRedirect(r, target))
+
+	// A short hypertext note for clients that do not follow redirects.
+	// WriteResponse drops it for HEAD requests.
+	body := "<a href=\"" + htmlEscape(loc) + "\">Redirecting</a>.\n"
+	h := Header{}
+	h.Set("Location", loc)
+	h.Set("Content-Type", "text/html; charset=utf-8")
+	h.Set("Content-Length", strconv.Itoa(len(body)))
+	return WriteResponse(r.Context(), w, &Response{
+		StatusCode: code,
+		Header:     h,
+		Body:       strings.NewReader(body),
+		Request:    r,
+	})
+}
+
+// writeBytes emits a fixed-length response with the given Content-Type.
+func writeBytes(ctx context.Context, w io.Writer, code int, ctype string, b []byte) error {
+	h := Header{}
+	h.Set("Content-Type", ctype)
+	h.Set("Content-Length", strconv.Itoa(len(b)))
+	return WriteResponse(ctx, w, &Response{
+		StatusCode: code,
+		Header:     h,
+		Body:       bytes.NewReader(b),
+	})
+}
+
+// resolveRedirect makes a path-relative target absolute with respect to r.
+// Absolute URLs and absolute paths are returned unchanged.
+func resolveRedirect(r *Request, target string) string {
+	if r == nil || r.URL == nil || target == "" {
+		return target
+	}
+	if strings.HasPrefix(target, "/") || strings.Contains(target, "://") {
+		return target
+	}
+	dir := r.URL.Path
+	if i := strings.LastIndexByte(dir, '/'); i >= 0 {
+		dir = dir[:i+1]
+	} else {
+		dir = "/"
+	}
+	return dir + target
+}
+
+// escapeLocation percent-encodes bytes that may not appear literally in a
+// Location header (controls, space, non-ASCII, and a few delimiters).
+// Existing escapes are preserved.
+func escapeLocation(s string) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c <= ' ' || c >= 0x7f || c == '"' || c == '<' || c == '>' ||
+			c == '\\' || c == '^' || c == '`' || c == '{' || c == '|' || c == '}' {
+			b.WriteByte('%')
+			b.WriteByte(hex[c>>4])
+			b.WriteByte(hex[c&0xf])
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+var htmlReplacer = strings.NewReplacer(
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;",
+	`"`, "&#34;",
+	"'", "&#39;",
+)
+
+// htmlEscape escapes s for safe inclusion in HTML attribute values.
+func htmlEscape(s string) string {
+	return htmlReplacer.Replace(s)
+}
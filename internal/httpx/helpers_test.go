@@ -0,0 +1,113 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteJSON(context.Background(), &buf, 201, map[string]string{"a": "<b>"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	if !strings.HasPrefix(got, "HTTP/1.1 201 ") {
+		t.Fatalf("bad status line: %q", got)
+	}
+	if !strings.Contains(got, "Content-Type: application/json\r\n") {
+		t.Fatalf("missing Content-Type: %q", got)
+	}
+	body := "{\"a\":\"<b>\"}\n"
+	if !strings.Contains(got, "Content-Length: 12\r\n") || !strings.HasSuffix(got, "\r\n\r\n"+body) {
+		t.Fatalf("bad framing: %q", got)
+	}
+}
+
+func TestWriteJSONEncodeError(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJSON(context.Background(), &buf, 200, make(chan int)); err == nil {
+		t.Fatal("expected encode error")
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("nothing should be written on encode error, got %q", buf.String())
+	}
+}
+
+func TestWriteTextAndHTML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteText(context.Background(), &buf, 200, "hi"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "Content-Type: text/plain; charset=utf-8\r\n") ||
+		!strings.HasSuffix(buf.String(), "\r\n\r\nhi") {
+		t.Fatalf("bad text response: %q", buf.String())
+	}
+
+	buf.Reset()
+	if err := WriteHTML(context.Background(), &buf, 200, "<p>x</p>"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "Content-Type: text/html; charset=utf-8\r\n") {
+		t.Fatalf("bad html response: %q", buf.String())
+	}
+}
+
+func TestNoContent(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NoContent(context.Background(), &buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "HTTP/1.1 204 ") || !strings.HasSuffix(buf.String(), "\r\n\r\n") {
+		t.Fatalf("bad 204: %q", buf.String())
+	}
+}
+
+func TestRedirect(t *testing.T) {
+	req := &Request{requestLine: requestLine{Method: "GET"}, URL: &URL{Path: "/docs/a/page"}}
+
+	cases := []struct{ target, want string }{
+		{"other", "/docs/a/other"},
+		{"/abs path", "/abs%20path"},
+		{"https://ex.com/ü", "https://ex.com/%C3%BC"},
+		{"/already%20ok", "/already%20ok"},
+	}
+	for _, c := range cases {
+		var buf bytes.Buffer
+		if err := Redirect(&buf, req, c.target, 302); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(buf.String(), "Location: "+c.want+"\r\n") {
+			t.Fatalf("target %q: want Location %q in %q", c.target, c.want, buf.String())
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := Redirect(&buf, req, "/x", 200); err == nil {
+		t.Fatal("expected error for non-3xx code")
+	}
+}
+
+func TestRedirectHeadHasNoBody(t *testing.T) {
+	req := &Request{requestLine: requestLine{Method: "HEAD"}, URL: &URL{Path: "/"}}
+	var buf bytes.Buffer
+	if err := Redirect(&buf, req, "/x", 301); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(buf.String(), "\r\n\r\n") || strings.Contains(buf.String(), "href") {
+		t.Fatalf("HEAD redirect must not carry a body: %q", buf.String())
+	}
+}
+
+func TestWriteOptions(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteOptions(context.Background(), &buf, NewMethodRegistry().Methods()); err != nil {
+		t.Fatal(err)
+	}
+	want := "HTTP/1.1 204 No Content\r\nAllow: CONNECT, DELETE, GET, HEAD, OPTIONS, PATCH, POST, PUT\r\n\r\n"
+	if buf.String() != want {
+		t.Fatalf("got %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,107 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+)
+
+// ErrHostNotAllowed is returned by HostPolicy.Check for a request whose
+// Host is denied or not on the allow-list. Answer it with
+// WriteMisdirected.
+var ErrHostNotAllowed = errors.New("httpx: host not allowed")
+
+// ErrHostSNIMismatch is returned by HostPolicy.Check when a TLS request
+// names a different host than the one its connection was opened for.
+var ErrHostSNIMismatch = fmt.Errorf("%w: host does not match TLS server name", ErrHostNotAllowed)
+
+// HostPolicy restricts which Host values a server answers, so a request
+// that reached it through DNS rebinding or carries an injected Host is
+// refused instead of being served as if it were legitimate.
+//
+// Patterns are host names or IP literals, matched case-insensitively:
+// "example.com" matches exactly, "*.example.com" matches any subdomain
+// but not example.com itself. Deny takes precedence over Allow. An empty
+// Allow admits every host that is not denied.
+type HostPolicy struct {
+	Allow []string
+	Deny  []string
+
+	// MatchSNI also rejects requests on TLS connections whose Host
+	// differs from the SNI server name the connection was established
+	// for, as read from the request's ConnInfo. The certificate
+	// presented was chosen for that name, so answering another host on
+	// the same connection would bypass per-host certificate selection.
+	// Connections without SNI, e.g. to an IP address, are not checked.
+	MatchSNI bool
+}
+
+// Allowed reports whether host, with or without a port, passes p.
+func (p *HostPolicy) Allowed(host string) bool {
+	host = normalizeHost(host)
+	for _, pat := range p.Deny {
+		if matchHostPattern(pat, host) {
+			return false
+		}
+	}
+	if len(p.Allow) == 0 {
+		return true
+	}
+	if host == "" {
+		return false
+	}
+	for _, pat := range p.Allow {
+		if matchHostPattern(pat, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// Check applies p to the host r was sent to: the authority of an
+// absolute-form target, else the Host header.
+func (p *HostPolicy) Check(r *Request) error {
+	host := r.Host
+	if host == "" {
+		host = r.Header.Get("Host")
+	}
+	if !p.Allowed(host) {
+		return ErrHostNotAllowed
+	}
+	if p.MatchSNI {
+		if info := ConnInfoFromContext(r.Context()); info != nil && info.TLS != nil && info.TLS.ServerName != "" &&
+			normalizeHost(info.TLS.ServerName) != normalizeHost(host) {
+			return ErrHostSNIMismatch
+		}
+	}
+	return nil
+}
+
+// WriteMisdirected writes a 421 Misdirected Request, telling the client
+// this server is not authoritative for the requested host. The
+// connection may stay open; a client can retry elsewhere.
+func WriteMisdirected(ctx context.Context, w io.Writer) error {
+	return WriteText(ctx, w, StatusMisdirectedRequest, StatusText(StatusMisdirectedRequest)+"\n")
+}
+
+// normalizeHost lowercases host and strips a port and a trailing dot.
+func normalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	} else {
+		host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	}
+	return strings.TrimSuffix(host, ".")
+}
+
+func matchHostPattern(pat, host string) bool {
+	pat = normalizeHost(pat)
+	if suffix, ok := strings.CutPrefix(pat, "*."); ok {
+		return len(host) > len(suffix)+1 && strings.HasSuffix(host, "."+suffix)
+	}
+	return pat != "" && host == pat
+}
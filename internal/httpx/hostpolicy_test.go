@@ -0,0 +1,87 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestHostPolicyAllowed(t *testing.T) {
+	p := &HostPolicy{
+		Allow: []string{"example.com", "*.api.example.com", "[::1]"},
+		Deny:  []string{"internal.api.example.com"},
+	}
+	for host, want := range map[string]bool{
+		"example.com":              true,
+		"EXAMPLE.com:8080":         true,
+		"example.com.":             true,
+		"www.example.com":          false,
+		"v1.api.example.com":       true,
+		"a.b.api.example.com":      true,
+		"api.example.com":          false,
+		"xapi.example.com":         false,
+		"internal.api.example.com": false,
+		"[::1]:443":                true,
+		"":                         false,
+		"evil.com":                 false,
+	} {
+		if got := p.Allowed(host); got != want {
+			t.Errorf("Allowed(%q) = %v, want %v", host, got, want)
+		}
+	}
+
+	deny := &HostPolicy{Deny: []string{"*.local"}}
+	if !deny.Allowed("example.com") || deny.Allowed("printer.local") {
+		t.Fatal("deny-only policy")
+	}
+}
+
+func TestHostPolicyCheck(t *testing.T) {
+	p := &HostPolicy{Allow: []string{"example.com"}}
+	r := &Request{Header: Header{"Host": {"rebind.attacker.test"}}}
+	if err := p.Check(r); !errors.Is(err, ErrHostNotAllowed) {
+		t.Fatalf("got %v", err)
+	}
+	// The absolute-form authority wins over the header.
+	r.Host = "example.com"
+	if err := p.Check(r); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMisdirected(context.Background(), &buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "HTTP/1.1 421 Misdirected Request\r\n") {
+		t.Fatalf("got %q", buf.String())
+	}
+}
+
+func TestHostPolicyMatchSNI(t *testing.T) {
+	p := &HostPolicy{MatchSNI: true}
+	newReq := func(host, sni string) *Request {
+		r := &Request{Header: Header{"Host": {host}}}
+		return r.WithContext(WithConnInfo(context.Background(), &ConnInfo{TLS: &tls.ConnectionState{ServerName: sni}}))
+	}
+	if err := p.Check(newReq("a.example.com:443", "A.example.com")); err != nil {
+		t.Fatal(err)
+	}
+	err := p.Check(newReq("b.example.com", "a.example.com"))
+	if !errors.Is(err, ErrHostSNIMismatch) || !errors.Is(err, ErrHostNotAllowed) {
+		t.Fatalf("got %v", err)
+	}
+	// No SNI, or plain text: nothing to compare against.
+	if err := p.Check(newReq("b.example.com", "")); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Check(&Request{Header: Header{"Host": {"b.example.com"}}}); err != nil {
+		t.Fatal(err)
+	}
+	p.MatchSNI = false
+	if err := p.Check(newReq("b.example.com", "a.example.com")); err != nil {
+		t.Fatal(err)
+	}
+}
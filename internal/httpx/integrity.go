@@ -0,0 +1,241 @@
+package httpx
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+)
+
+// Body integrity errors. Both map to 400 Bad Request.
+var (
+	ErrBodyDigestMismatch = errors.New("httpx: body does not match its digest")
+	ErrInvalidDigestField = errors.New("httpx: malformed digest field")
+)
+
+// ErrTrailerNeedsChunked is returned by AddDigestTrailer for responses that
+// cannot be sent chunked, and so cannot carry trailers.
+var ErrTrailerNeedsChunked = errors.New("httpx: trailers require chunked framing")
+
+// digestAlgs are the Repr-Digest algorithms (RFC 9530) understood here,
+// strongest first.
+var digestAlgs = []struct {
+	name string
+	new  func() hash.Hash
+}{
+	{"sha-512", sha512.New},
+	{"sha-256", sha256.New},
+}
+
+// ReprDigest returns a Repr-Digest field value (RFC 9530) for data using
+// SHA-256, e.g. "sha-256=:X48E9q...=:".
+func ReprDigest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha-256=:" + base64.StdEncoding.EncodeToString(sum[:]) + ":"
+}
+
+// parseReprDigest parses a Repr-Digest dictionary into algorithm -> digest,
+// keeping only algorithms in digestAlgs.
+func parseReprDigest(v string) (map[string][]byte, error) {
+	out := make(map[string][]byte)
+	for _, member := range strings.Split(v, ",") {
+		member = strings.TrimSpace(member)
+		if member == "" {
+			continue
+		}
+		name, val, ok := strings.Cut(member, "=")
+		if !ok {
+			return nil, ErrInvalidDigestField
+		}
+		val, _, _ = strings.Cut(val, ";") // parameters are not used
+		if len(val) < 2 || val[0] != ':' || val[len(val)-1] != ':' {
+			return nil, ErrInvalidDigestField
+		}
+		sum, err := base64.StdEncoding.DecodeString(val[1 : len(val)-1])
+		if err != nil {
+			return nil, ErrInvalidDigestField
+		}
+		out[strings.ToLower(strings.TrimSpace(name))] = sum
+	}
+	return out, nil
+}
+
+// VerifyBodyDigest makes r's body check itself against a Content-MD5 or
+// Repr-Digest field. Digests arriving as chunked trailers are seen too, as
+// long as the field is announced in a Trailer header. The body is hashed as
+// it streams; the Read that would return io.EOF returns
+// ErrBodyDigestMismatch instead if the content does not match, so the
+// handler sees the failure before acting on the full body. Answer it with
+// 400.
+//
+// Requests without a digest field are left alone, as are those whose
+// Repr-Digest names only unsupported algorithms (e.g. sha-1). A malformed
+// field is reported immediately as ErrInvalidDigestField. Of several
+// Repr-Digest algorithms, the strongest supported one is checked.
+func VerifyBodyDigest(r *Request) error {
+	if r.Body == nil {
+		return nil
+	}
+	declared := trailerDeclared(r.Header, "Repr-Digest") || trailerDeclared(r.Header, "Content-Md5")
+	present := r.Header.Get("Repr-Digest") != "" || r.Header.Get("Content-Md5") != ""
+	if !declared && !present {
+		return nil
+	}
+	dv := &digestVerifier{ReadCloser: r.Body, header: r.Header, hashes: make(map[string]hash.Hash)}
+	if present {
+		alg, want, err := expectedDigest(r.Header)
+		if err != nil {
+			return err
+		}
+		if alg != "" {
+			// The algorithm is known up front: hash with it alone.
+			dv.alg, dv.want = alg, want
+			dv.hashes[alg] = newDigestHash(alg)
+			r.Body = dv
+			return nil
+		}
+		if !declared {
+			return nil // nothing here we can check
+		}
+	}
+	// The digest arrives in a trailer, so any algorithm may turn up.
+	dv.hashes["md5"] = md5.New()
+	for _, a := range digestAlgs {
+		dv.hashes[a.name] = a.new()
+	}
+	r.Body = dv
+	return nil
+}
+
+// newDigestHash returns a hash for alg, one of "md5" or a digestAlgs name.
+func newDigestHash(alg string) hash.Hash {
+	for _, a := range digestAlgs {
+		if a.name == alg {
+			return a.new()
+		}
+	}
+	return md5.New()
+}
+
+// expectedDigest picks the digest to verify from h: the strongest
+// Repr-Digest algorithm across every field line (a trailer adds one to
+// the head's), else Content-MD5. alg is "" if none is usable.
+func expectedDigest(h Header) (alg string, sum []byte, err error) {
+	if v := strings.Join(h.Values("Repr-Digest"), ","); v != "" {
+		sums, err := parseReprDigest(v)
+		if err != nil {
+			return "", nil, err
+		}
+		for _, a := range digestAlgs {
+			if s, ok := sums[a.name]; ok {
+				return a.name, s, nil
+			}
+		}
+	}
+	if v := h.Get("Content-Md5"); v != "" {
+		s, err := base64.StdEncoding.DecodeString(strings.TrimSpace(v))
+		if err != nil || len(s) != md5.Size {
+			return "", nil, ErrInvalidDigestField
+		}
+		return "md5", s, nil
+	}
+	return "", nil, nil
+}
+
+type digestVerifier struct {
+	io.ReadCloser
+	header Header
+	alg    string // algorithm chosen from the head; "" if only a trailer will tell
+	want   []byte // digest from the head, when alg is set
+	hashes map[string]hash.Hash
+	err    error
+}
+
+func (d *digestVerifier) Read(p []byte) (int, error) {
+	if d.err != nil {
+		return 0, d.err
+	}
+	n, err := d.ReadCloser.Read(p)
+	if n > 0 {
+		for _, h := range d.hashes {
+			h.Write(p[:n])
+		}
+	}
+	if err == io.EOF {
+		// Trailers have been merged into the header by now.
+		d.err = d.check()
+		if d.err != nil {
+			return n, d.err
+		}
+		d.err = io.EOF
+	}
+	return n, err
+}
+
+func (d *digestVerifier) check() error {
+	alg, want := d.alg, d.want
+	if alg == "" {
+		var err error
+		alg, want, err = expectedDigest(d.header)
+		switch {
+		case err != nil:
+			return err
+		case alg == "" && d.header.Get("Repr-Digest") != "":
+			return nil // only unsupported algorithms
+		case alg == "":
+			return fmt.Errorf("%w: announced digest trailer missing", ErrBodyDigestMismatch)
+		}
+	}
+	if !bytes.Equal(d.hashes[alg].Sum(nil), want) {
+		return ErrBodyDigestMismatch
+	}
+	return nil
+}
+
+// AddDigestTrailer arranges for resp to end with a Repr-Digest trailer
+// (SHA-256) computed while its body streams out, so a body of unknown
+// length need not be buffered to be digested. The response is switched to
+// chunked framing; it fails with ErrTrailerNeedsChunked if it already
+// declares a Content-Length or the peer speaks HTTP/1.0. For bodies
+// already in memory, set Repr-Digest to ReprDigest(body) instead.
+func AddDigestTrailer(resp *Response) error {
+	if resp.Header == nil {
+		resp.Header = Header{}
+	}
+	if resp.Header.Get("Content-Length") != "" || !resp.chunkedAllowed() {
+		return ErrTrailerNeedsChunked
+	}
+	resp.Header.Set("Transfer-Encoding", "chunked")
+	resp.Header.Add("Trailer", "Repr-Digest")
+	if resp.Trailer == nil {
+		resp.Trailer = Header{}
+	}
+	if resp.Body == nil {
+		resp.Body = bytes.NewReader(nil)
+	}
+	resp.Body = &digestTrailerReader{r: resp.Body, h: sha256.New(), trailer: resp.Trailer}
+	return nil
+}
+
+// digestTrailerReader hashes a response body and records the digest in
+// the trailer at EOF.
+type digestTrailerReader struct {
+	r       io.Reader
+	h       hash.Hash
+	trailer Header
+}
+
+func (d *digestTrailerReader) Read(p []byte) (int, error) {
+	n, err := d.r.Read(p)
+	d.h.Write(p[:n])
+	if err == io.EOF {
+		d.trailer.Set("Repr-Digest", "sha-256=:"+base64.StdEncoding.EncodeToString(d.h.Sum(nil))+":")
+	}
+	return n, err
+}
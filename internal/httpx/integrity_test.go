@@ -0,0 +1,163 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/andycostintoma/httpx/internal/netx"
+)
+
+func TestReprDigest(t *testing.T) {
+	if got := ReprDigest([]byte("hello")); got != "sha-256=:LPJNul+wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ=:" {
+		t.Fatal(got)
+	}
+}
+
+func digestRequestWith(body string, h map[string]string) *Request {
+	r := &Request{Header: Header{}, Body: io.NopCloser(strings.NewReader(body))}
+	for k, v := range h {
+		r.Header.Set(k, v)
+	}
+	return r
+}
+
+func TestVerifyBodyDigest(t *testing.T) {
+	sum := md5.Sum([]byte("hello"))
+	good := []map[string]string{
+		{"Repr-Digest": ReprDigest([]byte("hello"))},
+		{"Content-MD5": base64.StdEncoding.EncodeToString(sum[:])},
+		// Unknown algorithms are skipped in favour of a supported one.
+		{"Repr-Digest": "blake9=:AAAA:, " + ReprDigest([]byte("hello"))},
+	}
+	for _, h := range good {
+		r := digestRequestWith("hello", h)
+		if err := VerifyBodyDigest(r); err != nil {
+			t.Fatal(err)
+		}
+		if b, err := io.ReadAll(r.Body); err != nil || string(b) != "hello" {
+			t.Fatalf("%v: %q %v", h, b, err)
+		}
+	}
+
+	r := digestRequestWith("hellO", map[string]string{"Repr-Digest": ReprDigest([]byte("hello"))})
+	VerifyBodyDigest(r)
+	if _, err := io.ReadAll(r.Body); !errors.Is(err, ErrBodyDigestMismatch) {
+		t.Fatalf("got %v", err)
+	}
+	if _, err := r.Body.Read(make([]byte, 1)); !errors.Is(err, ErrBodyDigestMismatch) {
+		t.Fatal("mismatch must be sticky")
+	}
+
+	for _, bad := range []string{"sha-256=abc", "sha-256=:!!:", "nonsense"} {
+		r := digestRequestWith("x", map[string]string{"Repr-Digest": bad})
+		if err := VerifyBodyDigest(r); !errors.Is(err, ErrInvalidDigestField) {
+			t.Fatalf("%q: got %v", bad, err)
+		}
+	}
+
+	// No digest: the body is left untouched.
+	r = digestRequestWith("x", nil)
+	orig := r.Body
+	if err := VerifyBodyDigest(r); err != nil || r.Body != orig {
+		t.Fatal("request without digest was wrapped")
+	}
+
+	// Only unsupported algorithms: nothing to check, so nothing fails.
+	r = digestRequestWith("x", map[string]string{"Repr-Digest": "sha-1=:AAAA:"})
+	orig = r.Body
+	if err := VerifyBodyDigest(r); err != nil || r.Body != orig {
+		t.Fatalf("unsupported-only digest: %v", err)
+	}
+
+	// A digest in the head is checked with its algorithm alone.
+	r = digestRequestWith("hello", map[string]string{"Repr-Digest": ReprDigest([]byte("hello"))})
+	VerifyBodyDigest(r)
+	if dv := r.Body.(*digestVerifier); len(dv.hashes) != 1 || dv.hashes["sha-256"] == nil {
+		t.Fatalf("hashes: %v", dv.hashes)
+	}
+}
+
+func TestVerifyBodyDigestTrailer(t *testing.T) {
+	for _, c := range []struct {
+		trailer string
+		want    error
+	}{
+		{"Repr-Digest: " + ReprDigest([]byte("hello")) + "\r\n", nil},
+		{"Repr-Digest: " + ReprDigest([]byte("other")) + "\r\n", ErrBodyDigestMismatch},
+		{"", ErrBodyDigestMismatch},
+		{"Repr-Digest: sha-1=:AAAA:\r\n", nil},
+	} {
+		raw := "POST / HTTP/1.1\r\nTransfer-Encoding: chunked\r\nTrailer: Repr-Digest\r\n\r\n5\r\nhello\r\n0\r\n" + c.trailer + "\r\n"
+		rd := netx.NewCRLFFastReader(strings.NewReader(raw))
+		req, err := ParseRequest(rd, ParseLimits{MaxLineBytes: 1024})
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Body, _, _ = NewBodyReader(context.Background(), req, rd, 0)
+		if err := VerifyBodyDigest(req); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.ReadAll(req.Body); !errors.Is(err, c.want) {
+			t.Fatalf("trailer %q: got %v, want %v", c.trailer, err, c.want)
+		}
+	}
+}
+
+func TestVerifyBodyDigestHeadAndTrailer(t *testing.T) {
+	// The head names only an unsupported algorithm; the announced trailer
+	// brings a supported one, which must still be checked.
+	for _, c := range []struct {
+		body string
+		want error
+	}{
+		{"hello", nil},
+		{"hellO", ErrBodyDigestMismatch},
+	} {
+		raw := "POST / HTTP/1.1\r\nTransfer-Encoding: chunked\r\nRepr-Digest: sha-1=:AAAA:\r\nTrailer: Repr-Digest\r\n\r\n" +
+			"5\r\n" + c.body + "\r\n0\r\nRepr-Digest: " + ReprDigest([]byte("hello")) + "\r\n\r\n"
+		rd := netx.NewCRLFFastReader(strings.NewReader(raw))
+		req, err := ParseRequest(rd, ParseLimits{MaxLineBytes: 1024})
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Body, _, _ = NewBodyReader(context.Background(), req, rd, 0)
+		if err := VerifyBodyDigest(req); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.ReadAll(req.Body); !errors.Is(err, c.want) {
+			t.Fatalf("body %q: got %v, want %v", c.body, err, c.want)
+		}
+	}
+}
+
+func TestAddDigestTrailer(t *testing.T) {
+	resp := &Response{StatusCode: StatusOK, Header: Header{}, Body: strings.NewReader("hello")}
+	if err := AddDigestTrailer(resp); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := WriteResponse(context.Background(), &buf, resp); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	want := "5\r\nhello\r\n0\r\nRepr-Digest: " + ReprDigest([]byte("hello")) + "\r\n\r\n"
+	if !strings.Contains(out, "Transfer-Encoding: chunked\r\n") || !strings.Contains(out, "Trailer: Repr-Digest\r\n") ||
+		!strings.HasSuffix(out, want) {
+		t.Fatalf("got %q", out)
+	}
+
+	fixed := &Response{StatusCode: StatusOK, Header: Header{"Content-Length": {"5"}}}
+	if err := AddDigestTrailer(fixed); !errors.Is(err, ErrTrailerNeedsChunked) {
+		t.Fatalf("got %v", err)
+	}
+	old := &Response{StatusCode: StatusOK, Proto: "HTTP/1.0"}
+	if err := AddDigestTrailer(old); !errors.Is(err, ErrTrailerNeedsChunked) {
+		t.Fatalf("got %v", err)
+	}
+}
@@ -0,0 +1,143 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Sentinel errors for DecodeJSON.
+var (
+	ErrUnsupportedMediaType = errors.New("httpx: unsupported media type")
+	ErrEmptyBody            = errors.New("httpx: empty request body")
+	ErrMalformedJSON        = errors.New("httpx: malformed json")
+)
+
+// DefaultMaxJSONBytes caps request bodies decoded by DecodeJSON when no
+// explicit limit is configured.
+const DefaultMaxJSONBytes = 1 << 20
+
+// DecodeJSONOptions tunes DecodeJSON.
+type DecodeJSONOptions struct {
+	MaxBytes           int64 // body cap; DefaultMaxJSONBytes if zero, unlimited if negative
+	AllowUnknownFields bool  // accept object keys with no matching struct field
+	AllowAnyMediaType  bool  // skip the Content-Type check
+}
+
+// DecodeError is returned by DecodeJSON. StatusCode is the response status
+// the failure maps to: 400, 413 or 415.
+type DecodeError struct {
+	StatusCode int
+	Err        error
+}
+
+func (e *DecodeError) Error() string { return e.Err.Error() }
+func (e *DecodeError) Unwrap() error { return e.Err }
+
+// DecodeJSON decodes the JSON body of r into v. It requires an
+// application/json (or +json) Content-Type, enforces a size cap, rejects
+// unknown fields and trailing data unless configured otherwise, and returns
+// a *DecodeError carrying the status code to answer with.
+func DecodeJSON(r *Request, v any, opts DecodeJSONOptions) error {
+	if !opts.AllowAnyMediaType && !isJSONMediaType(r.Header.Get("Content-Type")) {
+		return &DecodeError{415, fmt.Errorf("%w: %q", ErrUnsupportedMediaType, r.Header.Get("Content-Type"))}
+	}
+	if r.Body == nil {
+		return &DecodeError{400, ErrEmptyBody}
+	}
+
+	max := opts.MaxBytes
+	if max == 0 {
+		max = DefaultMaxJSONBytes
+	}
+	var body io.Reader = r.Body
+	var lr *io.LimitedReader
+	if max > 0 {
+		// One extra byte distinguishes "exactly max" from "too large".
+		lr = &io.LimitedReader{R: r.Body, N: max + 1}
+		body = lr
+	}
+
+	dec := json.NewDecoder(body)
+	if !opts.AllowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+	err := dec.Decode(v)
+	if lr != nil && lr.N <= 0 {
+		return &DecodeError{413, ErrBodyTooLarge}
+	}
+	switch {
+	case err == io.EOF:
+		return &DecodeError{400, ErrEmptyBody}
+	case errors.Is(err, ErrBodyTooLarge):
+		return &DecodeError{413, err}
+	case err != nil:
+		return &DecodeError{400, fmt.Errorf("%w: %v", ErrMalformedJSON, err)}
+	}
+
+	// Exactly one JSON value is allowed.
+	if _, err := dec.Token(); err != io.EOF {
+		if lr != nil && lr.N <= 0 {
+			return &DecodeError{413, ErrBodyTooLarge}
+		}
+		return &DecodeError{400, fmt.Errorf("%w: trailing data after value", ErrMalformedJSON)}
+	}
+	return nil
+}
+
+// isJSONMediaType reports whether ct is application/json or a +json type,
+// ignoring parameters and case.
+func isJSONMediaType(ct string) bool {
+	mt, _, _ := strings.Cut(ct, ";")
+	mt = strings.ToLower(strings.TrimSpace(mt))
+	return mt == "application/json" || (strings.HasPrefix(mt, "application/") && strings.HasSuffix(mt, "+json"))
+}
+
+// DefaultJSONChunkThreshold is the encoded size above which EncodeJSON
+// switches from Content-Length to chunked framing.
+const DefaultJSONChunkThreshold = 64 << 10
+
+// EncodeJSONOptions tunes EncodeJSON.
+type EncodeJSONOptions struct {
+	ChunkThreshold int    // DefaultJSONChunkThreshold if zero
+	Indent         string // pretty-print with this indent when non-empty
+}
+
+// EncodeJSON writes v as an application/json response. Payloads up to the
+// chunk threshold are sent with Content-Length; larger ones use chunked
+// transfer coding. The value is encoded in full before anything is
+// written, so an encoding error never leaves a partial response.
+func EncodeJSON(ctx context.Context, w io.Writer, code int, v any, opts EncodeJSONOptions) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if opts.Indent != "" {
+		enc.SetIndent("", opts.Indent)
+	}
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("httpx: encode json: %w", err)
+	}
+
+	threshold := opts.ChunkThreshold
+	if threshold <= 0 {
+		threshold = DefaultJSONChunkThreshold
+	}
+
+	h := Header{}
+	h.Set("Content-Type", "application/json")
+	if buf.Len() <= threshold {
+		h.Set("Content-Length", strconv.Itoa(buf.Len()))
+	} else {
+		h.Set("Transfer-Encoding", "chunked")
+	}
+	return WriteResponse(ctx, w, &Response{
+		StatusCode: code,
+		Header:     h,
+		Body:       &buf,
+	})
+}
@@ -0,0 +1,102 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+type jsonPayload struct {
+	Name string `json:"name"`
+}
+
+func jsonRequest(ct, body string) *Request {
+	r := &Request{Header: Header{}, Body: io.NopCloser(strings.NewReader(body))}
+	if ct != "" {
+		r.Header.Set("Content-Type", ct)
+	}
+	return r
+}
+
+func TestDecodeJSON(t *testing.T) {
+	var p jsonPayload
+	r := jsonRequest("application/json; charset=utf-8", `{"name":"x"}`)
+	if err := DecodeJSON(r, &p, DecodeJSONOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "x" {
+		t.Fatalf("decoded %+v", p)
+	}
+
+	r = jsonRequest("application/problem+json", `{"name":"y"}`)
+	if err := DecodeJSON(r, &p, DecodeJSONOptions{}); err != nil {
+		t.Fatalf("+json should be accepted: %v", err)
+	}
+}
+
+func TestDecodeJSONErrors(t *testing.T) {
+	cases := []struct {
+		name   string
+		r      *Request
+		opts   DecodeJSONOptions
+		status int
+		want   error
+	}{
+		{"media type", jsonRequest("text/plain", `{}`), DecodeJSONOptions{}, 415, ErrUnsupportedMediaType},
+		{"missing type", jsonRequest("", `{}`), DecodeJSONOptions{}, 415, ErrUnsupportedMediaType},
+		{"empty", jsonRequest("application/json", ``), DecodeJSONOptions{}, 400, ErrEmptyBody},
+		{"syntax", jsonRequest("application/json", `{"name":`), DecodeJSONOptions{}, 400, ErrMalformedJSON},
+		{"unknown field", jsonRequest("application/json", `{"nam":"x"}`), DecodeJSONOptions{}, 400, ErrMalformedJSON},
+		{"trailing", jsonRequest("application/json", `{"name":"x"} {}`), DecodeJSONOptions{}, 400, ErrMalformedJSON},
+		{"too large", jsonRequest("application/json", `{"name":"`+strings.Repeat("a", 100)+`"}`), DecodeJSONOptions{MaxBytes: 50}, 413, ErrBodyTooLarge},
+	}
+	for _, c := range cases {
+		var p jsonPayload
+		err := DecodeJSON(c.r, &p, c.opts)
+		var de *DecodeError
+		if !errors.As(err, &de) || de.StatusCode != c.status || !errors.Is(err, c.want) {
+			t.Fatalf("%s: got %v (%+v), want status %d and %v", c.name, err, de, c.status, c.want)
+		}
+	}
+
+	// Unknown fields may be allowed explicitly.
+	var p jsonPayload
+	r := jsonRequest("application/json", `{"nam":"x"}`)
+	if err := DecodeJSON(r, &p, DecodeJSONOptions{AllowUnknownFields: true}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEncodeJSONFraming(t *testing.T) {
+	var buf bytes.Buffer
+	if err := EncodeJSON(context.Background(), &buf, 200, []int{1, 2}, EncodeJSONOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "Content-Length: 6\r\n") || !strings.HasSuffix(buf.String(), "[1,2]\n") {
+		t.Fatalf("small payload: %q", buf.String())
+	}
+
+	buf.Reset()
+	big := strings.Repeat("z", 100)
+	if err := EncodeJSON(context.Background(), &buf, 200, big, EncodeJSONOptions{ChunkThreshold: 32}); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	if !strings.Contains(got, "Transfer-Encoding: chunked\r\n") || strings.Contains(got, "Content-Length") {
+		t.Fatalf("large payload should be chunked: %q", got)
+	}
+	if !strings.HasSuffix(got, "0\r\n\r\n") {
+		t.Fatalf("missing last chunk: %q", got)
+	}
+}
+
+func TestEncodeJSONError(t *testing.T) {
+	var buf bytes.Buffer
+	err := EncodeJSON(context.Background(), &buf, 200, make(chan int), EncodeJSONOptions{})
+	if err == nil || !strings.Contains(err.Error(), "encode json") || buf.Len() != 0 {
+		t.Fatalf("got %v, wrote %q", err, buf.String())
+	}
+}
@@ -0,0 +1,85 @@
+package httpx
+
+import (
+	"errors"
+	"math"
+)
+
+// ErrURITooLong indicates a request-target longer than
+// ParseLimits.MaxURIBytes. Answer with 414 URI Too Long.
+var ErrURITooLong = errors.New("httpx: request-target too long")
+
+// Defaults applied by ServerLimits for fields left at zero.
+const (
+	DefaultMaxURIBytes         = 8 << 10
+	DefaultMaxRequestLineBytes = 16 << 10
+	DefaultMaxHeaderBytes      = 64 << 10
+	DefaultMaxHeaderFields     = 100
+	DefaultMaxHeaderValueBytes = 16 << 10
+	DefaultMaxBodyBytes        = 10 << 20
+	DefaultMaxChunkExtBytes    = 256
+	DefaultMaxTrailerBytes     = 8 << 10
+)
+
+// ServerLimits gathers every size limit applied to incoming requests in one
+// place. Unlike ParseLimits and HeaderLimits, its zero value is safe: a
+// field left at zero takes the matching Default* constant. A negative
+// field removes that limit.
+type ServerLimits struct {
+	MaxURIBytes         int   // request-target length
+	MaxRequestLineBytes int   // whole request line
+	MaxHeaderBytes      int   // header section, all lines together
+	MaxHeaderFields     int   // distinct field names
+	MaxHeaderValueBytes int   // a single field value
+	MaxBodyBytes        int64 // request content
+	MaxChunkExtBytes    int   // extensions on one chunk-size line
+	MaxTrailerBytes     int   // trailer section of a chunked body
+}
+
+// ParseLimits returns the limits for ParseRequest and NewBodyReader's
+// chunked framing.
+func (l ServerLimits) ParseLimits() ParseLimits {
+	lim := ParseLimits{
+		MaxLineBytes:     limitOr(l.MaxRequestLineBytes, DefaultMaxRequestLineBytes),
+		MaxHeaderBytes:   limitOr(l.MaxHeaderBytes, DefaultMaxHeaderBytes),
+		MaxURIBytes:      limitOr(l.MaxURIBytes, DefaultMaxURIBytes),
+		MaxChunkExtBytes: limitOr(l.MaxChunkExtBytes, DefaultMaxChunkExtBytes),
+		MaxTrailerBytes:  limitOr(l.MaxTrailerBytes, DefaultMaxTrailerBytes),
+	}
+	if lim.MaxLineBytes == 0 {
+		// ReadLine needs a positive bound.
+		lim.MaxLineBytes = math.MaxInt32
+	}
+	return lim
+}
+
+// HeaderLimits returns the limits for ValidateHeader.
+func (l ServerLimits) HeaderLimits() HeaderLimits {
+	return HeaderLimits{
+		MaxFields:     limitOr(l.MaxHeaderFields, DefaultMaxHeaderFields),
+		MaxValueBytes: limitOr(l.MaxHeaderValueBytes, DefaultMaxHeaderValueBytes),
+	}
+}
+
+// BodyBytes returns the body cap for NewBodyReader; zero means unlimited.
+func (l ServerLimits) BodyBytes() int64 {
+	switch {
+	case l.MaxBodyBytes < 0:
+		return 0
+	case l.MaxBodyBytes == 0:
+		return DefaultMaxBodyBytes
+	}
+	return l.MaxBodyBytes
+}
+
+// limitOr maps a ServerLimits field to the zero-means-unlimited convention
+// of the lower-level limit structs.
+func limitOr(v, def int) int {
+	switch {
+	case v < 0:
+		return 0
+	case v == 0:
+		return def
+	}
+	return v
+}
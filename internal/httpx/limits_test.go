@@ -0,0 +1,47 @@
+package httpx
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/andycostintoma/httpx/internal/netx"
+)
+
+func TestServerLimitsDefaults(t *testing.T) {
+	var l ServerLimits
+	pl := l.ParseLimits()
+	if pl.MaxLineBytes != DefaultMaxRequestLineBytes || pl.MaxHeaderBytes != DefaultMaxHeaderBytes ||
+		pl.MaxURIBytes != DefaultMaxURIBytes || pl.MaxChunkExtBytes != DefaultMaxChunkExtBytes ||
+		pl.MaxTrailerBytes != DefaultMaxTrailerBytes {
+		t.Fatalf("parse limits %+v", pl)
+	}
+	hl := l.HeaderLimits()
+	if hl.MaxFields != DefaultMaxHeaderFields || hl.MaxValueBytes != DefaultMaxHeaderValueBytes {
+		t.Fatalf("header limits %+v", hl)
+	}
+	if l.BodyBytes() != DefaultMaxBodyBytes {
+		t.Fatal(l.BodyBytes())
+	}
+}
+
+func TestServerLimitsOverrides(t *testing.T) {
+	l := ServerLimits{MaxURIBytes: 10, MaxRequestLineBytes: -1, MaxHeaderFields: -1, MaxBodyBytes: -1}
+	pl := l.ParseLimits()
+	if pl.MaxURIBytes != 10 || pl.MaxLineBytes <= DefaultMaxRequestLineBytes {
+		t.Fatalf("parse limits %+v", pl)
+	}
+	if l.HeaderLimits().MaxFields != 0 || l.BodyBytes() != 0 {
+		t.Fatal("negative fields should lift the limit")
+	}
+
+	raw := "GET /" + strings.Repeat("a", 10) + " HTTP/1.1\r\n\r\n"
+	_, err := ParseRequest(netx.NewCRLFFastReader(strings.NewReader(raw)), pl)
+	if !errors.Is(err, ErrURITooLong) {
+		t.Fatalf("got %v", err)
+	}
+	raw = "GET /" + strings.Repeat("a", 9) + " HTTP/1.1\r\n\r\n"
+	if _, err := ParseRequest(netx.NewCRLFFastReader(strings.NewReader(raw)), pl); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,109 @@
+package httpx
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Common HTTP methods (RFC 9110 §9 and RFC 5789).
+const (
+	MethodGet     = "GET"
+	MethodHead    = "HEAD"
+	MethodPost    = "POST"
+	MethodPut     = "PUT"
+	MethodPatch   = "PATCH"
+	MethodDelete  = "DELETE"
+	MethodConnect = "CONNECT"
+	MethodOptions = "OPTIONS"
+	MethodTrace   = "TRACE"
+)
+
+// ErrInvalidMethod indicates a method that is not a valid token.
+var ErrInvalidMethod = errors.New("httpx: invalid method")
+
+// ErrMethodNotImplemented is returned by ParseRequest when the request
+// method is a valid token but not in the configured MethodRegistry. Servers
+// should answer 501 Not Implemented (RFC 9110 §9.1).
+var ErrMethodNotImplemented = errors.New("httpx: method not implemented")
+
+// MethodRegistry is the set of request methods a server accepts. Methods are
+// case-sensitive. It is safe for concurrent use.
+type MethodRegistry struct {
+	mu      sync.RWMutex
+	methods map[string]struct{}
+}
+
+// NewMethodRegistry returns a registry holding the standard methods listed
+// above except TRACE, which is opt-in (see WriteTrace). Extension methods,
+// such as WebDAV's PROPFIND or MKCOL, can be added with Register.
+func NewMethodRegistry() *MethodRegistry {
+	r := &MethodRegistry{methods: make(map[string]struct{})}
+	for _, m := range []string{
+		MethodGet, MethodHead, MethodPost, MethodPut, MethodPatch,
+		MethodDelete, MethodConnect, MethodOptions,
+	} {
+		r.methods[m] = struct{}{}
+	}
+	return r
+}
+
+// Register adds methods to the registry. Nothing is added if any of them is
+// not a valid token.
+func (r *MethodRegistry) Register(methods ...string) error {
+	for _, m := range methods {
+		if !isValidMethod(m) {
+			return fmt.Errorf("%w: %q", ErrInvalidMethod, m)
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, m := range methods {
+		r.methods[m] = struct{}{}
+	}
+	return nil
+}
+
+// Remove drops methods from the registry, e.g. TRACE or CONNECT on servers
+// that never want to see them.
+func (r *MethodRegistry) Remove(methods ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, m := range methods {
+		delete(r.methods, m)
+	}
+}
+
+// Allowed reports whether method is registered.
+func (r *MethodRegistry) Allowed(method string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.methods[method]
+	return ok
+}
+
+// Methods returns the registered methods in sorted order.
+func (r *MethodRegistry) Methods() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]string, 0, len(r.methods))
+	for m := range r.methods {
+		out = append(out, m)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// isValidMethod reports whether m is a non-empty token (RFC 9110 §9.1).
+func isValidMethod(m string) bool {
+	if m == "" {
+		return false
+	}
+	for i := 0; i < len(m); i++ {
+		if !isTokenByte(m[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,66 @@
+package httpx
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/andycostintoma/httpx/internal/netx"
+)
+
+func TestParseRequestLineExtensionMethods(t *testing.T) {
+	for _, m := range []string{"PROPFIND", "MKCOL", "VERSION-CONTROL", "get", "M-SEARCH"} {
+		rl, err := parseRequestLine(m + " / HTTP/1.1")
+		if err != nil || rl.Method != m {
+			t.Fatalf("%s: %+v %v", m, rl, err)
+		}
+	}
+	if _, err := parseRequestLine("GE\"T / HTTP/1.1"); !errors.Is(err, ErrInvalidMethod) {
+		t.Fatalf("expected ErrInvalidMethod, got %v", err)
+	}
+}
+
+func TestMethodRegistry(t *testing.T) {
+	reg := NewMethodRegistry()
+	if !reg.Allowed(MethodGet) || reg.Allowed("PROPFIND") || reg.Allowed("get") {
+		t.Fatal("unexpected default registry contents")
+	}
+	if err := reg.Register("PROPFIND", "MKCOL"); err != nil {
+		t.Fatal(err)
+	}
+	if !reg.Allowed("PROPFIND") {
+		t.Fatal("PROPFIND should be registered")
+	}
+	if err := reg.Register("COPY", "BAD METHOD"); !errors.Is(err, ErrInvalidMethod) {
+		t.Fatalf("expected ErrInvalidMethod, got %v", err)
+	}
+	if reg.Allowed("COPY") {
+		t.Fatal("failed Register must not add anything")
+	}
+	if reg.Allowed(MethodTrace) {
+		t.Fatal("TRACE must be opt-in")
+	}
+	reg.Remove(MethodConnect)
+	want := []string{"DELETE", "GET", "HEAD", "MKCOL", "OPTIONS", "PATCH", "POST", "PROPFIND", "PUT"}
+	if got := reg.Methods(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Methods() = %v, want %v", got, want)
+	}
+}
+
+func TestParseRequestMethodRegistry(t *testing.T) {
+	limits := ParseLimits{MaxLineBytes: 4096, Methods: NewMethodRegistry()}
+	rd := netx.NewCRLFFastReader(strings.NewReader("MKCOL /dir HTTP/1.1\r\n\r\n"))
+	if _, err := ParseRequest(rd, limits); !errors.Is(err, ErrMethodNotImplemented) {
+		t.Fatalf("expected ErrMethodNotImplemented, got %v", err)
+	}
+
+	if err := limits.Methods.Register("MKCOL"); err != nil {
+		t.Fatal(err)
+	}
+	rd = netx.NewCRLFFastReader(strings.NewReader("MKCOL /dir HTTP/1.1\r\n\r\n"))
+	req, err := ParseRequest(rd, limits)
+	if err != nil || req.Method != "MKCOL" {
+		t.Fatalf("got %+v, %v", req, err)
+	}
+}
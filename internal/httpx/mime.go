@@ -0,0 +1,97 @@
+package httpx
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+)
+
+// ErrInvalidExtension indicates a malformed extension passed to AddExtensionType.
+var ErrInvalidExtension = errors.New("httpx: extension must begin with a dot")
+
+// builtinTypes is the default extension → MIME table. Text types carry an
+// explicit UTF-8 charset so responses render consistently across clients.
+var builtinTypes = map[string]string{
+	".avif":  "image/avif",
+	".css":   "text/css; charset=utf-8",
+	".csv":   "text/csv; charset=utf-8",
+	".gif":   "image/gif",
+	".gz":    "application/gzip",
+	".htm":   "text/html; charset=utf-8",
+	".html":  "text/html; charset=utf-8",
+	".ico":   "image/x-icon",
+	".jpeg":  "image/jpeg",
+	".jpg":   "image/jpeg",
+	".js":    "text/javascript; charset=utf-8",
+	".json":  "application/json",
+	".md":    "text/markdown; charset=utf-8",
+	".mjs":   "text/javascript; charset=utf-8",
+	".mp3":   "audio/mpeg",
+	".mp4":   "video/mp4",
+	".otf":   "font/otf",
+	".pdf":   "application/pdf",
+	".png":   "image/png",
+	".svg":   "image/svg+xml",
+	".tar":   "application/x-tar",
+	".ttf":   "font/ttf",
+	".txt":   "text/plain; charset=utf-8",
+	".wasm":  "application/wasm",
+	".webm":  "video/webm",
+	".webp":  "image/webp",
+	".woff":  "font/woff",
+	".woff2": "font/woff2",
+	".xml":   "text/xml; charset=utf-8",
+	".zip":   "application/zip",
+}
+
+// mimeRegistry holds the active extension table. Keys are lower-case and
+// include the leading dot.
+var mimeRegistry = struct {
+	sync.RWMutex
+	types map[string]string
+}{types: cloneTypes(builtinTypes)}
+
+func cloneTypes(m map[string]string) map[string]string {
+	c := make(map[string]string, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+// TypeByExtension returns the MIME type registered for ext (e.g. ".html"),
+// or "" if none is known. Lookup is case-insensitive.
+func TypeByExtension(ext string) string {
+	mimeRegistry.RLock()
+	defer mimeRegistry.RUnlock()
+	if t, ok := mimeRegistry.types[ext]; ok {
+		return t
+	}
+	return mimeRegistry.types[strings.ToLower(ext)]
+}
+
+// AddExtensionType registers typ for ext, replacing any existing mapping.
+// The extension must begin with a leading dot, as in ".html".
+func AddExtensionType(ext, typ string) error {
+	if !strings.HasPrefix(ext, ".") || len(ext) < 2 {
+		return fmt.Errorf("%w: %q", ErrInvalidExtension, ext)
+	}
+	if typ == "" {
+		return fmt.Errorf("httpx: empty MIME type for %q", ext)
+	}
+	mimeRegistry.Lock()
+	mimeRegistry.types[strings.ToLower(ext)] = typ
+	mimeRegistry.Unlock()
+	return nil
+}
+
+// ContentTypeFor picks a Content-Type for a named payload: the registered
+// type for name's extension if any, otherwise DetectContentType(data).
+func ContentTypeFor(name string, data []byte) string {
+	if t := TypeByExtension(path.Ext(name)); t != "" {
+		return t
+	}
+	return DetectContentType(data)
+}
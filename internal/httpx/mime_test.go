@@ -0,0 +1,39 @@
+package httpx
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTypeByExtension(t *testing.T) {
+	if got := TypeByExtension(".html"); got != "text/html; charset=utf-8" {
+		t.Fatalf("TypeByExtension(.html) = %q", got)
+	}
+	if got := TypeByExtension(".PNG"); got != "image/png" {
+		t.Fatalf("lookup should be case-insensitive, got %q", got)
+	}
+	if got := TypeByExtension(".nope"); got != "" {
+		t.Fatalf("unknown extension = %q, want empty", got)
+	}
+}
+
+func TestAddExtensionType(t *testing.T) {
+	if err := AddExtensionType(".Foo", "application/x-foo"); err != nil {
+		t.Fatal(err)
+	}
+	if got := TypeByExtension(".foo"); got != "application/x-foo" {
+		t.Fatalf("registered type not found, got %q", got)
+	}
+	if err := AddExtensionType("foo", "x/y"); !errors.Is(err, ErrInvalidExtension) {
+		t.Fatalf("expected ErrInvalidExtension, got %v", err)
+	}
+}
+
+func TestContentTypeFor(t *testing.T) {
+	if got := ContentTypeFor("style.css", nil); got != "text/css; charset=utf-8" {
+		t.Fatalf("by extension: got %q", got)
+	}
+	if got := ContentTypeFor("blob", []byte("%PDF-1.4")); got != "application/pdf" {
+		t.Fatalf("by sniffing: got %q", got)
+	}
+}
@@ -0,0 +1,222 @@
+package httpx
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrInvalidBoundary indicates a multipart boundary that RFC 2046 does
+	// not allow.
+	ErrInvalidBoundary = errors.New("httpx: invalid multipart boundary")
+	// ErrMultipartClosed indicates a write after the multipart body was closed.
+	ErrMultipartClosed = errors.New("httpx: multipart writer closed")
+)
+
+// MultipartWriter streams a multipart body (RFC 7578 form data or any other
+// multipart type) to an underlying writer. Each part is written through as
+// it is produced, so file uploads are never buffered in memory.
+type MultipartWriter struct {
+	w        io.Writer
+	boundary string
+	last     *multipartPart
+	closed   bool
+}
+
+// NewMultipartWriter returns a writer with a random boundary.
+func NewMultipartWriter(w io.Writer) *MultipartWriter {
+	var b [24]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	return &MultipartWriter{w: w, boundary: hex.EncodeToString(b[:])}
+}
+
+// Boundary returns the writer's boundary.
+func (mw *MultipartWriter) Boundary() string { return mw.boundary }
+
+// SetBoundary overrides the random boundary. It must be called before the
+// first part is created.
+func (mw *MultipartWriter) SetBoundary(boundary string) error {
+	if mw.last != nil || mw.closed {
+		return errors.New("httpx: SetBoundary called after write")
+	}
+	if len(boundary) < 1 || len(boundary) > 70 || strings.HasSuffix(boundary, " ") {
+		return ErrInvalidBoundary
+	}
+	for i := 0; i < len(boundary); i++ {
+		c := boundary[i]
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+			strings.IndexByte("'()+_,-./:=? ", c) >= 0 {
+			continue
+		}
+		return ErrInvalidBoundary
+	}
+	mw.boundary = boundary
+	return nil
+}
+
+// FormDataContentType returns the Content-Type for a multipart/form-data
+// body written with this boundary.
+func (mw *MultipartWriter) FormDataContentType() string {
+	b := mw.boundary
+	if strings.ContainsAny(b, `()<>@,;:\"/[]?= `) {
+		b = `"` + b + `"`
+	}
+	return "multipart/form-data; boundary=" + b
+}
+
+// CreatePart starts a new part with the given header and returns a writer
+// for its body. The previous part, if any, ends.
+func (mw *MultipartWriter) CreatePart(h Header) (io.Writer, error) {
+	if mw.closed {
+		return nil, ErrMultipartClosed
+	}
+	if err := ValidateHeader(h, HeaderLimits{}); err != nil {
+		return nil, err
+	}
+	var b strings.Builder
+	if mw.last != nil {
+		b.WriteString("\r\n")
+	}
+	b.WriteString("--")
+	b.WriteString(mw.boundary)
+	b.WriteString("\r\n")
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range h[k] {
+			fmt.Fprintf(&b, "%s: %s\r\n", k, v)
+		}
+	}
+	b.WriteString("\r\n")
+	if mw.last != nil {
+		mw.last.closed = true
+	}
+	if _, err := io.WriteString(mw.w, b.String()); err != nil {
+		return nil, err
+	}
+	mw.last = &multipartPart{mw: mw}
+	return mw.last, nil
+}
+
+// CreateFormFile starts a file part for the form field with the given
+// filename, typed application/octet-stream.
+func (mw *MultipartWriter) CreateFormFile(field, filename string) (io.Writer, error) {
+	h := Header{}
+	h.Set("Content-Disposition", `form-data; name=`+formDataQuote(field)+`; filename=`+formDataQuote(filename))
+	h.Set("Content-Type", "application/octet-stream")
+	return mw.CreatePart(h)
+}
+
+// CreateFormField starts a plain form field part.
+func (mw *MultipartWriter) CreateFormField(field string) (io.Writer, error) {
+	h := Header{}
+	h.Set("Content-Disposition", `form-data; name=`+formDataQuote(field))
+	return mw.CreatePart(h)
+}
+
+// formDataQuote quotes a form-data name or filename. RFC 7578 §4.2 rules
+// out the filename* form, so non-ASCII text is sent as raw UTF-8; '"',
+// CR, LF and other control characters are percent-encoded as browsers do,
+// and '\' is escaped.
+func formDataQuote(v string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(v); i++ {
+		switch c := v[i]; {
+		case c < 0x20 || c == 0x7f || c == '"':
+			fmt.Fprintf(&b, "%%%02X", c)
+		case c == '\\':
+			b.WriteString(`\\`)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// WriteField writes a complete form field.
+func (mw *MultipartWriter) WriteField(field, value string) error {
+	p, err := mw.CreateFormField(field)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(p, value)
+	return err
+}
+
+// Close writes the closing boundary. It does not close the underlying writer.
+func (mw *MultipartWriter) Close() error {
+	if mw.closed {
+		return nil
+	}
+	mw.closed = true
+	if mw.last != nil {
+		mw.last.closed = true
+	}
+	prefix := ""
+	if mw.last != nil {
+		prefix = "\r\n"
+	}
+	_, err := io.WriteString(mw.w, prefix+"--"+mw.boundary+"--\r\n")
+	return err
+}
+
+type multipartPart struct {
+	mw     *MultipartWriter
+	closed bool
+}
+
+func (p *multipartPart) Write(b []byte) (int, error) {
+	if p.closed {
+		return 0, errors.New("httpx: write to finished multipart part")
+	}
+	return p.mw.w.Write(b)
+}
+
+// MultipartBody returns a request body that runs build against a fresh
+// MultipartWriter on demand, together with its Content-Type. build starts
+// on the first Read, and parts are produced as the body is read, so large
+// uploads stream without buffering; the length is unknown, so the body goes
+// out chunked. An error returned by build surfaces from the body's Read.
+// A body that is never read runs nothing.
+func MultipartBody(build func(*MultipartWriter) error) (io.ReadCloser, string) {
+	pr, pw := io.Pipe()
+	mb := &multipartBody{pr: pr, pw: pw, mw: NewMultipartWriter(pw), build: build}
+	return mb, mb.mw.FormDataContentType()
+}
+
+type multipartBody struct {
+	pr    *io.PipeReader
+	pw    *io.PipeWriter
+	mw    *MultipartWriter
+	build func(*MultipartWriter) error
+	once  sync.Once
+}
+
+func (b *multipartBody) Read(p []byte) (int, error) {
+	b.once.Do(func() { go b.run() })
+	return b.pr.Read(p)
+}
+
+// Close stops build, if it is running, at its next write.
+func (b *multipartBody) Close() error { return b.pr.Close() }
+
+func (b *multipartBody) run() {
+	err := b.build(b.mw)
+	if err == nil {
+		err = b.mw.Close()
+	}
+	b.pw.CloseWithError(err)
+}
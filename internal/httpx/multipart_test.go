@@ -0,0 +1,131 @@
+package httpx
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMultipartWriter(t *testing.T) {
+	var buf bytes.Buffer
+	mw := NewMultipartWriter(&buf)
+	if err := mw.WriteField("title", "hello"); err != nil {
+		t.Fatal(err)
+	}
+	f, err := mw.CreateFormFile("upload", "ünï.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.WriteString(f, "file ")
+	io.WriteString(f, "contents")
+	if err := mw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte("late")); err == nil {
+		t.Fatal("write to finished part should fail")
+	}
+	if _, err := mw.CreateFormField("x"); !errors.Is(err, ErrMultipartClosed) {
+		t.Fatalf("got %v", err)
+	}
+
+	// The standard library reader must accept what we wrote.
+	_, params, err := mime.ParseMediaType(mw.FormDataContentType())
+	if err != nil {
+		t.Fatal(err)
+	}
+	mr := multipart.NewReader(&buf, params["boundary"])
+	p, err := mr.NextPart()
+	if err != nil || p.FormName() != "title" {
+		t.Fatalf("first part %v %v", p, err)
+	}
+	if b, _ := io.ReadAll(p); string(b) != "hello" {
+		t.Fatalf("field %q", b)
+	}
+	p, err = mr.NextPart()
+	if err != nil || p.FormName() != "upload" || p.FileName() != "ünï.txt" {
+		t.Fatalf("second part %v %v", p, err)
+	}
+	if b, _ := io.ReadAll(p); string(b) != "file contents" {
+		t.Fatalf("file %q", b)
+	}
+	if _, err := mr.NextPart(); err != io.EOF {
+		t.Fatalf("expected EOF, got %v", err)
+	}
+}
+
+func TestMultipartBoundary(t *testing.T) {
+	mw := NewMultipartWriter(io.Discard)
+	if len(mw.Boundary()) == 0 || mw.Boundary() == NewMultipartWriter(io.Discard).Boundary() {
+		t.Fatal("boundaries should be random")
+	}
+	for _, b := range []string{"", "a b ", strings.Repeat("x", 71), "semi;colon"} {
+		if err := mw.SetBoundary(b); !errors.Is(err, ErrInvalidBoundary) {
+			t.Fatalf("%q: got %v", b, err)
+		}
+	}
+	if err := mw.SetBoundary("a b"); err != nil {
+		t.Fatal(err)
+	}
+	if got := mw.FormDataContentType(); got != `multipart/form-data; boundary="a b"` {
+		t.Fatal(got)
+	}
+	if _, err := mw.CreatePart(Header{"Bad Key": {"v"}}); !errors.Is(err, ErrInvalidFieldName) {
+		t.Fatalf("got %v", err)
+	}
+}
+
+func TestMultipartBody(t *testing.T) {
+	body, ctype := MultipartBody(func(mw *MultipartWriter) error {
+		return mw.WriteField("a", "1")
+	})
+	b, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, params, _ := mime.ParseMediaType(ctype)
+	if !strings.HasSuffix(string(b), "--"+params["boundary"]+"--\r\n") {
+		t.Fatalf("unterminated body %q", b)
+	}
+
+	boom := errors.New("boom")
+	body, _ = MultipartBody(func(*MultipartWriter) error { return boom })
+	if _, err := io.ReadAll(body); !errors.Is(err, boom) {
+		t.Fatalf("got %v", err)
+	}
+}
+
+func TestMultipartBodyStartsOnRead(t *testing.T) {
+	started := make(chan struct{})
+	body, _ := MultipartBody(func(mw *MultipartWriter) error {
+		close(started)
+		return mw.WriteField("a", "1")
+	})
+	select {
+	case <-started:
+		t.Fatal("build ran before the body was read")
+	case <-time.After(20 * time.Millisecond):
+	}
+	if _, err := body.Read(make([]byte, 1)); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	body.Close()
+}
+
+func TestMultipartFormDataFilename(t *testing.T) {
+	var buf bytes.Buffer
+	mw := NewMultipartWriter(&buf)
+	if _, err := mw.CreateFormFile("up", "ünï \"q\".txt"); err != nil {
+		t.Fatal(err)
+	}
+	mw.Close()
+	got := buf.String()
+	if strings.Contains(got, "filename*") || !strings.Contains(got, `Content-Disposition: form-data; name="up"; filename="ünï %22q%22.txt"`+"\r\n") {
+		t.Fatalf("form-data disposition %q", got)
+	}
+}
@@ -0,0 +1,58 @@
+package httpx
+
+import "sync"
+
+var (
+	requestPool = sync.Pool{
+		New: func() any { return &Request{URL: &URL{}, Header: make(Header)} },
+	}
+	responsePool = sync.Pool{
+		New: func() any { return &Response{Header: make(Header)} },
+	}
+)
+
+// AcquireRequest returns an empty Request from the pool, with a reusable URL
+// and Header already attached. Fill it with ParseRequestInto and return it
+// with ReleaseRequest once the exchange is complete.
+func AcquireRequest() *Request {
+	return requestPool.Get().(*Request)
+}
+
+// ReleaseRequest resets req and returns it to the pool. req, its URL and
+// its Header must not be used afterwards; the Body is not closed.
+func ReleaseRequest(req *Request) {
+	if req == nil {
+		return
+	}
+	u, h := req.URL, req.Header
+	if u == nil {
+		u = &URL{}
+	}
+	*u = URL{}
+	if h == nil {
+		h = make(Header)
+	}
+	clear(h)
+	*req = Request{URL: u, Header: h}
+	requestPool.Put(req)
+}
+
+// AcquireResponse returns an empty Response from the pool with a reusable Header.
+func AcquireResponse() *Response {
+	return responsePool.Get().(*Response)
+}
+
+// ReleaseResponse resets resp and returns it to the pool. resp and its
+// Header must not be used afterwards.
+func ReleaseResponse(resp *Response) {
+	if resp == nil {
+		return
+	}
+	h := resp.Header
+	if h == nil {
+		h = make(Header)
+	}
+	clear(h)
+	*resp = Response{Header: h}
+	responsePool.Put(resp)
+}
@@ -0,0 +1,66 @@
+package httpx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andycostintoma/httpx/internal/netx"
+)
+
+func TestAcquireReleaseRequest(t *testing.T) {
+	req := AcquireRequest()
+	rd := netx.NewCRLFFastReader(strings.NewReader("GET http://A.com/x?y HTTP/1.1\r\nX-One: 1\r\n\r\n"))
+	if err := ParseRequestInto(req, rd, ParseLimits{MaxLineBytes: 1024}); err != nil {
+		t.Fatal(err)
+	}
+	if req.Method != "GET" || req.URL.Path != "/x" || req.Host != "a.com" || req.Header.Get("X-One") != "1" {
+		t.Fatalf("parsed wrong: %+v %+v", req, req.URL)
+	}
+	req.RemoteAddr = "1.2.3.4:5"
+	ReleaseRequest(req)
+
+	if req.Method != "" || req.Host != "" || req.RemoteAddr != "" || len(req.Header) != 0 ||
+		*req.URL != (URL{}) {
+		t.Fatalf("request not fully reset: %+v", req)
+	}
+
+	req = AcquireRequest()
+	defer ReleaseRequest(req)
+	rd = netx.NewCRLFFastReader(strings.NewReader("POST / HTTP/1.0\r\n\r\n"))
+	if err := ParseRequestInto(req, rd, ParseLimits{MaxLineBytes: 1024}); err != nil {
+		t.Fatal(err)
+	}
+	if req.Method != "POST" || req.Host != "" || len(req.Header) != 0 || req.URL.RawQuery != "" {
+		t.Fatalf("stale data after reuse: %+v %+v", req, req.URL)
+	}
+}
+
+func TestAcquireReleaseResponse(t *testing.T) {
+	resp := AcquireResponse()
+	resp.StatusCode = 404
+	resp.Header.Set("X-A", "b")
+	resp.Body = strings.NewReader("x")
+	ReleaseResponse(resp)
+	if resp.StatusCode != 0 || resp.Body != nil || len(resp.Header) != 0 || resp.Header == nil {
+		t.Fatalf("response not reset: %+v", resp)
+	}
+}
+
+func BenchmarkParseRequestPooled(b *testing.B) {
+	raw := "GET /index.html?q=1 HTTP/1.1\r\n" +
+		"Host: example.com\r\n" +
+		"Accept: */*\r\n\r\n"
+	src := strings.NewReader(raw)
+	rd := netx.NewCRLFFastReader(src)
+	limits := ParseLimits{MaxLineBytes: 4096, MaxHeaderBytes: 8192}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		src.Reset(raw)
+		rd.Reset(src)
+		req := AcquireRequest()
+		if err := ParseRequestInto(req, rd, limits); err != nil {
+			b.Fatal(err)
+		}
+		ReleaseRequest(req)
+	}
+}
@@ -0,0 +1,87 @@
+package httpx
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Priority holds the RFC 9218 extensible priority parameters.
+type Priority struct {
+	Urgency     int  // 0 (highest) to 7 (lowest); default 3
+	Incremental bool // whether the response can be processed incrementally
+}
+
+// DefaultPriority is the priority assumed when none is signalled.
+var DefaultPriority = Priority{Urgency: 3}
+
+// ParsePriority parses a Priority header value such as "u=1, i".
+//
+// Per RFC 9218 §4, unknown members and members with invalid or
+// out-of-range values are ignored, so parsing never fails: anything not
+// understood leaves the corresponding default in place.
+func ParsePriority(s string) Priority {
+	p := DefaultPriority
+	for s != "" {
+		var member string
+		member, s, _ = strings.Cut(s, ",")
+		member = strings.TrimSpace(member)
+		// Drop member parameters (";param"); RFC 9218 defines none.
+		if i := strings.IndexByte(member, ';'); i >= 0 {
+			member = member[:i]
+		}
+		key, val, hasVal := strings.Cut(member, "=")
+		switch key {
+		case "u":
+			if !hasVal {
+				continue
+			}
+			u, err := strconv.Atoi(val)
+			if err != nil || u < 0 || u > 7 {
+				continue
+			}
+			p.Urgency = u
+		case "i":
+			switch {
+			case !hasVal, val == "?1":
+				p.Incremental = true
+			case val == "?0":
+				p.Incremental = false
+			}
+		}
+	}
+	return p
+}
+
+// String serializes p as a structured-field dictionary, omitting members
+// that hold their default value. It returns "" for DefaultPriority.
+func (p Priority) String() string {
+	var parts []string
+	if p.Urgency != DefaultPriority.Urgency {
+		parts = append(parts, "u="+strconv.Itoa(p.Urgency))
+	}
+	if p.Incremental {
+		parts = append(parts, "i")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Priority returns the request's priority as signalled by its Priority
+// header, or DefaultPriority if absent. Multiple header lines are combined
+// as a single dictionary, later members overriding earlier ones.
+func (r *Request) Priority() Priority {
+	vals := r.Header.Values("Priority")
+	if len(vals) == 0 {
+		return DefaultPriority
+	}
+	return ParsePriority(strings.Join(vals, ","))
+}
+
+// SetPriority sets the request's Priority header, removing it when p is the
+// default so that no redundant signal is sent.
+func (r *Request) SetPriority(p Priority) {
+	if s := p.String(); s != "" {
+		r.Header.Set("Priority", s)
+		return
+	}
+	r.Header.Del("Priority")
+}
@@ -0,0 +1,43 @@
+package httpx
+
+import "testing"
+
+func TestParsePriority(t *testing.T) {
+	cases := map[string]Priority{
+		"":             {Urgency: 3},
+		"u=0":          {Urgency: 0},
+		"u=5, i":       {Urgency: 5, Incremental: true},
+		"i=?0, u=2":    {Urgency: 2},
+		"i=?1":         {Urgency: 3, Incremental: true},
+		"u=9":          {Urgency: 3}, // out of range → ignored
+		"u=x, i=maybe": {Urgency: 3}, // invalid values → ignored
+		"foo=1, u=1;p": {Urgency: 1}, // unknown keys and params → ignored
+	}
+	for in, want := range cases {
+		if got := ParsePriority(in); got != want {
+			t.Fatalf("ParsePriority(%q) = %+v, want %+v", in, got, want)
+		}
+	}
+}
+
+func TestRequestPriority(t *testing.T) {
+	r := &Request{Header: Header{}}
+	if got := r.Priority(); got != DefaultPriority {
+		t.Fatalf("default = %+v", got)
+	}
+
+	r.Header.Add("Priority", "u=1")
+	r.Header.Add("Priority", "i")
+	if got := r.Priority(); got != (Priority{Urgency: 1, Incremental: true}) {
+		t.Fatalf("combined = %+v", got)
+	}
+
+	r.SetPriority(Priority{Urgency: 6})
+	if got := r.Header.Get("Priority"); got != "u=6" {
+		t.Fatalf("SetPriority header = %q", got)
+	}
+	r.SetPriority(DefaultPriority)
+	if _, ok := r.Header["Priority"]; ok {
+		t.Fatal("default priority should remove the header")
+	}
+}
@@ -0,0 +1,248 @@
+package httpx
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"strings"
+	"sync"
+)
+
+// ErrInvalidProxyURL indicates a proxy setting that is not a usable URL.
+var ErrInvalidProxyURL = errors.New("httpx: invalid proxy URL")
+
+// ProxyConfig holds proxy settings in the conventional environment-variable
+// form.
+type ProxyConfig struct {
+	HTTPProxy  string // proxy for http requests
+	HTTPSProxy string // proxy for https requests
+	NoProxy    string // comma-separated hosts, domains, IPs and CIDRs to reach directly
+
+	// CGI reports that the process runs as a CGI script. HTTP_PROXY is
+	// then ignored, since a client can set it via the Proxy request header.
+	CGI bool
+}
+
+// ProxyConfigFromEnvironment reads HTTP_PROXY, HTTPS_PROXY and NO_PROXY,
+// falling back to their lower-case forms.
+func ProxyConfigFromEnvironment() *ProxyConfig {
+	return &ProxyConfig{
+		HTTPProxy:  getEnvAny("HTTP_PROXY", "http_proxy"),
+		HTTPSProxy: getEnvAny("HTTPS_PROXY", "https_proxy"),
+		NoProxy:    getEnvAny("NO_PROXY", "no_proxy"),
+		CGI:        os.Getenv("REQUEST_METHOD") != "",
+	}
+}
+
+func getEnvAny(names ...string) string {
+	for _, n := range names {
+		if v := os.Getenv(n); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+var envProxyFunc = sync.OnceValue(func() func(*Request) (*URL, error) {
+	return ProxyConfigFromEnvironment().ProxyFunc()
+})
+
+// ProxyFromEnvironment returns the proxy to use for r according to the
+// environment, or nil for a direct connection. The environment is read
+// once, on first use.
+func ProxyFromEnvironment(r *Request) (*URL, error) {
+	return envProxyFunc()(r)
+}
+
+// ProxyURL returns a proxy function that always selects u.
+func ProxyURL(u *URL) func(*Request) (*URL, error) {
+	return func(*Request) (*URL, error) { return u, nil }
+}
+
+// ProxyFunc returns a function selecting the proxy for a request, suitable
+// as a client's Proxy hook. Requests to loopback hosts and to hosts matched
+// by NoProxy get a nil URL.
+func (c *ProxyConfig) ProxyFunc() func(*Request) (*URL, error) {
+	httpProxy, httpErr := parseProxyURL(c.HTTPProxy)
+	if c.CGI {
+		httpProxy, httpErr = nil, nil
+	}
+	httpsProxy, httpsErr := parseProxyURL(c.HTTPSProxy)
+	np := parseNoProxy(c.NoProxy)
+
+	return func(r *Request) (*URL, error) {
+		scheme, host, port := requestTarget(r)
+		var proxy *URL
+		var err error
+		switch scheme {
+		case "https":
+			proxy, err = httpsProxy, httpsErr
+		case "http":
+			proxy, err = httpProxy, httpErr
+		}
+		if err != nil || proxy == nil {
+			return nil, err
+		}
+		if !np.useProxy(host, port) {
+			return nil, nil
+		}
+		return proxy, nil
+	}
+}
+
+// parseProxyURL parses a proxy setting. A bare "host:port" means an http
+// proxy. Empty settings yield nil.
+func parseProxyURL(s string) (*URL, error) {
+	if s == "" {
+		return nil, nil
+	}
+	scheme, rest, ok := strings.Cut(s, "://")
+	if !ok {
+		scheme, rest = "http", s
+	}
+	scheme = strings.ToLower(scheme)
+	switch scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("%w: unsupported scheme in %q", ErrInvalidProxyURL, s)
+	}
+	rest = strings.TrimSuffix(rest, "/")
+	if rest == "" || strings.ContainsAny(rest, "/?#") {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidProxyURL, s)
+	}
+	if strings.Contains(rest, "@") {
+		return nil, fmt.Errorf("%w: credentials in proxy URL are not supported", ErrInvalidProxyURL)
+	}
+	return &URL{Scheme: scheme, Host: strings.ToLower(rest), Path: "/"}, nil
+}
+
+// requestTarget returns the scheme, lower-case host and port r is aimed at.
+func requestTarget(r *Request) (scheme, host, port string) {
+	hostport := r.Host
+	scheme = "http"
+	if r.URL != nil {
+		if r.URL.Scheme != "" {
+			scheme = r.URL.Scheme
+		}
+		if r.URL.Host != "" {
+			hostport = r.URL.Host
+		}
+	}
+	if hostport == "" && r.Header != nil {
+		hostport = r.Header.Get("Host")
+	}
+	host, port, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host, port = strings.Trim(hostport, "[]"), ""
+	}
+	if port == "" {
+		port = "80"
+		if scheme == "https" {
+			port = "443"
+		}
+	}
+	return scheme, strings.ToLower(host), port
+}
+
+// -----------------------------------------------------------------------------
+// NO_PROXY matching
+// -----------------------------------------------------------------------------
+
+type noProxyDomain struct {
+	name       string // without leading dot
+	subdomOnly bool   // ".example.com" form
+	port       string // empty matches any port
+}
+
+type noProxy struct {
+	all      bool
+	prefixes []netip.Prefix
+	addrs    []netip.Addr
+	domains  []noProxyDomain
+}
+
+// parseNoProxy parses a NO_PROXY list. Entries are "*", IP addresses, CIDR
+// prefixes, or host names with an optional port. "example.com" matches the
+// domain and its subdomains; ".example.com" matches subdomains only.
+func parseNoProxy(s string) noProxy {
+	var np noProxy
+	for _, e := range strings.Split(s, ",") {
+		e = strings.ToLower(strings.TrimSpace(e))
+		switch {
+		case e == "":
+			continue
+		case e == "*":
+			np.all = true
+			continue
+		}
+		if p, err := netip.ParsePrefix(e); err == nil {
+			np.prefixes = append(np.prefixes, p)
+			continue
+		}
+		if a, err := netip.ParseAddr(strings.Trim(e, "[]")); err == nil {
+			np.addrs = append(np.addrs, a)
+			continue
+		}
+		d := noProxyDomain{name: e}
+		if h, p, err := net.SplitHostPort(e); err == nil {
+			if a, err := netip.ParseAddr(h); err == nil {
+				// IP with port: port restrictions are not worth the
+				// complexity for addresses; match any port.
+				np.addrs = append(np.addrs, a)
+				continue
+			}
+			d.name, d.port = h, p
+		}
+		if strings.HasPrefix(d.name, ".") {
+			d.name, d.subdomOnly = d.name[1:], true
+		}
+		np.domains = append(np.domains, d)
+	}
+	return np
+}
+
+// useProxy reports whether a request to host:port should go via the proxy.
+func (np noProxy) useProxy(host, port string) bool {
+	if host == "" {
+		return true
+	}
+	if host == "localhost" {
+		return false
+	}
+	if a, err := netip.ParseAddr(host); err == nil {
+		if a.IsLoopback() {
+			return false
+		}
+		if np.all {
+			return false
+		}
+		for _, p := range np.prefixes {
+			if p.Contains(a) {
+				return false
+			}
+		}
+		for _, x := range np.addrs {
+			if x == a {
+				return false
+			}
+		}
+		return true
+	}
+	if np.all {
+		return false
+	}
+	for _, d := range np.domains {
+		if d.port != "" && d.port != port {
+			continue
+		}
+		if host == d.name && !d.subdomOnly {
+			return false
+		}
+		if strings.HasSuffix(host, "."+d.name) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,96 @@
+package httpx
+
+import (
+	"errors"
+	"testing"
+)
+
+func proxyReq(target string) *Request {
+	u, err := ParseRequestURI(target)
+	if err != nil {
+		panic(err)
+	}
+	return &Request{URL: u, Header: Header{}}
+}
+
+func TestProxyFuncSchemes(t *testing.T) {
+	f := (&ProxyConfig{HTTPProxy: "proxy:3128", HTTPSProxy: "https://secure.proxy:443"}).ProxyFunc()
+
+	u, err := f(proxyReq("http://example.com/"))
+	if err != nil || u == nil || u.Scheme != "http" || u.Host != "proxy:3128" {
+		t.Fatalf("http: got %+v, %v", u, err)
+	}
+	u, err = f(proxyReq("https://example.com/"))
+	if err != nil || u == nil || u.Scheme != "https" || u.Host != "secure.proxy:443" {
+		t.Fatalf("https: got %+v, %v", u, err)
+	}
+}
+
+func TestProxyFuncNoProxy(t *testing.T) {
+	f := (&ProxyConfig{
+		HTTPProxy: "http://proxy:3128",
+		NoProxy:   "internal.example, .corp.example, 10.0.0.0/8, 192.0.2.7, api.example:8443",
+	}).ProxyFunc()
+
+	cases := map[string]bool{ // target -> proxied
+		"http://example.com/":               true,
+		"http://internal.example/":          false,
+		"http://a.internal.example/":        false,
+		"http://corp.example/":              true, // leading dot: subdomains only
+		"http://x.corp.example/":            false,
+		"http://10.1.2.3/":                  false,
+		"http://192.0.2.7:8080/":            false,
+		"http://192.0.2.8/":                 true,
+		"http://api.example:8443/":          false,
+		"http://api.example/":               true, // port must match
+		"http://localhost:8080/":            false,
+		"http://127.0.0.1/":                 false,
+		"http://[::1]:80/":                  false,
+		"http://notinternal.example/":       true,
+		"http://internal.example.evil.com/": true,
+	}
+	for target, want := range cases {
+		u, err := f(proxyReq(target))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (u != nil) != want {
+			t.Fatalf("%s: proxied=%v, want %v", target, u != nil, want)
+		}
+	}
+
+	all := (&ProxyConfig{HTTPProxy: "proxy:1", NoProxy: "*"}).ProxyFunc()
+	if u, _ := all(proxyReq("http://example.com/")); u != nil {
+		t.Fatal("NO_PROXY=* should disable proxying")
+	}
+}
+
+func TestProxyFuncCGI(t *testing.T) {
+	f := (&ProxyConfig{HTTPProxy: "evil:1", HTTPSProxy: "good:1", CGI: true}).ProxyFunc()
+	if u, _ := f(proxyReq("http://example.com/")); u != nil {
+		t.Fatal("HTTP_PROXY must be ignored under CGI")
+	}
+	if u, _ := f(proxyReq("https://example.com/")); u == nil {
+		t.Fatal("HTTPS_PROXY still applies under CGI")
+	}
+}
+
+func TestProxyFuncInvalid(t *testing.T) {
+	for _, s := range []string{"ftp://proxy:21", "http://user:pw@proxy:1", "http://proxy/path"} {
+		f := (&ProxyConfig{HTTPProxy: s}).ProxyFunc()
+		if _, err := f(proxyReq("http://example.com/")); !errors.Is(err, ErrInvalidProxyURL) {
+			t.Fatalf("%q: expected ErrInvalidProxyURL, got %v", s, err)
+		}
+	}
+}
+
+func TestProxyFromEnvironment(t *testing.T) {
+	t.Setenv("HTTP_PROXY", "")
+	t.Setenv("http_proxy", "lower:8080")
+	t.Setenv("NO_PROXY", "skip.example")
+	t.Setenv("REQUEST_METHOD", "")
+	c := ProxyConfigFromEnvironment()
+	if c.HTTPProxy != "lower:8080" || c.NoProxy != "skip.example" || c.CGI {
+		t.Fatalf("unexpected config %+v", c)
+	}
+}
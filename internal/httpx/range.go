@@ -0,0 +1,148 @@
+package httpx
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidRange indicates a malformed Range header. RFC 9110 has
+	// servers ignore such headers and send the full representation.
+	ErrInvalidRange = errors.New("httpx: invalid range")
+	// ErrRangeNotSatisfiable indicates that none of the requested ranges
+	// overlap the representation; answer with 416 and ContentRangeUnsatisfied.
+	ErrRangeNotSatisfiable = errors.New("httpx: range not satisfiable")
+)
+
+// maxRanges bounds how many ranges one header may list, so a client cannot
+// make a handler seek around thousands of tiny slices.
+const maxRanges = 100
+
+// Range is a satisfiable byte range of a representation of known size.
+type Range struct {
+	Start  int64
+	Length int64
+}
+
+// ContentRange formats r for a Content-Range header, e.g. "bytes 0-99/1000".
+func (r Range) ContentRange(size int64) string {
+	return "bytes " + strconv.FormatInt(r.Start, 10) + "-" +
+		strconv.FormatInt(r.Start+r.Length-1, 10) + "/" + strconv.FormatInt(size, 10)
+}
+
+// ContentRangeUnsatisfied formats the Content-Range of a 416 response.
+func ContentRangeUnsatisfied(size int64) string {
+	return "bytes */" + strconv.FormatInt(size, 10)
+}
+
+// ParseRange parses a Range header value against a representation of size
+// bytes. It handles first-last, open-ended ("500-") and suffix ("-500")
+// specs, clamps them to size, drops the unsatisfiable ones, and coalesces
+// overlapping or adjacent ranges, returning them in ascending order.
+//
+// A malformed header yields ErrInvalidRange; a well-formed one with no
+// satisfiable range yields ErrRangeNotSatisfiable. An empty spec returns
+// nil, nil. Handlers serving from any seekable source (files, blob stores)
+// can use it to answer 206 themselves.
+func ParseRange(spec string, size int64) ([]Range, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil
+	}
+	unit, set, ok := strings.Cut(spec, "=")
+	if !ok || !strings.EqualFold(strings.TrimSpace(unit), "bytes") {
+		return nil, ErrInvalidRange
+	}
+
+	var ranges []Range
+	n := 0
+	for _, part := range strings.Split(set, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue // RFC 9110 list syntax tolerates empty elements
+		}
+		if n++; n > maxRanges {
+			return nil, ErrInvalidRange
+		}
+		first, last, ok := strings.Cut(part, "-")
+		if !ok {
+			return nil, ErrInvalidRange
+		}
+		first, last = strings.TrimSpace(first), strings.TrimSpace(last)
+
+		var r Range
+		if first == "" {
+			// Suffix range: the final N bytes.
+			suffix, err := parseRangeInt(last)
+			if err != nil {
+				return nil, err
+			}
+			if suffix == 0 || size == 0 {
+				continue
+			}
+			suffix = min(suffix, size)
+			r = Range{Start: size - suffix, Length: suffix}
+		} else {
+			start, err := parseRangeInt(first)
+			if err != nil {
+				return nil, err
+			}
+			end := size - 1
+			if last != "" {
+				if end, err = parseRangeInt(last); err != nil {
+					return nil, err
+				}
+				if end < start {
+					return nil, ErrInvalidRange
+				}
+				end = min(end, size-1)
+			}
+			if start >= size {
+				continue
+			}
+			r = Range{Start: start, Length: end - start + 1}
+		}
+		ranges = append(ranges, r)
+	}
+	if n == 0 {
+		return nil, ErrInvalidRange
+	}
+	if len(ranges) == 0 {
+		return nil, ErrRangeNotSatisfiable
+	}
+	return coalesceRanges(ranges), nil
+}
+
+// parseRangeInt parses a non-negative decimal position.
+func parseRangeInt(s string) (int64, error) {
+	if s == "" {
+		return 0, ErrInvalidRange
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return 0, ErrInvalidRange
+		}
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidRange
+	}
+	return v, nil
+}
+
+// coalesceRanges sorts ranges and merges those that overlap or touch.
+func coalesceRanges(rs []Range) []Range {
+	sort.Slice(rs, func(i, j int) bool { return rs[i].Start < rs[j].Start })
+	out := rs[:1]
+	for _, r := range rs[1:] {
+		last := &out[len(out)-1]
+		if end := last.Start + last.Length; r.Start <= end {
+			last.Length = max(end, r.Start+r.Length) - last.Start
+			continue
+		}
+		out = append(out, r)
+	}
+	return out
+}
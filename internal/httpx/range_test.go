@@ -0,0 +1,64 @@
+package httpx
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseRange(t *testing.T) {
+	cases := []struct {
+		spec string
+		want []Range
+	}{
+		{"bytes=0-99", []Range{{0, 100}}},
+		{"bytes=900-", []Range{{900, 100}}},
+		{"bytes=-100", []Range{{900, 100}}},
+		{"bytes=-5000", []Range{{0, 1000}}},
+		{"bytes=990-2000", []Range{{990, 10}}},
+		{"Bytes = 0-0, -1", []Range{{0, 1}, {999, 1}}},
+		{"bytes=500-599,0-99", []Range{{0, 100}, {500, 100}}},
+		{"bytes=0-99,50-149,150-199", []Range{{0, 200}}},
+		{"bytes=0-9,2000-3000", []Range{{0, 10}}},
+		{"bytes=0-9,,", []Range{{0, 10}}},
+	}
+	for _, c := range cases {
+		got, err := ParseRange(c.spec, 1000)
+		if err != nil {
+			t.Fatalf("%q: %v", c.spec, err)
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Fatalf("%q: got %v want %v", c.spec, got, c.want)
+		}
+	}
+	if got, err := ParseRange("", 1000); got != nil || err != nil {
+		t.Fatalf("empty spec: %v %v", got, err)
+	}
+}
+
+func TestParseRangeErrors(t *testing.T) {
+	invalid := []string{"items=0-1", "bytes=", "bytes=5", "bytes=9-1", "bytes=a-b", "bytes=-", "bytes=+1-2", "bytes=0-1;x"}
+	for _, s := range invalid {
+		if _, err := ParseRange(s, 1000); !errors.Is(err, ErrInvalidRange) {
+			t.Fatalf("%q: got %v", s, err)
+		}
+	}
+	unsat := []struct {
+		spec string
+		size int64
+	}{{"bytes=1000-", 1000}, {"bytes=-0", 1000}, {"bytes=0-", 0}, {"bytes=-10", 0}}
+	for _, c := range unsat {
+		if _, err := ParseRange(c.spec, c.size); !errors.Is(err, ErrRangeNotSatisfiable) {
+			t.Fatalf("%q/%d: got %v", c.spec, c.size, err)
+		}
+	}
+}
+
+func TestContentRange(t *testing.T) {
+	if got := (Range{Start: 0, Length: 100}).ContentRange(1000); got != "bytes 0-99/1000" {
+		t.Fatal(got)
+	}
+	if got := ContentRangeUnsatisfied(1000); got != "bytes */1000" {
+		t.Fatal(got)
+	}
+}
@@ -0,0 +1,298 @@
+package httpx
+
+import (
+	"context"
+	"io"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimit describes a token bucket: Burst tokens refilled at Rate per second.
+type RateLimit struct {
+	Rate  float64 // tokens added per second
+	Burst int     // bucket capacity
+}
+
+// RateLimitDecision is the outcome of taking one token from a bucket.
+type RateLimitDecision struct {
+	Allowed    bool
+	Limit      int           // bucket capacity (RateLimit-Limit)
+	Remaining  int           // whole tokens left after this request (RateLimit-Remaining)
+	Reset      time.Duration // time until the bucket is full again (RateLimit-Reset)
+	RetryAfter time.Duration // time until a token is available; zero when Allowed
+}
+
+// RateLimitStore holds bucket state. The in-memory store suits a single
+// process; implementations backed by Redis or similar share limits across
+// instances.
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, lim RateLimit, now time.Time) (RateLimitDecision, error)
+}
+
+// RateLimiter applies a RateLimit per key extracted from each request.
+type RateLimiter struct {
+	Limit RateLimit
+	Store RateLimitStore          // defaults to a MemoryRateLimitStore of this limiter's own
+	Key   func(r *Request) string // defaults to ClientIP
+	Now   func() time.Time        // defaults to time.Now; for tests
+
+	ownOnce  sync.Once
+	ownStore *MemoryRateLimitStore
+}
+
+// Allow takes a token for r and reports the decision.
+func (l *RateLimiter) Allow(r *Request) (RateLimitDecision, error) {
+	store := l.Store
+	if store == nil {
+		// Each limiter gets its own buckets: sharing one store would let
+		// limiters with different limits drain each other for a key.
+		l.ownOnce.Do(func() { l.ownStore = NewMemoryRateLimitStore() })
+		store = l.ownStore
+	}
+	keyFn := l.Key
+	if keyFn == nil {
+		keyFn = ClientIP
+	}
+	now := time.Now
+	if l.Now != nil {
+		now = l.Now
+	}
+	return store.Take(r.Context(), keyFn(r), l.Limit, now())
+}
+
+// ClientIP returns the host part of r.RemoteAddr, or RemoteAddr itself
+// when it carries no port.
+func ClientIP(r *Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// SetRateLimitHeaders sets the RateLimit-Limit, RateLimit-Remaining and
+// RateLimit-Reset fields for d, plus Retry-After when the request was denied.
+func SetRateLimitHeaders(h Header, d RateLimitDecision) {
+	h.Set("RateLimit-Limit", strconv.Itoa(d.Limit))
+	h.Set("RateLimit-Remaining", strconv.Itoa(d.Remaining))
+	h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(d.Reset)))
+	if !d.Allowed {
+		h.Set("Retry-After", strconv.Itoa(ceilSeconds(d.RetryAfter)))
+	}
+}
+
+// WriteTooManyRequests writes a 429 response carrying the rate-limit headers for d.
+func WriteTooManyRequests(ctx context.Context, w io.Writer, d RateLimitDecision) error {
+	const body = "Too Many Requests\n"
+	h := Header{}
+	SetRateLimitHeaders(h, d)
+	h.Set("Content-Type", "text/plain; charset=utf-8")
+	h.Set("Content-Length", strconv.Itoa(len(body)))
+	return WriteResponse(ctx, w, &Response{
+		StatusCode: StatusTooManyRequests,
+		Header:     h,
+		Body:       strings.NewReader(body),
+	})
+}
+
+func ceilSeconds(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int(math.Ceil(d.Seconds()))
+}
+
+// -----------------------------------------------------------------------------
+// Parsing (client side)
+// -----------------------------------------------------------------------------
+
+// httpDateLayouts are the HTTP-date forms a recipient must accept
+// (RFC 9110 §5.6.7): IMF-fixdate first, then the obsolete RFC 850 and
+// asctime forms.
+var httpDateLayouts = []string{
+	"Mon, 02 Jan 2006 15:04:05 GMT",
+	"Monday, 02-Jan-06 15:04:05 GMT",
+	"Mon Jan _2 15:04:05 2006",
+}
+
+func parseHTTPDate(s string) (time.Time, bool) {
+	for _, layout := range httpDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// ParseRetryAfter interprets a Retry-After field value, either
+// delay-seconds or an HTTP-date, as the wait from now. Dates in the past
+// yield zero. ok is false for an empty or malformed value.
+func ParseRetryAfter(v string, now time.Time) (d time.Duration, ok bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0, false
+	}
+	if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+		if n < 0 {
+			return 0, false
+		}
+		return secondsToDuration(float64(n)), true
+	}
+	t, ok := parseHTTPDate(v)
+	if !ok {
+		return 0, false
+	}
+	if d := t.Sub(now); d > 0 {
+		return d, true
+	}
+	return 0, true
+}
+
+// epochResetThreshold separates X-RateLimit-Reset values given as Unix
+// timestamps, as GitHub and others send, from those given as seconds.
+const epochResetThreshold = 1 << 30
+
+// ParseRateLimitHeaders is the client-side counterpart of
+// SetRateLimitHeaders: it reads RateLimit-Limit, RateLimit-Remaining and
+// RateLimit-Reset from a response header, falling back to the X-RateLimit-*
+// names, plus Retry-After. A reset given as a Unix timestamp is converted
+// to a duration from now. Allowed is set unless Remaining is zero or
+// Retry-After is present; a denied decision without Retry-After waits
+// for the reset. ok is false when none of the fields is present.
+//
+// Fields that fail to parse are left at zero, so a client can still honor
+// the ones it understood.
+func ParseRateLimitHeaders(h Header, now time.Time) (d RateLimitDecision, ok bool) {
+	field := func(name string) (int64, bool) {
+		v := h.Get("RateLimit-" + name)
+		if v == "" {
+			v = h.Get("X-RateLimit-" + name)
+		}
+		if v == "" {
+			return 0, false
+		}
+		ok = true
+		n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		if err != nil || n < 0 {
+			return 0, false
+		}
+		return n, true
+	}
+
+	if n, found := field("Limit"); found {
+		d.Limit = int(n)
+	}
+	remaining, hasRemaining := field("Remaining")
+	d.Remaining = int(remaining)
+	if n, found := field("Reset"); found {
+		if n >= epochResetThreshold {
+			if r := time.Unix(n, 0).Sub(now); r > 0 {
+				d.Reset = r
+			}
+		} else {
+			d.Reset = secondsToDuration(float64(n))
+		}
+	}
+	if v := h.Get("Retry-After"); v != "" {
+		ok = true
+		d.RetryAfter, _ = ParseRetryAfter(v, now)
+	}
+	d.Allowed = h.Get("Retry-After") == "" && (!hasRemaining || remaining > 0)
+	if !d.Allowed && d.RetryAfter == 0 {
+		d.RetryAfter = d.Reset
+	}
+	return d, ok
+}
+
+// -----------------------------------------------------------------------------
+// In-memory store
+// -----------------------------------------------------------------------------
+
+// memoryPruneEvery controls how often full (idle) buckets are swept.
+const memoryPruneEvery = 4096
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+	lim    RateLimit // limit the bucket was last taken under
+}
+
+// MemoryRateLimitStore is a process-local RateLimitStore. Buckets that have
+// refilled completely are indistinguishable from new ones and are pruned
+// periodically to bound memory.
+type MemoryRateLimitStore struct {
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	ops     int
+}
+
+// NewMemoryRateLimitStore returns an empty in-memory store.
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{buckets: make(map[string]*tokenBucket)}
+}
+
+// Take implements RateLimitStore.
+func (s *MemoryRateLimitStore) Take(_ context.Context, key string, lim RateLimit, now time.Time) (RateLimitDecision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	burst := float64(lim.Burst)
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: burst, last: now}
+		s.buckets[key] = b
+	}
+	b.lim = lim
+	if lim.Rate > 0 {
+		if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+			b.tokens = math.Min(burst, b.tokens+elapsed*lim.Rate)
+		}
+	}
+	b.last = now
+
+	d := RateLimitDecision{Limit: lim.Burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		d.Allowed = true
+	} else if lim.Rate > 0 {
+		d.RetryAfter = secondsToDuration((1 - b.tokens) / lim.Rate)
+	} else {
+		d.RetryAfter = time.Duration(math.MaxInt64)
+	}
+	d.Remaining = int(b.tokens)
+	if lim.Rate > 0 {
+		d.Reset = secondsToDuration((burst - b.tokens) / lim.Rate)
+	}
+
+	s.ops++
+	if s.ops >= memoryPruneEvery {
+		s.ops = 0
+		s.prune(now)
+	}
+	return d, nil
+}
+
+// prune drops buckets that would be full by now under their own limit.
+func (s *MemoryRateLimitStore) prune(now time.Time) {
+	for k, b := range s.buckets {
+		if b.lim.Rate <= 0 {
+			continue
+		}
+		if b.tokens+now.Sub(b.last).Seconds()*b.lim.Rate >= float64(b.lim.Burst) {
+			delete(s.buckets, k)
+		}
+	}
+}
+
+// secondsToDuration converts s seconds to a Duration, saturating at the
+// largest one instead of overflowing into a negative wait.
+func secondsToDuration(s float64) time.Duration {
+	if s >= float64(math.MaxInt64)/float64(time.Second) {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(s * float64(time.Second))
+}
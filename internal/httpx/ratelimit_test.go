@@ -0,0 +1,201 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterTokenBucket(t *testing.T) {
+	now := time.Unix(1000, 0)
+	l := &RateLimiter{
+		Limit: RateLimit{Rate: 1, Burst: 2},
+		Store: NewMemoryRateLimitStore(),
+		Now:   func() time.Time { return now },
+	}
+	r := &Request{Header: Header{}, RemoteAddr: "10.0.0.1:5555"}
+
+	for i := 0; i < 2; i++ {
+		d, err := l.Allow(r)
+		if err != nil || !d.Allowed {
+			t.Fatalf("request %d should be allowed: %+v %v", i, d, err)
+		}
+	}
+	d, _ := l.Allow(r)
+	if d.Allowed || d.Remaining != 0 || d.RetryAfter != time.Second {
+		t.Fatalf("third request should be denied: %+v", d)
+	}
+
+	// A different client has its own bucket.
+	other := &Request{Header: Header{}, RemoteAddr: "10.0.0.2:1"}
+	if d, _ := l.Allow(other); !d.Allowed {
+		t.Fatal("other client should be allowed")
+	}
+
+	// Refill after one second.
+	now = now.Add(time.Second)
+	if d, _ := l.Allow(r); !d.Allowed {
+		t.Fatalf("should refill: %+v", d)
+	}
+}
+
+func TestRateLimiterCustomKey(t *testing.T) {
+	l := &RateLimiter{
+		Limit: RateLimit{Rate: 0, Burst: 1},
+		Store: NewMemoryRateLimitStore(),
+		Key:   func(r *Request) string { return r.Header.Get("X-Api-Key") },
+	}
+	a := &Request{Header: Header{"X-Api-Key": {"a"}}}
+	b := &Request{Header: Header{"X-Api-Key": {"b"}}}
+	if d, _ := l.Allow(a); !d.Allowed {
+		t.Fatal("first a should pass")
+	}
+	if d, _ := l.Allow(a); d.Allowed {
+		t.Fatal("second a should be limited")
+	}
+	if d, _ := l.Allow(b); !d.Allowed {
+		t.Fatal("b has its own bucket")
+	}
+}
+
+func TestRateLimitersDoNotShareBuckets(t *testing.T) {
+	strict := &RateLimiter{Limit: RateLimit{Rate: 0, Burst: 1}}
+	loose := &RateLimiter{Limit: RateLimit{Rate: 100, Burst: 5}}
+	r := &Request{Header: Header{}, RemoteAddr: "10.0.0.1:5555"}
+
+	for i := 0; i < 5; i++ {
+		if d, _ := loose.Allow(r); !d.Allowed {
+			t.Fatalf("loose request %d should be allowed: %+v", i, d)
+		}
+	}
+	if d, _ := strict.Allow(r); !d.Allowed || d.Limit != 1 {
+		t.Fatalf("strict limiter drained by loose one: %+v", d)
+	}
+	if d, _ := strict.Allow(r); d.Allowed {
+		t.Fatal("second strict request should be limited")
+	}
+}
+
+func TestMemoryRateLimitStoreTinyRate(t *testing.T) {
+	s := NewMemoryRateLimitStore()
+	lim := RateLimit{Rate: 1e-12, Burst: 1}
+	now := time.Unix(1000, 0)
+	s.Take(context.Background(), "k", lim, now)
+	d, _ := s.Take(context.Background(), "k", lim, now)
+	if d.Allowed || d.RetryAfter <= 0 || d.Reset <= 0 {
+		t.Fatalf("waits overflowed: %+v", d)
+	}
+}
+
+func TestMemoryRateLimitStorePrunesByBucketLimit(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryRateLimitStore()
+	now := time.Unix(1000, 0)
+	slow := RateLimit{Rate: 0.001, Burst: 1}
+	if d, _ := s.Take(ctx, "slow", slow, now); !d.Allowed {
+		t.Fatal("first slow take should be allowed")
+	}
+
+	// Enough takes under a fast limit to trigger a prune; the slow bucket
+	// must be judged by its own rate, not this one.
+	now = now.Add(time.Second)
+	for i := 0; i < memoryPruneEvery; i++ {
+		s.Take(ctx, "fast", RateLimit{Rate: 1000, Burst: 1}, now)
+	}
+	if d, _ := s.Take(ctx, "slow", slow, now); d.Allowed {
+		t.Fatalf("slow bucket was pruned and refilled: %+v", d)
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	cases := map[string]string{
+		"1.2.3.4:80":  "1.2.3.4",
+		"[::1]:8080":  "::1",
+		"unix-socket": "unix-socket",
+	}
+	for in, want := range cases {
+		if got := ClientIP(&Request{RemoteAddr: in}); got != want {
+			t.Fatalf("ClientIP(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestWriteTooManyRequests(t *testing.T) {
+	var buf bytes.Buffer
+	d := RateLimitDecision{Limit: 10, Remaining: 0, Reset: 1500 * time.Millisecond, RetryAfter: 200 * time.Millisecond}
+	if err := WriteTooManyRequests(context.Background(), &buf, d); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		"HTTP/1.1 429 ",
+		"Retry-After: 1\r\n",
+		"Ratelimit-Limit: 10\r\n",
+		"Ratelimit-Remaining: 0\r\n",
+		"Ratelimit-Reset: 2\r\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("missing %q in %q", want, got)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2015, 10, 21, 7, 28, 0, 0, time.UTC)
+	for _, c := range []struct {
+		in   string
+		want time.Duration
+		ok   bool
+	}{
+		{"120", 2 * time.Minute, true},
+		{" 0 ", 0, true},
+		{"Wed, 21 Oct 2015 07:28:30 GMT", 30 * time.Second, true},
+		{"Wednesday, 21-Oct-15 07:29:00 GMT", time.Minute, true},
+		{"Wed Oct 21 07:28:05 2015", 5 * time.Second, true},
+		{"Wed, 21 Oct 2015 07:00:00 GMT", 0, true}, // in the past
+		{"-5", 0, false},
+		{"99999999999999", time.Duration(math.MaxInt64), true}, // saturates
+		{"soon", 0, false},
+		{"", 0, false},
+	} {
+		got, ok := ParseRetryAfter(c.in, now)
+		if got != c.want || ok != c.ok {
+			t.Fatalf("%q: got %v %v, want %v %v", c.in, got, ok, c.want, c.ok)
+		}
+	}
+}
+
+func TestParseRateLimitHeaders(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	// Round trip with SetRateLimitHeaders.
+	h := Header{}
+	SetRateLimitHeaders(h, RateLimitDecision{Limit: 10, Remaining: 0, Reset: 4 * time.Second, RetryAfter: 2 * time.Second})
+	d, ok := ParseRateLimitHeaders(h, now)
+	if !ok || d.Allowed || d.Limit != 10 || d.Remaining != 0 || d.Reset != 4*time.Second || d.RetryAfter != 2*time.Second {
+		t.Fatalf("got %+v %v", d, ok)
+	}
+
+	// X- names with an epoch reset; no Retry-After, so wait for the reset.
+	h = Header{}
+	h.Set("X-RateLimit-Limit", "5000")
+	h.Set("X-RateLimit-Remaining", "0")
+	h.Set("X-RateLimit-Reset", "1700000060")
+	d, ok = ParseRateLimitHeaders(h, now)
+	if !ok || d.Allowed || d.Limit != 5000 || d.Reset != time.Minute || d.RetryAfter != time.Minute {
+		t.Fatalf("got %+v %v", d, ok)
+	}
+
+	h = Header{}
+	h.Set("RateLimit-Remaining", "3")
+	if d, ok := ParseRateLimitHeaders(h, now); !ok || !d.Allowed || d.Remaining != 3 {
+		t.Fatalf("got %+v %v", d, ok)
+	}
+
+	if _, ok := ParseRateLimitHeaders(Header{}, now); ok {
+		t.Fatal("no fields should report !ok")
+	}
+}
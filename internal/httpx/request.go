@@ -1,7 +1,9 @@
 package httpx
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -11,6 +13,24 @@ import (
 	"github.com/andycostintoma/httpx/internal/netx"
 )
 
+// ErrBodyNotRewindable is returned by RewindBody when the request has a body
+// but no GetBody.
+var ErrBodyNotRewindable = errors.New("httpx: request body cannot be rewound")
+
+// ErrTLSHandshake is returned by ParseRequest when the connection opens with
+// a TLS record instead of a request line, i.e. an HTTPS client reached a
+// plaintext listener. The peer cannot read a plaintext reply, so callers
+// should simply close the connection.
+var ErrTLSHandshake = errors.New("httpx: TLS handshake on plaintext connection")
+
+// ErrHTTP09 is returned for HTTP/0.9 simple requests ("GET /path" with no
+// version). They are not supported; callers should reply 400 and close.
+var ErrHTTP09 = errors.New("httpx: HTTP/0.9 request not supported")
+
+// ErrInvalidRequestURL is returned by NewRequest for URLs that are not
+// absolute http or https URLs.
+var ErrInvalidRequestURL = errors.New("httpx: invalid request URL")
+
 // requestLine models the first line of an HTTP/1.x request.
 type requestLine struct {
 	Method     string
@@ -26,7 +46,6 @@ func (r requestLine) String() string {
 }
 
 // Request represents a parsed HTTP/1.x request.
-// Body handling and header parsing are added in Stage 4.
 type Request struct {
 	requestLine
 	URL           *URL
@@ -34,49 +53,135 @@ type Request struct {
 	Host          string
 	ContentLength int64
 	Body          io.ReadCloser
-	ctx           context.Context
+	RemoteAddr    string // client "IP:port", set by the accepting side
+
+	// GetBody returns a fresh copy of Body so it can be replayed on
+	// redirects and retries. It is nil when the body cannot be rewound;
+	// SetBody fills it in for in-memory bodies.
+	GetBody func() (io.ReadCloser, error)
+
+	ctx   context.Context
+	limit ParseLimits // profile the head was parsed with; NewBodyReader applies it to the body
 }
 
 // ParseLimits controls how many bytes can be read from a request line or headers.
 type ParseLimits struct {
 	MaxLineBytes   int
 	MaxHeaderBytes int
+	MaxURIBytes    int         // request-target length; unlimited if zero
+	ObsFold        ObsFoldMode // handling of folded header lines; rejects by default
+
+	// Methods restricts the accepted request methods. When nil, any
+	// valid token is accepted.
+	Methods *MethodRegistry
+
+	// MaxChunkExtBytes and MaxTrailerBytes bound the chunk extensions and
+	// the trailer section of a chunked body read through NewBodyReader.
+	// Zero means unlimited.
+	MaxChunkExtBytes int
+	MaxTrailerBytes  int
+
+	// Checksum selects whether chunked bodies read through NewBodyReader
+	// are hashed and checked against a ChecksumTrailerField trailer. The
+	// final Read reports ErrChecksumMismatch or ErrChecksumMissing in
+	// place of io.EOF.
+	Checksum ChecksumPolicy
+
+	// Strict rejects inputs that lenient parsers tolerate but that enable
+	// request smuggling; see HardenedParseLimits.
+	Strict bool
 }
 
-// ParseRequest reads and parses the request line from r.
-// Headers and body are ignored at this stage.
+// ParseRequest reads and parses the request line and header section from r.
+// The body is left unread for NewBodyReader.
 func ParseRequest(r *netx.CRLFFastReader, limits ParseLimits) (*Request, error) {
+	req := &Request{}
+	if err := ParseRequestInto(req, r, limits); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// ParseRequestInto is ParseRequest filling a request obtained from
+// AcquireRequest, reusing its URL and Header storage.
+//
+// Errors are *Error values carrying the status to answer with; they
+// wrap the underlying cause, so errors.Is matches the sentinel errors
+// and io.EOF for a connection closed between requests.
+func ParseRequestInto(req *Request, r *netx.CRLFFastReader, limits ParseLimits) error {
+	return parseError(parseRequestInto(req, r, limits))
+}
+
+func parseRequestInto(req *Request, r *netx.CRLFFastReader, limits ParseLimits) error {
+	// A valid request line is much longer than a TLS record header, so
+	// waiting for three bytes never stalls a well-formed client.
+	if b, err := r.Peek(3); err == nil && isTLSRecordHeader(b) {
+		return ErrTLSHandshake
+	}
+
+	base, baseLine := r.Offset(), r.Line()
 	line, _, err := r.ReadLine(limits.MaxLineBytes)
 	if err != nil {
-		return nil, fmt.Errorf("read request line: %w", err)
+		return &OffsetError{Op: "read request line", Line: 1, Err: err}
+	}
+	if err := parseRequestLineInto(req, line, limits); err != nil {
+		return &OffsetError{Op: "request line", Line: 1, Err: err}
+	}
+
+	if req.Header == nil {
+		req.Header = make(Header)
 	}
+	if err := readHeaderInto(req.Header, r, limits, base, baseLine); err != nil {
+		return err
+	}
+
+	req.ctx = context.Background()
+	req.limit = limits
+
+	// For now, Host comes from URL if absolute-form.
+	if req.URL.Host != "" {
+		req.Host = strings.ToLower(req.URL.Host)
+	}
+
+	return nil
+}
+
+// parseRequestLineInto validates the request line and fills req's
+// method, target and version from it.
+func parseRequestLineInto(req *Request, line []byte, limits ParseLimits) error {
 	if len(line) == 0 {
-		return nil, errors.New("empty request line")
+		return errors.New("empty request line")
+	}
+	if limits.Strict {
+		if err := checkStrictLine(line); err != nil {
+			return err
+		}
 	}
 
 	rl, err := parseRequestLine(string(line))
 	if err != nil {
-		return nil, err
+		return err
 	}
-
-	u, err := ParseRequestURI(rl.RequestURI)
-	if err != nil {
-		return nil, err
+	if limits.MaxURIBytes > 0 && len(rl.RequestURI) > limits.MaxURIBytes {
+		return fmt.Errorf("%w: %d bytes", ErrURITooLong, len(rl.RequestURI))
 	}
-
-	req := &Request{
-		requestLine: rl,
-		URL:         u,
-		Header:      make(Header),
-		ctx:         context.Background(),
+	if limits.Methods != nil && !limits.Methods.Allowed(rl.Method) {
+		return fmt.Errorf("%w: %q", ErrMethodNotImplemented, rl.Method)
 	}
 
-	// For now, Host comes from URL if absolute-form.
-	if u.Host != "" {
-		req.Host = strings.ToLower(u.Host)
+	// The asterisk-form target is only meaningful for OPTIONS (RFC 9112 §3.2.4).
+	if rl.RequestURI == "*" && rl.Method != MethodOptions {
+		return fmt.Errorf("asterisk-form target with method %q", rl.Method)
 	}
 
-	return req, nil
+	if req.URL == nil {
+		req.URL = &URL{}
+	}
+	if err := parseRequestURIInto(req.URL, rl.RequestURI); err != nil {
+		return err
+	}
+	req.requestLine = rl
+	return nil
 }
 
 // parseRequestWithContext is the context-aware variant used in later stages.
@@ -96,9 +201,31 @@ func parseRequestWithContext(ctx context.Context, r *netx.CRLFFastReader, limits
 
 // parseRequestLine parses "METHOD SP Request-URI SP HTTP/x.y".
 func parseRequestLine(line string) (rl requestLine, err error) {
-	// Be tolerant of multiple spaces or tabs.
-	parts := strings.Fields(line)
-	if len(parts) != 3 {
+	// Be tolerant of multiple spaces or tabs. The fields are substrings of
+	// line, so splitting does not allocate.
+	var parts [3]string
+	n := 0
+	for i := 0; i < len(line); {
+		for i < len(line) && (line[i] == ' ' || line[i] == '\t') {
+			i++
+		}
+		if i == len(line) {
+			break
+		}
+		start := i
+		for i < len(line) && line[i] != ' ' && line[i] != '\t' {
+			i++
+		}
+		if n == len(parts) {
+			return rl, fmt.Errorf("malformed request line: %q", line)
+		}
+		parts[n] = line[start:i]
+		n++
+	}
+	if n == 2 && strings.HasPrefix(parts[1], "/") {
+		return rl, fmt.Errorf("%w: %q", ErrHTTP09, line)
+	}
+	if n != len(parts) {
 		return rl, fmt.Errorf("malformed request line: %q", line)
 	}
 
@@ -106,13 +233,8 @@ func parseRequestLine(line string) (rl requestLine, err error) {
 	target := parts[1]
 	proto := parts[2]
 
-	if len(method) == 0 || len(method) > 20 {
-		return rl, fmt.Errorf("invalid method: %q", method)
-	}
-	for _, c := range method {
-		if c < 'A' || c > 'Z' {
-			return rl, fmt.Errorf("method must be uppercase A–Z: %q", method)
-		}
+	if !isValidMethod(method) {
+		return rl, fmt.Errorf("%w: %q", ErrInvalidMethod, method)
 	}
 
 	if !strings.HasPrefix(proto, "HTTP/") {
@@ -139,6 +261,12 @@ func parseRequestLine(line string) (rl requestLine, err error) {
 	return rl, nil
 }
 
+// isTLSRecordHeader reports whether b starts like a TLS handshake record:
+// content type 22 followed by a 3.x record version (SSL 3.0 through TLS 1.3).
+func isTLSRecordHeader(b []byte) bool {
+	return len(b) >= 3 && b[0] == 0x16 && b[1] == 0x03 && b[2] <= 0x04
+}
+
 // Context returns the request's context.
 func (r *Request) Context() context.Context {
 	if r == nil || r.ctx == nil {
@@ -157,6 +285,141 @@ func (r *Request) WithContext(ctx context.Context) *Request {
 	return &cp
 }
 
+// NewRequest builds an outgoing HTTP/1.1 request for an absolute http or
+// https URL. Host and the origin-form request target are taken from the
+// URL, and body is installed with SetBody, so in-memory bodies get a
+// Content-Length and a GetBody for retries. ctx must be non-nil.
+func NewRequest(ctx context.Context, method, rawURL string, body io.Reader) (*Request, error) {
+	if !isValidMethod(method) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidMethod, method)
+	}
+	u, err := ParseRequestURI(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRequestURL, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("%w: %q is not an absolute http(s) URL", ErrInvalidRequestURL, rawURL)
+	}
+	target := u.Path
+	if u.RawQuery != "" {
+		target += "?" + u.RawQuery
+	}
+	r := &Request{
+		requestLine: requestLine{
+			Method:     method,
+			RequestURI: target,
+			Proto:      "HTTP/1.1",
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+		},
+		URL:    u,
+		Header: Header{},
+		Host:   u.Host,
+		ctx:    ctx,
+	}
+	r.SetBody(body)
+	return r, nil
+}
+
+// NewRequestWithJSON is NewRequest with v encoded as an application/json
+// body.
+func NewRequestWithJSON(ctx context.Context, method, rawURL string, v any) (*Request, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("httpx: encode json: %w", err)
+	}
+	r, err := NewRequest(ctx, method, rawURL, bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	r.Header.Set("Content-Type", "application/json")
+	return r, nil
+}
+
+// Clone returns a deep copy of r with its context replaced by ctx. Header
+// and URL are copied, so the clone can be mutated freely. When GetBody is
+// set the clone gets a fresh body from it, making it independently
+// readable; otherwise both requests share Body, which only one of them
+// may consume.
+func (r *Request) Clone(ctx context.Context) (*Request, error) {
+	cp := *r
+	cp.ctx = ctx
+	cp.Header = r.Header.Clone()
+	if r.URL != nil {
+		u := *r.URL
+		cp.URL = &u
+	}
+	if r.GetBody != nil {
+		body, err := r.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		cp.Body = body
+	}
+	return &cp, nil
+}
+
+// SetBody sets r.Body to body. For *bytes.Buffer, *bytes.Reader and
+// *strings.Reader it also sets ContentLength and a GetBody that replays a
+// snapshot of the unread content. Other readers clear GetBody and leave
+// ContentLength to the caller.
+func (r *Request) SetBody(body io.Reader) {
+	r.GetBody = nil
+	if body == nil {
+		r.Body = nil
+		r.ContentLength = 0
+		return
+	}
+	if rc, ok := body.(io.ReadCloser); ok {
+		r.Body = rc
+	} else {
+		r.Body = io.NopCloser(body)
+	}
+	switch v := body.(type) {
+	case *bytes.Buffer:
+		buf := v.Bytes()
+		r.ContentLength = int64(len(buf))
+		r.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(buf)), nil
+		}
+	case *bytes.Reader:
+		snapshot := *v
+		r.ContentLength = int64(v.Len())
+		r.GetBody = func() (io.ReadCloser, error) {
+			rd := snapshot
+			return io.NopCloser(&rd), nil
+		}
+	case *strings.Reader:
+		snapshot := *v
+		r.ContentLength = int64(v.Len())
+		r.GetBody = func() (io.ReadCloser, error) {
+			rd := snapshot
+			return io.NopCloser(&rd), nil
+		}
+	}
+}
+
+// RewindBody closes the current body and replaces it with a fresh copy from
+// GetBody. Requests without a body are left alone; requests whose body
+// cannot be replayed return ErrBodyNotRewindable.
+func (r *Request) RewindBody() error {
+	if r.GetBody == nil {
+		if r.Body == nil {
+			return nil
+		}
+		return ErrBodyNotRewindable
+	}
+	if r.Body != nil {
+		r.Body.Close()
+	}
+	body, err := r.GetBody()
+	if err != nil {
+		return err
+	}
+	r.Body = body
+	return nil
+}
+
 // String returns a human-readable representation of the request line.
 func (r *Request) String() string {
 	if r == nil {
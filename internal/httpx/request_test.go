@@ -3,6 +3,8 @@ package httpx
 import (
 	"bytes"
 	"context"
+	"errors"
+	"io"
 	"strings"
 	"testing"
 
@@ -25,12 +27,12 @@ func TestParseRequestLine(t *testing.T) {
 
 func TestParseRequestLineBad(t *testing.T) {
 	cases := []string{
-		"G ET / HTTP/1.1",                     // space in method
-		"GET / WTF/1.1",                       // proto missing HTTP/
-		"GET / HTTP/x.y",                      // invalid version numbers
-		"",                                    // empty
-		"GET / HTTP/1",                        // missing minor version
-		"TOOLONGMETHODNAMEFORHTTP / HTTP/1.1", // >20 chars
+		"G ET / HTTP/1.1", // space in method
+		"GET / WTF/1.1",   // proto missing HTTP/
+		"GET / HTTP/x.y",  // invalid version numbers
+		"",                // empty
+		"GET / HTTP/1",    // missing minor version
+		"GE(T / HTTP/1.1", // delimiter in method
 	}
 	for _, c := range cases {
 		if _, err := parseRequestLine(c); err == nil {
@@ -82,3 +84,240 @@ func TestContextCancelDuringParse(t *testing.T) {
 		t.Fatal("expected ctx error")
 	}
 }
+
+func TestParseRequestHeaders(t *testing.T) {
+	raw := "GET / HTTP/1.1\r\nhost: ex.com\r\nAccept:  a \r\nAccept: b\r\n\r\nBODY"
+	rd := netx.NewCRLFFastReader(strings.NewReader(raw))
+	req, err := ParseRequest(rd, ParseLimits{MaxLineBytes: 4096})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Header.Get("Host") != "ex.com" {
+		t.Fatalf("Host header = %q", req.Header.Get("Host"))
+	}
+	if v := req.Header.Values("Accept"); len(v) != 2 || v[0] != "a" || v[1] != "b" {
+		t.Fatalf("Accept = %#v", v)
+	}
+	// The body must be left unread.
+	rest, _, _ := rd.ReadLine(4096)
+	if string(rest) != "BODY" {
+		t.Fatalf("body consumed, next line %q", rest)
+	}
+}
+
+func TestParseRequestObsFold(t *testing.T) {
+	raw := "GET / HTTP/1.1\r\nX-Long: a\r\n  b\r\n\r\n"
+
+	rd := netx.NewCRLFFastReader(strings.NewReader(raw))
+	if _, err := ParseRequest(rd, ParseLimits{MaxLineBytes: 4096}); !errors.Is(err, ErrObsFold) {
+		t.Fatalf("expected ErrObsFold by default, got %v", err)
+	}
+
+	rd = netx.NewCRLFFastReader(strings.NewReader(raw))
+	req, err := ParseRequest(rd, ParseLimits{MaxLineBytes: 4096, ObsFold: ObsFoldUnfold})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := req.Header.Get("X-Long"); got != "a b" {
+		t.Fatalf("unfolded value = %q", got)
+	}
+}
+
+func TestParseRequestBadHeaders(t *testing.T) {
+	cases := []string{
+		"GET / HTTP/1.1\r\n Host: x\r\n\r\n",     // whitespace before first field
+		"GET / HTTP/1.1\r\nHost : x\r\n\r\n",     // whitespace before colon
+		"GET / HTTP/1.1\r\nNoColon\r\n\r\n",      // missing colon
+		"GET / HTTP/1.1\r\n: empty-name\r\n\r\n", // empty field name
+		"GET / HTTP/1.1\r\nHost: x\r\n",          // truncated header section
+	}
+	for _, raw := range cases {
+		rd := netx.NewCRLFFastReader(strings.NewReader(raw))
+		if _, err := ParseRequest(rd, ParseLimits{MaxLineBytes: 4096, ObsFold: ObsFoldUnfold}); err == nil {
+			t.Fatalf("expected error for %q", raw)
+		}
+	}
+}
+
+func TestParseRequestHeaderBudget(t *testing.T) {
+	raw := "GET / HTTP/1.1\r\nX-A: 1234567890\r\nX-B: 1234567890\r\n\r\n"
+	rd := netx.NewCRLFFastReader(strings.NewReader(raw))
+	_, err := ParseRequest(rd, ParseLimits{MaxLineBytes: 4096, MaxHeaderBytes: 20})
+	if !errors.Is(err, netx.ErrLineTooLong) {
+		t.Fatalf("expected header budget error, got %v", err)
+	}
+}
+
+func BenchmarkParseRequestLine(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := parseRequestLine("GET /index.html?q=1 HTTP/1.1"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseRequest(b *testing.B) {
+	raw := "GET /index.html?q=1 HTTP/1.1\r\n" +
+		"Host: example.com\r\n" +
+		"user-agent: bench/1.0\r\n" +
+		"Accept: */*\r\n" +
+		"Accept-Encoding: gzip\r\n" +
+		"Connection: keep-alive\r\n\r\n"
+	src := strings.NewReader(raw)
+	rd := netx.NewCRLFFastReader(src)
+	limits := ParseLimits{MaxLineBytes: 4096, MaxHeaderBytes: 8192}
+	b.ReportAllocs()
+	b.SetBytes(int64(len(raw)))
+	for i := 0; i < b.N; i++ {
+		src.Reset(raw)
+		rd.Reset(src)
+		if _, err := ParseRequest(rd, limits); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestParseRequestTLSHandshake(t *testing.T) {
+	// Start of a TLS 1.2 ClientHello record.
+	hello := []byte{0x16, 0x03, 0x01, 0x02, 0x00, 0x01, 0x00, 0x01, 0xfc, 0x03, 0x03}
+	rd := netx.NewCRLFFastReader(bytes.NewReader(hello))
+	if _, err := ParseRequest(rd, ParseLimits{MaxLineBytes: 4096}); !errors.Is(err, ErrTLSHandshake) {
+		t.Fatalf("expected ErrTLSHandshake, got %v", err)
+	}
+}
+
+func TestParseRequestHTTP09(t *testing.T) {
+	rd := netx.NewCRLFFastReader(strings.NewReader("GET /index.html\r\n"))
+	if _, err := ParseRequest(rd, ParseLimits{MaxLineBytes: 4096}); !errors.Is(err, ErrHTTP09) {
+		t.Fatalf("expected ErrHTTP09, got %v", err)
+	}
+
+	// Two fields without a path are just malformed.
+	if _, err := parseRequestLine("GET HTTP/1.1"); err == nil || errors.Is(err, ErrHTTP09) {
+		t.Fatalf("expected plain malformed error, got %v", err)
+	}
+}
+
+func TestParseRequestAsteriskForm(t *testing.T) {
+	rd := netx.NewCRLFFastReader(strings.NewReader("OPTIONS * HTTP/1.1\r\nHost: ex.com\r\n\r\n"))
+	req, err := ParseRequest(rd, ParseLimits{MaxLineBytes: 4096})
+	if err != nil || req.URL.Path != "*" {
+		t.Fatalf("got %+v, %v", req, err)
+	}
+
+	rd = netx.NewCRLFFastReader(strings.NewReader("GET * HTTP/1.1\r\nHost: ex.com\r\n\r\n"))
+	if _, err := ParseRequest(rd, ParseLimits{MaxLineBytes: 4096}); err == nil {
+		t.Fatal("asterisk-form must be rejected for GET")
+	}
+}
+
+func TestRequestSetBodyRewind(t *testing.T) {
+	for name, body := range map[string]io.Reader{
+		"buffer":  bytes.NewBufferString("hello"),
+		"bytes":   bytes.NewReader([]byte("hello")),
+		"strings": strings.NewReader("hello"),
+	} {
+		r := &Request{}
+		r.SetBody(body)
+		if r.ContentLength != 5 || r.GetBody == nil {
+			t.Fatalf("%s: ContentLength=%d GetBody=%v", name, r.ContentLength, r.GetBody != nil)
+		}
+		for i := 0; i < 2; i++ {
+			got, _ := io.ReadAll(r.Body)
+			if string(got) != "hello" {
+				t.Fatalf("%s pass %d: got %q", name, i, got)
+			}
+			if err := r.RewindBody(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestRequestRewindBodyUnsupported(t *testing.T) {
+	r := &Request{}
+	if err := r.RewindBody(); err != nil {
+		t.Fatalf("no body should rewind trivially: %v", err)
+	}
+	r.SetBody(io.LimitReader(strings.NewReader("x"), 1))
+	if r.GetBody != nil {
+		t.Fatal("streaming bodies are not rewindable")
+	}
+	if err := r.RewindBody(); !errors.Is(err, ErrBodyNotRewindable) {
+		t.Fatalf("expected ErrBodyNotRewindable, got %v", err)
+	}
+}
+
+func TestRequestClone(t *testing.T) {
+	r := &Request{
+		requestLine: requestLine{Method: MethodPost, RequestURI: "/a?x=1", Proto: "HTTP/1.1"},
+		URL:         &URL{Path: "/a", RawQuery: "x=1"},
+		Header:      Header{"X-A": {"1"}},
+	}
+	r.SetBody(strings.NewReader("payload"))
+
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "v")
+	c, err := r.Clone(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Header.Add("X-A", "2")
+	c.Header.Set("X-B", "3")
+	c.URL.Path = "/b"
+	if len(r.Header["X-A"]) != 1 || r.Header.Get("X-B") != "" || r.URL.Path != "/a" {
+		t.Fatal("clone shares header or URL with original")
+	}
+	if c.Context().Value(key{}) != "v" || c.Method != MethodPost {
+		t.Fatal("clone lost context or request line")
+	}
+
+	// Both bodies can be read in full.
+	cb, _ := io.ReadAll(c.Body)
+	rb, _ := io.ReadAll(r.Body)
+	if string(cb) != "payload" || string(rb) != "payload" {
+		t.Fatalf("clone %q original %q", cb, rb)
+	}
+
+	boom := errors.New("boom")
+	r.GetBody = func() (io.ReadCloser, error) { return nil, boom }
+	if _, err := r.Clone(ctx); !errors.Is(err, boom) {
+		t.Fatalf("got %v", err)
+	}
+}
+
+func TestNewRequest(t *testing.T) {
+	r, err := NewRequest(context.Background(), MethodPut, "https://API.example.com/v1/items?id=3", strings.NewReader("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Host != "api.example.com" || r.RequestURI != "/v1/items?id=3" || r.Proto != "HTTP/1.1" ||
+		r.URL.Scheme != "https" || r.ContentLength != 5 || r.GetBody == nil {
+		t.Fatalf("built %+v", r)
+	}
+	if got := r.String(); got != "PUT /v1/items?id=3 HTTP/1.1" {
+		t.Fatal(got)
+	}
+
+	r, err = NewRequestWithJSON(context.Background(), MethodPost, "http://h", map[string]int{"n": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := io.ReadAll(r.Body)
+	if r.Header.Get("Content-Type") != "application/json" || string(b) != `{"n":1}` || r.ContentLength != 7 || r.RequestURI != "/" {
+		t.Fatalf("json request %+v %q", r, b)
+	}
+
+	if _, err := NewRequest(context.Background(), "BAD METHOD", "http://h/", nil); !errors.Is(err, ErrInvalidMethod) {
+		t.Fatalf("got %v", err)
+	}
+	for _, u := range []string{"/relative", "", "ftp://h/"} {
+		if _, err := NewRequest(context.Background(), MethodGet, u, nil); !errors.Is(err, ErrInvalidRequestURL) {
+			t.Fatalf("%q: got %v", u, err)
+		}
+	}
+	if _, err := NewRequestWithJSON(context.Background(), MethodPost, "http://h", make(chan int)); err == nil {
+		t.Fatal("unencodable value accepted")
+	}
+}
@@ -2,11 +2,19 @@ package httpx
 
 import (
 	"bufio"
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"hash"
 	"io"
+	"net"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 // Response represents a minimal HTTP/1.x response to serialize.
@@ -16,13 +24,39 @@ type Response struct {
 	Status     string    // e.g. "OK"
 	Header     Header    // response headers
 	Body       io.Reader // may be nil
+
+	// Request is the request being answered, if known. For HEAD requests
+	// the head is written as set (including Content-Length) but the body
+	// is never sent, so GET handlers can serve HEAD unchanged.
+	Request *Request
+
+	// Trailer holds fields sent after a chunked body. It is read once the
+	// body is exhausted, so a Body reader may fill in values as it reaches
+	// EOF. Declare the names up front in a "Trailer" header field. It is
+	// ignored for other framings.
+	Trailer Header
+
+	// ChecksumTrailer makes a chunked body end with a ChecksumTrailerField
+	// trailer holding the SHA-256 of the bytes sent, computed as they
+	// stream. The field is declared in the head automatically. A response
+	// without declared framing is sent chunked when the peer allows it;
+	// the option is ignored for Content-Length and until-close bodies.
+	ChecksumTrailer bool
 }
 
 // WriteResponse serializes an HTTP/1.x response (status line, headers, body).
 // It selects transfer semantics by inspecting headers:
 //   - Content-Length present -> write exactly that many bytes
 //   - Transfer-Encoding: chunked -> write chunked body
-//   - else -> stream until EOF (caller manages connection close semantics)
+//   - neither -> buffer up to autoFrameSize of the body; if it ends there,
+//     send it with a Content-Length, otherwise stream it chunked, or until
+//     close (adding "Connection: close") when the peer speaks HTTP/1.0.
+//     The chosen framing header is added to resp.Header.
+//
+// The status line and headers are built into a single buffer and sent
+// together with the first block of the body: as one vectored write
+// (net.Buffers, i.e. writev on TCP connections) for fixed-length bodies,
+// or through the same buffered write otherwise.
 func WriteResponse(ctx context.Context, w io.Writer, resp *Response) error {
 	select {
 	case <-ctx.Done():
@@ -30,85 +64,256 @@ func WriteResponse(ctx context.Context, w io.Writer, resp *Response) error {
 	default:
 	}
 
-	bw := bufio.NewWriter(w)
-
-	proto := resp.Proto
-	if proto == "" {
-		proto = "HTTP/1.1"
-	}
 	if resp.Status == "" {
-		// best-effort: if missing, synthesize "NNN"
-		resp.Status = strconv.Itoa(resp.StatusCode)
+		// Use the registered reason phrase; fall back to "NNN" for
+		// unknown codes so the status line stays well-formed.
+		resp.Status = StatusText(resp.StatusCode)
+		if resp.Status == "" {
+			resp.Status = strconv.Itoa(resp.StatusCode)
+		}
 	}
 
-	// Status line: "HTTP/1.1 200 OK\r\n"
-	if _, err := bw.WriteString(fmt.Sprintf("%s %d %s\r\n", proto, resp.StatusCode, resp.Status)); err != nil {
-		return err
+	// 1xx, 204 and 304 responses never carry a body (RFC 9110 §6.4.1).
+	if !bodyAllowedForStatus(resp.StatusCode) && resp.Body != nil {
+		var probe [1]byte
+		if n, _ := io.ReadFull(resp.Body, probe[:]); n > 0 {
+			return fmt.Errorf("%w: status %d", ErrBodyNotAllowed, resp.StatusCode)
+		}
 	}
 
-	// Emit headers (each value on its own line).
-	for k, vals := range resp.Header {
-		ck := CanonicalHeaderKey(k)
-		for _, v := range vals {
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			default:
+	// Validate framing before anything reaches the wire.
+	fixed := int64(-1)
+	if resp.Body != nil && !resp.isHead() && bodyAllowedForStatus(resp.StatusCode) {
+		if clStr := resp.Header.Get("Content-Length"); clStr != "" {
+			n, err := strconv.ParseInt(strings.TrimSpace(clStr), 10, 64)
+			if err != nil || n < 0 {
+				return ErrLengthMismatch
 			}
-			if _, err := bw.WriteString(ck + ": " + v + "\r\n"); err != nil {
-				return err
+			fixed = n
+		}
+	}
+
+	// No framing declared: pick the cheapest one that fits the body.
+	body := resp.Body
+	if fixed < 0 && resp.ChecksumTrailer && resp.Body != nil && resp.chunkedAllowed() &&
+		resp.Header.Get("Transfer-Encoding") == "" {
+		// Only chunked framing can carry the checksum.
+		if resp.Header == nil {
+			resp.Header = Header{}
+		}
+		resp.Header.Set("Transfer-Encoding", "chunked")
+	}
+	if fixed < 0 && resp.Body != nil && !resp.isHead() && bodyAllowedForStatus(resp.StatusCode) &&
+		resp.Header.Get("Transfer-Encoding") == "" {
+		if resp.Header == nil {
+			resp.Header = Header{}
+		}
+		bp := chunkBufPool.Get().(*[]byte)
+		defer chunkBufPool.Put(bp)
+		buf := (*bp)[:autoFrameSize]
+		n, err := io.ReadFull(resp.Body, buf)
+		switch {
+		case err == io.EOF || err == io.ErrUnexpectedEOF:
+			fixed = int64(n)
+			resp.Header.Set("Content-Length", strconv.Itoa(n))
+			body = bytes.NewReader(buf[:n])
+		case err != nil:
+			return err
+		default:
+			body = io.MultiReader(bytes.NewReader(buf), resp.Body)
+			if resp.chunkedAllowed() {
+				resp.Header.Set("Transfer-Encoding", "chunked")
+			} else {
+				resp.Header.Set("Connection", "close")
 			}
 		}
 	}
 
-	// End of header section.
-	if _, err := bw.WriteString("\r\n"); err != nil {
-		return err
+	chunked := strings.EqualFold(resp.Header.Get("Transfer-Encoding"), "chunked")
+	if chunked && resp.ChecksumTrailer && !trailerDeclared(resp.Header, ChecksumTrailerField) {
+		resp.Header.Add("Trailer", ChecksumTrailerField)
 	}
-	if err := bw.Flush(); err != nil {
+
+	hp := headBufPool.Get().(*[]byte)
+	defer func() {
+		*hp = (*hp)[:0]
+		headBufPool.Put(hp)
+	}()
+	head := appendResponseHead((*hp)[:0], resp)
+	*hp = head
+
+	// If no body (or a HEAD response, or a bodiless status), we're done.
+	if resp.Body == nil || resp.isHead() || !bodyAllowedForStatus(resp.StatusCode) {
+		_, err := w.Write(head)
 		return err
 	}
 
-	// If no body, we're done.
-	if resp.Body == nil {
-		return nil
+	if fixed >= 0 {
+		return writeFixedBody(ctx, w, head, body, fixed)
 	}
 
-	// Body strategy:
-	if clStr := resp.Header.Get("Content-Length"); clStr != "" {
-		// Fixed length
-		n, err := strconv.ParseInt(strings.TrimSpace(clStr), 10, 64)
-		if err != nil || n < 0 {
-			return ErrLengthMismatch
-		}
-		// copy exactly N bytes
-		if _, err := io.CopyN(bw, resp.Body, n); err != nil {
-			return err
-		}
-		return bw.Flush()
+	bw := bufio.NewWriter(w)
+	if _, err := bw.Write(head); err != nil {
+		return err
 	}
 
-	if strings.EqualFold(resp.Header.Get("Transfer-Encoding"), "chunked") {
+	if chunked {
 		// Chunked writer
 		cw := newChunkedWriter(ctx, bw)
+		if resp.ChecksumTrailer {
+			cw.sum = sha256.New()
+		}
 		// Stream body in reasonable chunks; io.Copy will call Write on cw.
-		if _, err := io.Copy(cw, resp.Body); err != nil {
+		if _, err := io.Copy(cw, body); err != nil {
 			_ = cw.Close() // attempt to close trailer even on error
 			return err
 		}
-		if err := cw.Close(); err != nil {
+		if err := cw.closeWithTrailer(resp.Trailer); err != nil {
 			return err
 		}
 		return bw.Flush()
 	}
 
 	// Until-close: just stream everything.
-	if _, err := io.Copy(bw, resp.Body); err != nil {
+	if _, err := io.Copy(bw, body); err != nil {
 		return err
 	}
 	return bw.Flush()
 }
 
+// ErrBodyNotAllowed is returned by WriteResponse when a body is supplied for
+// a status code that forbids one (1xx, 204, 304).
+var ErrBodyNotAllowed = errors.New("httpx: response status does not allow a body")
+
+// bodyAllowedForStatus reports whether a response with the given status
+// may include a body.
+func bodyAllowedForStatus(code int) bool {
+	switch {
+	case code >= 100 && code <= 199:
+		return false
+	case code == StatusNoContent, code == StatusNotModified:
+		return false
+	}
+	return true
+}
+
+// autoFrameSize is how much of a body without declared framing WriteResponse
+// buffers to decide between Content-Length and streaming. It must not
+// exceed the chunkBufPool buffer size.
+const autoFrameSize = 16 << 10
+
+// chunkedAllowed reports whether the peer can receive a chunked body: the
+// response is not HTTP/1.0 and neither is the request, when known.
+func (resp *Response) chunkedAllowed() bool {
+	if resp.Proto == "HTTP/1.0" {
+		return false
+	}
+	if r := resp.Request; r != nil && r.ProtoMajor == 1 && r.ProtoMinor == 0 {
+		return false
+	}
+	return true
+}
+
+// isHead reports whether resp answers a HEAD request.
+func (resp *Response) isHead() bool {
+	return resp.Request != nil && resp.Request.Method == MethodHead
+}
+
+// firstBlockSize bounds how much of a fixed-length body is sent in the
+// same vectored write as the head.
+const firstBlockSize = 16 << 10
+
+// writeFixedBody sends head plus the first block of a Content-Length body
+// in one vectored write, then copies the remainder directly to w.
+func writeFixedBody(ctx context.Context, w io.Writer, head []byte, body io.Reader, n int64) error {
+	first := n
+	if first > firstBlockSize {
+		first = firstBlockSize
+	}
+
+	var block []byte
+	if first > 0 {
+		bp := chunkBufPool.Get().(*[]byte)
+		defer chunkBufPool.Put(bp)
+		block = (*bp)[:first]
+		if _, err := io.ReadFull(body, block); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return ErrLengthMismatch
+			}
+			return err
+		}
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	bufs := net.Buffers{head, block}
+	if _, err := bufs.WriteTo(w); err != nil {
+		return err
+	}
+
+	// copy the remaining bytes
+	if rest := n - first; rest > 0 {
+		if _, err := io.CopyN(w, body, rest); err != nil {
+			if err == io.EOF {
+				return ErrLengthMismatch
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// headBufPool recycles the buffers the status line and headers are built in.
+var headBufPool = sync.Pool{
+	New: func() any {
+		b := make([]byte, 0, 1024)
+		return &b
+	},
+}
+
+// appendResponseHead appends the status line, headers and the blank line
+// ending the header section to b.
+func appendResponseHead(b []byte, resp *Response) []byte {
+	proto := resp.Proto
+	if proto == "" {
+		proto = "HTTP/1.1"
+	}
+
+	// Status line: "HTTP/1.1 200 OK\r\n"
+	b = append(b, proto...)
+	b = append(b, ' ')
+	b = strconv.AppendInt(b, int64(resp.StatusCode), 10)
+	b = append(b, ' ')
+	b = append(b, resp.Status...)
+	b = append(b, "\r\n"...)
+
+	// 1xx and 204 responses must not carry framing headers (RFC 9110
+	// §8.6, RFC 9112 §6.1); 304 keeps them to describe the selected
+	// representation.
+	noFraming := resp.StatusCode/100 == 1 || resp.StatusCode == StatusNoContent
+
+	// Emit headers (each value on its own line).
+	for k, vals := range resp.Header {
+		ck := CanonicalHeaderKey(k)
+		if noFraming && (ck == "Content-Length" || ck == "Transfer-Encoding") {
+			continue
+		}
+		for _, v := range vals {
+			b = append(b, ck...)
+			b = append(b, ": "...)
+			b = append(b, v...)
+			b = append(b, "\r\n"...)
+		}
+	}
+
+	// End of header section.
+	return append(b, "\r\n"...)
+}
+
 // -----------------------------------------------------------------------------
 // chunkedWriter: mirror of chunked transfer encoding (writer side)
 // -----------------------------------------------------------------------------
@@ -116,6 +321,7 @@ func WriteResponse(ctx context.Context, w io.Writer, resp *Response) error {
 type chunkedWriter struct {
 	ctx context.Context
 	w   *bufio.Writer
+	sum hash.Hash // running checksum of the data, if requested
 }
 
 func newChunkedWriter(ctx context.Context, w *bufio.Writer) *chunkedWriter {
@@ -144,6 +350,9 @@ func (cw *chunkedWriter) Write(p []byte) (int, error) {
 
 	// data
 	n, err := cw.w.Write(p)
+	if cw.sum != nil {
+		cw.sum.Write(p[:n])
+	}
 	if err != nil {
 		return n, err
 	}
@@ -155,15 +364,135 @@ func (cw *chunkedWriter) Write(p []byte) (int, error) {
 	return n, nil
 }
 
+// chunkBufPool recycles ReadFrom buffers so streaming a body into a chunked
+// response does not allocate io.Copy's 32 KiB buffer per response.
+var chunkBufPool = sync.Pool{
+	New: func() any {
+		b := make([]byte, 16<<10)
+		return &b
+	},
+}
+
+// ReadFrom implements io.ReaderFrom: io.Copy into a chunkedWriter reads src
+// into a pooled buffer and emits one chunk per read.
+func (cw *chunkedWriter) ReadFrom(src io.Reader) (int64, error) {
+	bp := chunkBufPool.Get().(*[]byte)
+	defer chunkBufPool.Put(bp)
+	buf := *bp
+
+	var total int64
+	for {
+		n, rerr := src.Read(buf)
+		if n > 0 {
+			if _, err := cw.Write(buf[:n]); err != nil {
+				return total, err
+			}
+			total += int64(n)
+		}
+		if rerr == io.EOF {
+			return total, nil
+		}
+		if rerr != nil {
+			return total, rerr
+		}
+	}
+}
+
 // Close writes the terminating zero-sized chunk: "0\r\n\r\n".
 func (cw *chunkedWriter) Close() error {
+	return cw.closeWithTrailer(nil)
+}
+
+// closeWithTrailer writes the last chunk followed by the trailer fields.
+// Fields that are not valid to send are rejected before anything is
+// written.
+func (cw *chunkedWriter) closeWithTrailer(trailer Header) error {
 	select {
 	case <-cw.ctx.Done():
 		return cw.ctx.Err()
 	default:
 	}
-	if _, err := cw.w.WriteString("0\r\n\r\n"); err != nil {
+	if cw.sum != nil {
+		trailer = trailer.Clone()
+		if trailer == nil {
+			trailer = Header{}
+		}
+		trailer.Set(ChecksumTrailerField, base64.StdEncoding.EncodeToString(cw.sum.Sum(nil)))
+	}
+	if err := ValidateHeader(trailer, HeaderLimits{}); err != nil {
+		return err
+	}
+	if _, err := cw.w.WriteString("0\r\n"); err != nil {
 		return err
 	}
+	keys := make([]string, 0, len(trailer))
+	for k := range trailer {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range trailer[k] {
+			if _, err := cw.w.WriteString(k + ": " + v + "\r\n"); err != nil {
+				return err
+			}
+		}
+	}
+	_, err := cw.w.WriteString("\r\n")
+	return err
+}
+
+// -----------------------------------------------------------------------------
+// ContentLengthWriter: enforces a declared Content-Length (writer side)
+// -----------------------------------------------------------------------------
+
+// ErrContentLengthExceeded is returned when a write would exceed the
+// declared Content-Length.
+var ErrContentLengthExceeded = errors.New("httpx: write exceeds declared Content-Length")
+
+// ContentLengthWriter passes body bytes through to w while holding the
+// writer to a declared Content-Length. Writes beyond it are rejected
+// whole, so no stray bytes reach the wire to be misread as the next
+// response. Writing fewer bytes leaves the connection out of sync; Close
+// reports that and Broken then tells the server to close rather than
+// reuse the connection.
+type ContentLengthWriter struct {
+	w         io.Writer
+	remaining int64
+	broken    bool
+}
+
+// NewContentLengthWriter returns a writer that accepts exactly n bytes.
+func NewContentLengthWriter(w io.Writer, n int64) *ContentLengthWriter {
+	return &ContentLengthWriter{w: w, remaining: n}
+}
+
+// Write forwards p, or fails with ErrContentLengthExceeded without writing
+// anything if p does not fit in the remaining length.
+func (cw *ContentLengthWriter) Write(p []byte) (int, error) {
+	if int64(len(p)) > cw.remaining {
+		return 0, ErrContentLengthExceeded
+	}
+	n, err := cw.w.Write(p)
+	cw.remaining -= int64(n)
+	if err != nil {
+		cw.broken = true
+	}
+	return n, err
+}
+
+// Remaining returns how many declared bytes have not been written yet.
+func (cw *ContentLengthWriter) Remaining() int64 { return cw.remaining }
+
+// Close checks that the full declared length was written. If not, it marks
+// the writer broken and returns ErrLengthMismatch.
+func (cw *ContentLengthWriter) Close() error {
+	if cw.remaining != 0 {
+		cw.broken = true
+		return fmt.Errorf("%w: %d declared bytes not written", ErrLengthMismatch, cw.remaining)
+	}
 	return nil
 }
+
+// Broken reports whether the connection carrying this body can no longer
+// be reused: a write failed or the body ended short.
+func (cw *ContentLengthWriter) Broken() bool { return cw.broken }
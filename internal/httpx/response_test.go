@@ -1,9 +1,12 @@
 package httpx
 
 import (
+	"bufio"
 	"bytes"
 	"context"
+	"errors"
 	"io"
+	"strconv"
 	"strings"
 	"testing"
 )
@@ -107,30 +110,67 @@ func TestWriteChunkedResponse(t *testing.T) {
 func TestWriteUntilCloseResponse(t *testing.T) {
 	var buf bytes.Buffer
 
+	body := strings.Repeat("a", autoFrameSize+1)
 	resp := &Response{
-		Proto:      "HTTP/1.1",
+		Proto:      "HTTP/1.0",
 		StatusCode: 200,
 		Status:     "OK",
 		Header:     Header{},
-		Body:       strings.NewReader("abc"),
+		Body:       strings.NewReader(body),
 	}
 	resp.Header.Set("Content-Type", "text/plain")
-	// No Content-Length, no Transfer-Encoding => until-close
+	// No Content-Length, no Transfer-Encoding, too big to buffer and an
+	// HTTP/1.0 peer => until-close
 
 	if err := WriteResponse(context.Background(), &buf, resp); err != nil {
 		t.Fatal(err)
 	}
 
-	wantPrefix := "" +
-		"HTTP/1.1 200 OK\r\n" +
-		"Content-Type: text/plain\r\n" +
-		"\r\n"
 	got := buf.String()
-	if !strings.HasPrefix(got, wantPrefix) {
-		t.Fatalf("headers mismatch:\n--- got ---\n%q\n--- want prefix ---\n%q", got, wantPrefix)
+	head, rest, ok := strings.Cut(got, "\r\n\r\n")
+	if !ok || !strings.HasPrefix(head, "HTTP/1.0 200 OK\r\n") ||
+		!strings.Contains(head, "Connection: close") || strings.Contains(head, "Content-Length") {
+		t.Fatalf("headers mismatch: %q", head)
+	}
+	if rest != body {
+		t.Fatalf("body mismatch: got %d bytes, want %d", len(rest), len(body))
+	}
+}
+
+func TestWriteResponseAutoFraming(t *testing.T) {
+	// A small body gets a Content-Length.
+	var buf bytes.Buffer
+	resp := &Response{StatusCode: 200, Body: strings.NewReader("abc")}
+	if err := WriteResponse(context.Background(), &buf, resp); err != nil {
+		t.Fatal(err)
+	}
+	mustEqual(t, buf.String(), "HTTP/1.1 200 OK\r\nContent-Length: 3\r\n\r\nabc")
+
+	// A large one is chunked for HTTP/1.1 peers.
+	buf.Reset()
+	body := strings.Repeat("b", autoFrameSize+10)
+	resp = &Response{StatusCode: 200, Header: Header{}, Body: strings.NewReader(body)}
+	if err := WriteResponse(context.Background(), &buf, resp); err != nil {
+		t.Fatal(err)
+	}
+	head, rest, _ := strings.Cut(buf.String(), "\r\n\r\n")
+	if !strings.Contains(head, "Transfer-Encoding: chunked") {
+		t.Fatalf("expected chunked framing: %q", head)
+	}
+	decoded, err := io.ReadAll(newChunkedReader(context.Background(), strings.NewReader(rest), 0, Header{}))
+	if err != nil || string(decoded) != body {
+		t.Fatalf("chunked body mismatch: %d bytes, %v", len(decoded), err)
 	}
-	if got[len(wantPrefix):] != "abc" {
-		t.Fatalf("body mismatch: got %q, want %q", got[len(wantPrefix):], "abc")
+
+	// An HTTP/1.0 request rules out chunking.
+	buf.Reset()
+	req := &Request{requestLine: requestLine{Method: "GET", ProtoMajor: 1, ProtoMinor: 0}}
+	resp = &Response{StatusCode: 200, Header: Header{}, Body: strings.NewReader(body), Request: req}
+	if err := WriteResponse(context.Background(), &buf, resp); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "chunked") || !strings.Contains(buf.String(), "Connection: close") {
+		t.Fatalf("HTTP/1.0 request must get until-close framing")
 	}
 }
 
@@ -154,3 +194,170 @@ func TestContextCancelDuringWrite(t *testing.T) {
 		t.Fatalf("expected ctx.Err() to be non-nil")
 	}
 }
+
+func TestChunkedWriterReadFrom(t *testing.T) {
+	var out bytes.Buffer
+	bw := bufio.NewWriter(&out)
+	cw := newChunkedWriter(context.Background(), bw)
+
+	body := &splitReader{chunks: [][]byte{[]byte("Wiki"), []byte("pedia")}}
+	n, err := cw.ReadFrom(body)
+	if err != nil || n != 9 {
+		t.Fatalf("n=%d err=%v", n, err)
+	}
+	if err := cw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	bw.Flush()
+	mustEqual(t, out.String(), "4\r\nWiki\r\n5\r\npedia\r\n0\r\n\r\n")
+}
+
+func TestWriteFixedLengthLargeBody(t *testing.T) {
+	var buf bytes.Buffer
+	body := strings.Repeat("x", 40<<10) // spans several blocks
+	resp := &Response{StatusCode: 200, Status: "OK", Header: Header{}, Body: strings.NewReader(body)}
+	resp.Header.Set("Content-Length", strconv.Itoa(len(body)))
+
+	if err := WriteResponse(context.Background(), &buf, resp); err != nil {
+		t.Fatal(err)
+	}
+	want := "HTTP/1.1 200 OK\r\nContent-Length: 40960\r\n\r\n" + body
+	if buf.String() != want {
+		t.Fatalf("mismatch: got %d bytes, want %d", buf.Len(), len(want))
+	}
+}
+
+func TestWriteFixedLengthShortBodyWritesNothing(t *testing.T) {
+	var buf bytes.Buffer
+	resp := &Response{StatusCode: 200, Status: "OK", Header: Header{}, Body: strings.NewReader("abc")}
+	resp.Header.Set("Content-Length", "10")
+
+	if err := WriteResponse(context.Background(), &buf, resp); err != ErrLengthMismatch {
+		t.Fatalf("expected ErrLengthMismatch, got %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("head should not be sent for a short body, got %q", buf.String())
+	}
+}
+
+// countingWriter records the number of Write calls.
+type countingWriter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	c.writes++
+	return c.Buffer.Write(p)
+}
+
+func TestWriteChunkedCoalescesHead(t *testing.T) {
+	var cw countingWriter
+	resp := &Response{StatusCode: 200, Status: "OK", Header: Header{}, Body: strings.NewReader("abc")}
+	resp.Header.Set("Transfer-Encoding", "chunked")
+	if err := WriteResponse(context.Background(), &cw, resp); err != nil {
+		t.Fatal(err)
+	}
+	if cw.writes != 1 {
+		t.Fatalf("small chunked response took %d writes, want 1", cw.writes)
+	}
+}
+
+func TestWriteResponseHeadSuppressesBody(t *testing.T) {
+	head := &Request{requestLine: requestLine{Method: "HEAD"}}
+	for _, te := range []string{"", "chunked"} {
+		var buf bytes.Buffer
+		resp := &Response{
+			StatusCode: 200,
+			Status:     "OK",
+			Header:     Header{},
+			Body:       strings.NewReader("hello"),
+			Request:    head,
+		}
+		resp.Header.Set("Content-Type", "text/plain")
+		if te == "" {
+			resp.Header.Set("Content-Length", "5")
+		} else {
+			resp.Header.Set("Transfer-Encoding", te)
+		}
+		if err := WriteResponse(context.Background(), &buf, resp); err != nil {
+			t.Fatal(err)
+		}
+		got := buf.String()
+		if !strings.HasSuffix(got, "\r\n\r\n") || strings.Contains(got, "hello") {
+			t.Fatalf("HEAD response carried a body: %q", got)
+		}
+		if te == "" && !strings.Contains(got, "Content-Length: 5\r\n") {
+			t.Fatalf("Content-Length must be preserved for HEAD: %q", got)
+		}
+	}
+}
+
+func TestWriteResponseBodilessStatuses(t *testing.T) {
+	for _, code := range []int{100, 204, 304} {
+		var buf bytes.Buffer
+		resp := &Response{StatusCode: code, Status: "X", Header: Header{}}
+		resp.Header.Set("Content-Length", "10")
+		resp.Header.Set("Transfer-Encoding", "chunked")
+		resp.Header.Set("Etag", `"v1"`)
+		if err := WriteResponse(context.Background(), &buf, resp); err != nil {
+			t.Fatalf("%d: %v", code, err)
+		}
+		got := buf.String()
+		stripped := code != 304
+		if strings.Contains(got, "Content-Length") == stripped || strings.Contains(got, "Transfer-Encoding") == stripped {
+			t.Fatalf("%d: framing headers handled wrong: %q", code, got)
+		}
+		if !strings.Contains(got, "Etag: \"v1\"\r\n") || !strings.HasSuffix(got, "\r\n\r\n") {
+			t.Fatalf("%d: bad head: %q", code, got)
+		}
+	}
+}
+
+func TestWriteResponseBodyNotAllowed(t *testing.T) {
+	var buf bytes.Buffer
+	resp := &Response{StatusCode: 204, Header: Header{}, Body: strings.NewReader("oops")}
+	if err := WriteResponse(context.Background(), &buf, resp); !errors.Is(err, ErrBodyNotAllowed) {
+		t.Fatalf("expected ErrBodyNotAllowed, got %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("nothing should be written, got %q", buf.String())
+	}
+
+	// An empty body is harmless.
+	resp = &Response{StatusCode: 304, Header: Header{}, Body: strings.NewReader("")}
+	if err := WriteResponse(context.Background(), &buf, resp); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestContentLengthWriter(t *testing.T) {
+	var buf bytes.Buffer
+	cw := NewContentLengthWriter(&buf, 5)
+	if _, err := cw.Write([]byte("hel")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cw.Write([]byte("lo!")); !errors.Is(err, ErrContentLengthExceeded) {
+		t.Fatalf("expected ErrContentLengthExceeded, got %v", err)
+	}
+	if buf.String() != "hel" {
+		t.Fatalf("overflowing write must not reach the wire: %q", buf.String())
+	}
+	if _, err := cw.Write([]byte("lo")); err != nil {
+		t.Fatal(err)
+	}
+	if err := cw.Close(); err != nil || cw.Broken() {
+		t.Fatalf("complete body: %v broken=%v", err, cw.Broken())
+	}
+}
+
+func TestContentLengthWriterShort(t *testing.T) {
+	cw := NewContentLengthWriter(io.Discard, 10)
+	cw.Write([]byte("abc"))
+	if err := cw.Close(); !errors.Is(err, ErrLengthMismatch) {
+		t.Fatalf("expected ErrLengthMismatch, got %v", err)
+	}
+	if !cw.Broken() || cw.Remaining() != 7 {
+		t.Fatalf("short body should break the connection: broken=%v remaining=%d", cw.Broken(), cw.Remaining())
+	}
+}
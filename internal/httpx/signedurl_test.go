@@ -0,0 +1,61 @@
+package httpx
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignedURLRoundTrip(t *testing.T) {
+	key := []byte("secret")
+	now := time.Unix(1_700_000_000, 0)
+	u := &URL{Path: "/files/report.pdf", RawQuery: "user=42&v=1"}
+
+	su := SignURL(key, "GET", u, now.Add(time.Minute), "user")
+	if !strings.Contains(su.RawQuery, SignatureParam+"=") {
+		t.Fatalf("missing signature: %q", su.RawQuery)
+	}
+	if u.RawQuery != "user=42&v=1" {
+		t.Fatalf("SignURL mutated input: %q", u.RawQuery)
+	}
+	if err := VerifySignedURL(key, "GET", su, now); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+
+	// Unsigned params may change.
+	alt := *su
+	alt.RawQuery = strings.Replace(su.RawQuery, "v=1", "v=2", 1)
+	if err := VerifySignedURL(key, "GET", &alt, now); err != nil {
+		t.Fatalf("unsigned param change should verify: %v", err)
+	}
+}
+
+func TestSignedURLRejects(t *testing.T) {
+	key := []byte("secret")
+	now := time.Unix(1_700_000_000, 0)
+	su := SignURL(key, "GET", &URL{Path: "/a", RawQuery: "user=42"}, now.Add(time.Minute), "user")
+
+	tampered := *su
+	tampered.RawQuery = strings.Replace(su.RawQuery, "user=42", "user=43", 1)
+	cases := []struct {
+		name   string
+		key    []byte
+		method string
+		u      *URL
+		now    time.Time
+		want   error
+	}{
+		{"expired", key, "GET", su, now.Add(2 * time.Minute), ErrURLExpired},
+		{"wrong key", []byte("other"), "GET", su, now, ErrURLBadSignature},
+		{"wrong method", key, "DELETE", su, now, ErrURLBadSignature},
+		{"wrong path", key, "GET", &URL{Path: "/b", RawQuery: su.RawQuery}, now, ErrURLBadSignature},
+		{"signed param", key, "GET", &tampered, now, ErrURLBadSignature},
+		{"unsigned", key, "GET", &URL{Path: "/a"}, now, ErrURLNotSigned},
+	}
+	for _, c := range cases {
+		if err := VerifySignedURL(c.key, c.method, c.u, c.now); !errors.Is(err, c.want) {
+			t.Fatalf("%s: got %v, want %v", c.name, err, c.want)
+		}
+	}
+}
@@ -0,0 +1,314 @@
+package httpx
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Header fields set by HMACSigner.
+const (
+	SignedDateHeader        = "X-Date"           // signing time, SignedDateFormat
+	SignedContentHashHeader = "X-Content-Sha256" // hex SHA-256 of the body
+	SignedDateFormat        = "20060102T150405Z"
+)
+
+// HMACScheme is the Authorization scheme written by HMACSigner.
+const HMACScheme = "HMAC-SHA256"
+
+// ErrMissingSignedHeader indicates a header listed for signing that the
+// request does not carry.
+var ErrMissingSignedHeader = errors.New("httpx: signed header missing from request")
+
+// Signer signs outgoing requests, typically by setting Authorization.
+type Signer interface {
+	Sign(r *Request) error
+}
+
+// BodySHA256 returns the hex SHA-256 of r's body without consuming it: the
+// body is read through GetBody. A request without a body hashes as empty;
+// one whose body cannot be replayed returns ErrBodyNotRewindable.
+func BodySHA256(r *Request) (string, error) {
+	h := sha256.New()
+	switch {
+	case r.GetBody != nil:
+		body, err := r.GetBody()
+		if err != nil {
+			return "", err
+		}
+		defer body.Close()
+		if _, err := io.Copy(h, body); err != nil {
+			return "", err
+		}
+	case r.Body != nil:
+		return "", ErrBodyNotRewindable
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// CanonicalRequest renders the parts of r covered by a signature, one per
+// line:
+//
+//	METHOD
+//	/path
+//	a=1&b=2            (query pairs sorted, encoding kept as sent)
+//	host:example.com   (one line per signed header, lower-cased, sorted)
+//	x-date:...
+//	host;x-date        (the signed header list)
+//	<hex body hash>
+//
+// headers names the fields to include; "host" is taken from r.Host when
+// absent from the header map. Repeated fields are joined with ",". The
+// same function serves signers and verifiers, so both sides agree byte for
+// byte.
+func CanonicalRequest(r *Request, headers []string, bodyHash string) (string, error) {
+	var b strings.Builder
+	b.WriteString(r.Method)
+	b.WriteByte('\n')
+	path, query := "/", ""
+	if r.URL != nil {
+		path, query = r.URL.Path, r.URL.RawQuery
+	}
+	b.WriteString(path)
+	b.WriteByte('\n')
+
+	q := parseRawQuery(query)
+	sort.SliceStable(q, func(i, j int) bool {
+		if q[i].key != q[j].key {
+			return q[i].key < q[j].key
+		}
+		return q[i].value < q[j].value
+	})
+	b.WriteString(q.encode())
+	b.WriteByte('\n')
+
+	names := canonicalHeaderNames(headers)
+	for _, name := range names {
+		vals := r.Header.Values(name)
+		if name == "host" && len(vals) == 0 && r.Host != "" {
+			vals = []string{r.Host}
+		}
+		if len(vals) == 0 {
+			return "", fmt.Errorf("%w: %s", ErrMissingSignedHeader, name)
+		}
+		trimmed := make([]string, len(vals))
+		for i, v := range vals {
+			trimmed[i] = strings.Join(strings.Fields(v), " ")
+		}
+		b.WriteString(name + ":" + strings.Join(trimmed, ",") + "\n")
+	}
+	b.WriteString(strings.Join(names, ";"))
+	b.WriteByte('\n')
+	b.WriteString(bodyHash)
+	return b.String(), nil
+}
+
+// canonicalHeaderNames lower-cases, de-duplicates and sorts names.
+func canonicalHeaderNames(headers []string) []string {
+	seen := make(map[string]bool, len(headers))
+	names := make([]string, 0, len(headers))
+	for _, h := range headers {
+		h = strings.ToLower(strings.TrimSpace(h))
+		if h != "" && !seen[h] {
+			seen[h] = true
+			names = append(names, h)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HMACSigner is a reference Signer using a shared secret. It stamps the
+// request with X-Date and X-Content-Sha256, then sets
+//
+//	Authorization: HMAC-SHA256 keyId="...", headers="host;x-content-sha256;x-date", signature="<hex>"
+//
+// where the signature is HMAC-SHA256(Key, CanonicalRequest(...)).
+type HMACSigner struct {
+	KeyID string
+	Key   []byte
+
+	// Headers lists extra fields to sign in addition to host, x-date and
+	// x-content-sha256.
+	Headers []string
+
+	// Now returns the signing time; time.Now if nil.
+	Now func() time.Time
+}
+
+// Sign implements Signer. A KeyID holding control characters fails with
+// ErrInvalidValue before r is touched.
+func (s *HMACSigner) Sign(r *Request) error {
+	keyID, err := quoteString(s.KeyID)
+	if err != nil {
+		return err
+	}
+	hash, err := BodySHA256(r)
+	if err != nil {
+		return err
+	}
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+	if r.Header == nil {
+		r.Header = Header{}
+	}
+	r.Header.Set(SignedDateHeader, now().UTC().Format(SignedDateFormat))
+	r.Header.Set(SignedContentHashHeader, hash)
+
+	names := canonicalHeaderNames(append([]string{"host", SignedDateHeader, SignedContentHashHeader}, s.Headers...))
+	canon, err := CanonicalRequest(r, names, hash)
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Authorization", HMACScheme+` keyId=`+keyID+`, headers="`+strings.Join(names, ";")+
+		`", signature="`+hmacHex(s.Key, canon)+`"`)
+	return nil
+}
+
+func hmacHex(key []byte, msg string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(msg))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// -----------------------------------------------------------------------------
+// Verification
+// -----------------------------------------------------------------------------
+
+// DefaultSignatureSkew is how far X-Date may lie from the verifier's clock
+// when HMACVerifier.MaxSkew is zero.
+const DefaultSignatureSkew = 5 * time.Minute
+
+// Errors returned by HMACVerifier.Verify.
+var (
+	ErrSignatureMissing    = errors.New("httpx: request not signed")
+	ErrSignatureMalformed  = errors.New("httpx: malformed request signature")
+	ErrSignatureUnknownKey = errors.New("httpx: unknown signing key")
+	ErrSignaturePolicy     = errors.New("httpx: required component not signed")
+	ErrSignatureExpired    = errors.New("httpx: signature outside validity window")
+	ErrSignatureMismatch   = errors.New("httpx: signature mismatch")
+	ErrContentHashMismatch = errors.New("httpx: content hash mismatch")
+)
+
+// HMACVerifier checks requests signed by HMACSigner.
+type HMACVerifier struct {
+	// Key returns the secret for a key ID; ok is false for unknown IDs.
+	Key func(keyID string) (key []byte, ok bool)
+
+	// Required lists header fields that must be covered by the signature,
+	// beyond host, x-date and x-content-sha256 which always are.
+	Required []string
+
+	// MaxSkew bounds how far the signing time may be from now, in either
+	// direction; DefaultSignatureSkew if zero.
+	MaxSkew time.Duration
+
+	// MaxBodyBytes caps the body read to check its hash;
+	// DefaultMaxBodyBytes if zero.
+	MaxBodyBytes int64
+
+	// Now returns the current time; time.Now if nil.
+	Now func() time.Time
+}
+
+// Verify checks r's signature and returns the key ID that signed it. The
+// body is read in full to check X-Content-Sha256 and replaced with an
+// in-memory copy, so handlers can still read it.
+func (v *HMACVerifier) Verify(r *Request) (keyID string, err error) {
+	auth := r.Header.Get("Authorization")
+	scheme, rest, _ := strings.Cut(auth, " ")
+	if !strings.EqualFold(scheme, HMACScheme) {
+		return "", ErrSignatureMissing
+	}
+	p, err := parseAuthParams(rest)
+	if err != nil {
+		return "", ErrSignatureMalformed
+	}
+	keyID, sig := p["keyid"], p["signature"]
+	if keyID == "" || sig == "" || p["headers"] == "" {
+		return "", ErrSignatureMalformed
+	}
+	names := strings.Split(p["headers"], ";")
+	if got := canonicalHeaderNames(names); strings.Join(got, ";") != p["headers"] {
+		return "", fmt.Errorf("%w: header list not canonical", ErrSignatureMalformed)
+	}
+	signed := make(map[string]bool, len(names))
+	for _, n := range names {
+		signed[n] = true
+	}
+	for _, req := range canonicalHeaderNames(append([]string{"host", SignedDateHeader, SignedContentHashHeader}, v.Required...)) {
+		if !signed[req] {
+			return "", fmt.Errorf("%w: %s", ErrSignaturePolicy, req)
+		}
+	}
+	key, ok := v.Key(keyID)
+	if !ok {
+		return "", ErrSignatureUnknownKey
+	}
+
+	hash := r.Header.Get(SignedContentHashHeader)
+	canon, err := CanonicalRequest(r, names, hash)
+	if err != nil {
+		return "", err
+	}
+	if !hmac.Equal([]byte(hmacHex(key, canon)), []byte(strings.ToLower(sig))) {
+		return "", ErrSignatureMismatch
+	}
+
+	// The signature is authentic; now check it is current and that the
+	// body is the one that was signed.
+	signedAt, err := time.Parse(SignedDateFormat, r.Header.Get(SignedDateHeader))
+	if err != nil {
+		return "", ErrSignatureMalformed
+	}
+	now := time.Now
+	if v.Now != nil {
+		now = v.Now
+	}
+	skew := v.MaxSkew
+	if skew <= 0 {
+		skew = DefaultSignatureSkew
+	}
+	if d := now().Sub(signedAt); d > skew || d < -skew {
+		return "", ErrSignatureExpired
+	}
+	if err := v.checkBody(r, hash); err != nil {
+		return "", err
+	}
+	return keyID, nil
+}
+
+// checkBody hashes r's body against want and restores it for the handler.
+func (v *HMACVerifier) checkBody(r *Request, want string) error {
+	h := sha256.New()
+	if r.Body != nil {
+		max := v.MaxBodyBytes
+		if max <= 0 {
+			max = DefaultMaxBodyBytes
+		}
+		var buf bytes.Buffer
+		n, err := io.Copy(&buf, io.LimitReader(r.Body, max+1))
+		r.Body.Close()
+		if err != nil {
+			return err
+		}
+		if n > max {
+			return ErrBodyTooLarge
+		}
+		h.Write(buf.Bytes())
+		r.Body = io.NopCloser(&buf)
+	}
+	if !hmac.Equal([]byte(hex.EncodeToString(h.Sum(nil))), []byte(strings.ToLower(want))) {
+		return ErrContentHashMismatch
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCanonicalRequest(t *testing.T) {
+	r, _ := NewRequest(context.Background(), MethodGet, "http://Example.com/p?b=2&a=3&a=1", nil)
+	r.Header.Set("X-Date", "20260101T000000Z")
+	r.Header.Add("X-Multi", "a   b")
+	r.Header.Add("X-Multi", "c")
+
+	got, err := CanonicalRequest(r, []string{"X-Multi", "host", "x-date", "Host"}, "h")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "GET\n/p\na=1&a=3&b=2\nhost:example.com\nx-date:20260101T000000Z\nx-multi:a b,c\nhost;x-date;x-multi\nh"
+	if got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+	if _, err := CanonicalRequest(r, []string{"x-absent"}, "h"); !errors.Is(err, ErrMissingSignedHeader) {
+		t.Fatalf("got %v", err)
+	}
+}
+
+func TestBodySHA256(t *testing.T) {
+	r, _ := NewRequest(context.Background(), MethodPost, "http://h/", strings.NewReader("abc"))
+	got, err := BodySHA256(r)
+	if err != nil || got != "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad" {
+		t.Fatalf("%s %v", got, err)
+	}
+	if b, _ := io.ReadAll(r.Body); string(b) != "abc" {
+		t.Fatal("body consumed by hashing")
+	}
+
+	r.GetBody = nil
+	if _, err := BodySHA256(r); !errors.Is(err, ErrBodyNotRewindable) {
+		t.Fatalf("got %v", err)
+	}
+	r.Body = nil
+	if got, _ := BodySHA256(r); got != "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855" {
+		t.Fatal(got)
+	}
+}
+
+func TestHMACSigner(t *testing.T) {
+	s := &HMACSigner{
+		KeyID:   "k1",
+		Key:     []byte("secret"),
+		Headers: []string{"Content-Type"},
+		Now:     func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) },
+	}
+	r, _ := NewRequestWithJSON(context.Background(), MethodPost, "https://api.example.com/v1/x?z=1", map[string]int{"a": 1})
+	if err := s.Sign(r); err != nil {
+		t.Fatal(err)
+	}
+	if r.Header.Get(SignedDateHeader) != "20260102T030405Z" || len(r.Header.Get(SignedContentHashHeader)) != 64 {
+		t.Fatalf("stamps %v", r.Header)
+	}
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, `HMAC-SHA256 keyId="k1", headers="content-type;host;x-content-sha256;x-date", signature="`) {
+		t.Fatal(auth)
+	}
+
+	// Signing is deterministic for a fixed time, and the signature
+	// depends on the body.
+	r2, _ := NewRequestWithJSON(context.Background(), MethodPost, "https://api.example.com/v1/x?z=1", map[string]int{"a": 1})
+	s.Sign(r2)
+	r3, _ := NewRequestWithJSON(context.Background(), MethodPost, "https://api.example.com/v1/x?z=1", map[string]int{"a": 2})
+	s.Sign(r3)
+	if r2.Header.Get("Authorization") != auth || r3.Header.Get("Authorization") == auth {
+		t.Fatal("signature not bound to request content")
+	}
+}
+
+func TestHMACSignerKeyIDQuoting(t *testing.T) {
+	s := &HMACSigner{KeyID: `k"1\`, Key: []byte("secret")}
+	r, _ := NewRequestWithJSON(context.Background(), MethodPost, "https://api.example.com/x", nil)
+	if err := s.Sign(r); err != nil {
+		t.Fatal(err)
+	}
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, `HMAC-SHA256 keyId="k\"1\\", headers=`) {
+		t.Fatal(auth)
+	}
+	if _, err := (&HMACVerifier{Key: func(id string) ([]byte, bool) { return s.Key, id == s.KeyID }}).Verify(receivedCopy(t, r)); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+
+	s.KeyID = "k1\r\nX-Injected: 1"
+	r, _ = NewRequestWithJSON(context.Background(), MethodPost, "https://api.example.com/x", nil)
+	if err := s.Sign(r); !errors.Is(err, ErrInvalidValue) || r.Header.Get("Authorization") != "" {
+		t.Fatalf("CTL in key id: %v", err)
+	}
+}
+
+// receivedCopy simulates the server's view of a signed client request: Host
+// arrives as a header and the body is a plain stream.
+func receivedCopy(t *testing.T, r *Request) *Request {
+	t.Helper()
+	body := ""
+	if r.GetBody != nil {
+		rc, _ := r.GetBody()
+		b, _ := io.ReadAll(rc)
+		body = string(b)
+	}
+	s := &Request{requestLine: r.requestLine, URL: &URL{Path: r.URL.Path, RawQuery: r.URL.RawQuery}, Header: r.Header.Clone()}
+	s.Header.Set("Host", r.Host)
+	s.Body = io.NopCloser(strings.NewReader(body))
+	return s
+}
+
+func TestHMACVerifier(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	signer := &HMACSigner{KeyID: "k1", Key: []byte("secret"), Headers: []string{"content-type"}, Now: func() time.Time { return now }}
+	v := &HMACVerifier{
+		Key: func(id string) ([]byte, bool) {
+			if id == "k1" {
+				return []byte("secret"), true
+			}
+			return nil, false
+		},
+		Now: func() time.Time { return now.Add(time.Minute) },
+	}
+
+	r, _ := NewRequestWithJSON(context.Background(), MethodPost, "https://api.example.com/v1/x?z=1", map[string]int{"a": 1})
+	signer.Sign(r)
+
+	s := receivedCopy(t, r)
+	if id, err := v.Verify(s); err != nil || id != "k1" {
+		t.Fatalf("%q %v", id, err)
+	}
+	if b, _ := io.ReadAll(s.Body); string(b) != `{"a":1}` {
+		t.Fatalf("body not restored: %q", b)
+	}
+
+	cases := []struct {
+		name   string
+		mutate func(s *Request)
+		want   error
+	}{
+		{"unsigned", func(s *Request) { s.Header.Del("Authorization") }, ErrSignatureMissing},
+		{"tampered query", func(s *Request) { s.URL.RawQuery = "z=2" }, ErrSignatureMismatch},
+		{"tampered header", func(s *Request) { s.Header.Set("Content-Type", "text/plain") }, ErrSignatureMismatch},
+		{"tampered body", func(s *Request) { s.Body = io.NopCloser(strings.NewReader(`{"a":2}`)) }, ErrContentHashMismatch},
+		{"unknown key", func(s *Request) {
+			s.Header.Set("Authorization", strings.Replace(s.Header.Get("Authorization"), "k1", "k2", 1))
+		}, ErrSignatureUnknownKey},
+	}
+	for _, c := range cases {
+		s := receivedCopy(t, r)
+		c.mutate(s)
+		if _, err := v.Verify(s); !errors.Is(err, c.want) {
+			t.Fatalf("%s: got %v, want %v", c.name, err, c.want)
+		}
+	}
+
+	// Outside the skew window.
+	v.Now = func() time.Time { return now.Add(time.Hour) }
+	if _, err := v.Verify(receivedCopy(t, r)); !errors.Is(err, ErrSignatureExpired) {
+		t.Fatalf("got %v", err)
+	}
+	v.Now = func() time.Time { return now }
+
+	// Policy: a required header that the signer did not cover.
+	v.Required = []string{"X-Tenant"}
+	if _, err := v.Verify(receivedCopy(t, r)); !errors.Is(err, ErrSignaturePolicy) {
+		t.Fatalf("got %v", err)
+	}
+}
@@ -0,0 +1,44 @@
+package httpx
+
+import "testing"
+
+func TestDetectContentType(t *testing.T) {
+	cases := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"empty", []byte{}, "text/plain; charset=utf-8"},
+		{"binary", []byte{1, 2, 3}, "application/octet-stream"},
+		{"html doctype", []byte("<!DOCTYPE html><html>"), "text/html; charset=utf-8"},
+		{"html leading ws", []byte("  \n<HtMl><body>"), "text/html; charset=utf-8"},
+		{"html no terminator", []byte("<htmlx"), "text/plain; charset=utf-8"},
+		{"xml", []byte("\n<?xml version=\"1.0\"?>"), "text/xml; charset=utf-8"},
+		{"pdf", []byte("%PDF-1.7"), "application/pdf"},
+		{"png", []byte("\x89PNG\x0D\x0A\x1A\x0A"), "image/png"},
+		{"jpeg", []byte("\xFF\xD8\xFF\xE0"), "image/jpeg"},
+		{"gif", []byte("GIF89a..."), "image/gif"},
+		{"webp", []byte("RIFF\x00\x00\x00\x00WEBPVP8 "), "image/webp"},
+		{"gzip", []byte("\x1F\x8B\x08\x00"), "application/x-gzip"},
+		{"zip", []byte("PK\x03\x04"), "application/zip"},
+		{"utf8 bom", []byte("\xEF\xBB\xBFhello"), "text/plain; charset=utf-8"},
+		{"mp4", []byte("\x00\x00\x00\x18ftypmp42\x00\x00\x00\x00mp42isom"), "video/mp4"},
+		{"plain", []byte("hello world\n"), "text/plain; charset=utf-8"},
+	}
+	for _, c := range cases {
+		if got := DetectContentType(c.data); got != c.want {
+			t.Fatalf("%s: DetectContentType = %q, want %q", c.name, got, c.want)
+		}
+	}
+}
+
+func TestDetectContentTypeOnlySniffsPrefix(t *testing.T) {
+	data := make([]byte, 1024)
+	for i := range data {
+		data[i] = 'a'
+	}
+	data[600] = 0x00 // binary byte beyond the sniff window is ignored
+	if got := DetectContentType(data); got != "text/plain; charset=utf-8" {
+		t.Fatalf("got %q", got)
+	}
+}
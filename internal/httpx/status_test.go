@@ -0,0 +1,42 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestStatusText(t *testing.T) {
+	cases := map[int]string{
+		StatusOK:                  "OK",
+		StatusNotFound:            "Not Found",
+		StatusTeapot:              "I'm a teapot",
+		StatusInternalServerError: "Internal Server Error",
+		299:                       "",
+	}
+	for code, want := range cases {
+		if got := StatusText(code); got != want {
+			t.Fatalf("StatusText(%d) = %q, want %q", code, got, want)
+		}
+	}
+}
+
+func TestWriteResponseFillsReasonPhrase(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteResponse(context.Background(), &buf, &Response{StatusCode: StatusNotFound, Header: Header{}}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "HTTP/1.1 404 Not Found\r\n") {
+		t.Fatalf("bad status line: %q", buf.String())
+	}
+
+	// Unknown codes keep a well-formed status line.
+	buf.Reset()
+	if err := WriteResponse(context.Background(), &buf, &Response{StatusCode: 299, Header: Header{}}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "HTTP/1.1 299 299\r\n") {
+		t.Fatalf("bad status line: %q", buf.String())
+	}
+}
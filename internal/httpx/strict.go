@@ -0,0 +1,69 @@
+package httpx
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// Errors reported by the hardened parsing profile. Each wraps the general
+// error the lenient parser would report for the same class of input, so
+// existing errors.Is checks keep working.
+var (
+	ErrSpaceBeforeColon = fmt.Errorf("%w: whitespace between field name and colon", ErrInvalidFieldName)
+	ErrBareCR           = fmt.Errorf("%w: bare CR in message", ErrMalformedHeader)
+	ErrNULByte          = fmt.Errorf("%w: NUL byte in message", ErrMalformedHeader)
+	ErrChunkSizeSyntax  = fmt.Errorf("%w: chunk size is not plain hex", ErrBadChunk)
+	ErrChunkExtTooLong  = fmt.Errorf("%w: chunk extension too long", ErrBadChunk)
+	ErrTrailerTooLarge  = fmt.Errorf("%w: trailer section too large", ErrUnexpectedTrailer)
+)
+
+// HardenedParseLimits returns the hardened parsing profile: the bounded
+// defaults of ServerLimits, with Strict set so that inputs used for request
+// smuggling and desync (whitespace before a colon, bare CR, NUL bytes,
+// "+"-prefixed chunk sizes) are rejected rather than interpreted. Use it
+// on servers behind proxies whose parsers may disagree with this one.
+func HardenedParseLimits() ParseLimits {
+	lim := ServerLimits{}.ParseLimits()
+	lim.Strict = true
+	return lim
+}
+
+// checkStrictLine rejects a message-head line carrying a NUL or a CR. The
+// line terminator has already been trimmed, so any CR left is bare.
+func checkStrictLine(line []byte) error {
+	if bytes.IndexByte(line, 0) >= 0 {
+		return ErrNULByte
+	}
+	if bytes.IndexByte(line, '\r') >= 0 {
+		return ErrBareCR
+	}
+	return nil
+}
+
+// errChunkLineTooLong is returned by readChunkLine past its limit; callers
+// translate it to the error for the section being read.
+var errChunkLineTooLong = errors.New("httpx: chunk line too long")
+
+// readChunkLine reads one line including its terminator. When max is
+// positive, lines longer than max bytes fail without being buffered whole.
+func readChunkLine(r *bufio.Reader, max int) (string, error) {
+	if max <= 0 {
+		return r.ReadString('\n')
+	}
+	var buf []byte
+	for {
+		part, err := r.ReadSlice('\n')
+		if len(buf)+len(part) > max {
+			return "", errChunkLineTooLong
+		}
+		buf = append(buf, part...)
+		if err == nil {
+			return string(buf), nil
+		}
+		if !errors.Is(err, bufio.ErrBufferFull) {
+			return string(buf), err
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/andycostintoma/httpx/internal/netx"
+)
+
+func TestHardenedHead(t *testing.T) {
+	cases := []struct {
+		name string
+		raw  string
+		want error
+	}{
+		{"space before colon", "GET / HTTP/1.1\r\nHost : a\r\n\r\n", ErrSpaceBeforeColon},
+		{"tab before colon", "GET / HTTP/1.1\r\nHost\t: a\r\n\r\n", ErrSpaceBeforeColon},
+		{"bare CR in value", "GET / HTTP/1.1\r\nX: a\rb\r\n\r\n", ErrBareCR},
+		{"bare CR in request line", "GET /\r HTTP/1.1\r\n\r\n", ErrBareCR},
+		{"NUL in value", "GET / HTTP/1.1\r\nX: a\x00b\r\n\r\n", ErrNULByte},
+	}
+	for _, c := range cases {
+		_, err := ParseRequest(netx.NewCRLFFastReader(strings.NewReader(c.raw)), HardenedParseLimits())
+		if !errors.Is(err, c.want) {
+			t.Fatalf("%s: got %v, want %v", c.name, err, c.want)
+		}
+	}
+
+	// Whitespace before the colon is an invalid field name in either profile.
+	_, err := ParseRequest(netx.NewCRLFFastReader(strings.NewReader(cases[0].raw)), ParseLimits{MaxLineBytes: 1024})
+	if !errors.Is(err, ErrInvalidFieldName) {
+		t.Fatalf("lenient: got %v", err)
+	}
+	// The lenient profile still accepts NUL in values.
+	if _, err := ParseRequest(netx.NewCRLFFastReader(strings.NewReader(cases[4].raw)), ParseLimits{MaxLineBytes: 1024}); err != nil {
+		t.Fatalf("lenient: %v", err)
+	}
+}
+
+func TestStrictErrorsWrapLenient(t *testing.T) {
+	for _, c := range []struct{ err, base error }{
+		{ErrSpaceBeforeColon, ErrInvalidFieldName},
+		{ErrBareCR, ErrMalformedHeader},
+		{ErrNULByte, ErrMalformedHeader},
+		{ErrChunkSizeSyntax, ErrBadChunk},
+		{ErrChunkExtTooLong, ErrBadChunk},
+		{ErrTrailerTooLarge, ErrUnexpectedTrailer},
+	} {
+		if !errors.Is(c.err, c.base) {
+			t.Fatalf("%v does not wrap %v", c.err, c.base)
+		}
+	}
+}
+
+func TestHardenedChunked(t *testing.T) {
+	cases := []struct {
+		name string
+		body string
+		want error
+	}{
+		{"plus sign", "+3\r\nabc\r\n0\r\n\r\n", ErrChunkSizeSyntax},
+		{"leading space", " 3\r\nabc\r\n0\r\n\r\n", ErrChunkSizeSyntax},
+		{"hex prefix", "0x3\r\nabc\r\n0\r\n\r\n", ErrChunkSizeSyntax},
+		{"long extension", "3;" + strings.Repeat("a", 300) + "\r\nabc\r\n0\r\n\r\n", ErrChunkExtTooLong},
+		{"huge extension", "3;" + strings.Repeat("a", 1<<16) + "\r\nabc\r\n0\r\n\r\n", ErrChunkExtTooLong},
+		{"bare LF", "3\nabc\r\n0\r\n\r\n", ErrBadChunk},
+		{"large trailers", "0\r\n" + strings.Repeat("X-Pad: "+strings.Repeat("p", 1000)+"\r\n", 10) + "\r\n", ErrTrailerTooLarge},
+		{"trailer space before colon", "0\r\nX-T : v\r\n\r\n", ErrSpaceBeforeColon},
+	}
+	for _, c := range cases {
+		raw := "POST / HTTP/1.1\r\nHost: a\r\nTransfer-Encoding: chunked\r\n\r\n" + c.body
+		r := netx.NewCRLFFastReader(strings.NewReader(raw))
+		req, err := ParseRequest(r, HardenedParseLimits())
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		body, _, err := NewBodyReader(context.Background(), req, r, 1<<20)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.ReadAll(body); !errors.Is(err, c.want) {
+			t.Fatalf("%s: got %v, want %v", c.name, err, c.want)
+		}
+	}
+
+	// A well-formed chunked body with short extensions passes.
+	raw := "POST / HTTP/1.1\r\nTransfer-Encoding: chunked\r\n\r\n3 ;a=b\r\nabc\r\n0\r\nX-T: v\r\n\r\n"
+	r := netx.NewCRLFFastReader(strings.NewReader(raw))
+	req, err := ParseRequest(r, HardenedParseLimits())
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _, _ := NewBodyReader(context.Background(), req, r, 1<<20)
+	if b, err := io.ReadAll(body); err != nil || string(b) != "abc" {
+		t.Fatalf("got %q %v", b, err)
+	}
+}
@@ -0,0 +1,88 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultMaxTraceBytes bounds the echoed message when TraceOptions.MaxBytes
+// is zero.
+const DefaultMaxTraceBytes = 8 << 10
+
+// ErrTraceTooLarge is returned by WriteTrace when the echoed request would
+// exceed TraceOptions.MaxBytes. A 413 response has been written.
+var ErrTraceTooLarge = errors.New("httpx: TRACE message too large")
+
+// traceSensitive lists fields that are never echoed unless
+// TraceOptions.KeepSensitive is set: reflecting them lets scripts read
+// credentials they cannot otherwise see (cross-site tracing).
+var traceSensitive = []string{"Authorization", "Proxy-Authorization", "Cookie"}
+
+// TraceOptions configures WriteTrace.
+type TraceOptions struct {
+	MaxBytes      int  // cap on the echoed message; DefaultMaxTraceBytes if zero
+	KeepSensitive bool // echo Cookie and Authorization fields as received
+}
+
+// WriteTrace answers a TRACE request by echoing its request line and header
+// section as a message/http body (RFC 9110 §9.3.8). Request content is
+// never echoed.
+//
+// TRACE is deliberately absent from NewMethodRegistry; servers that want it
+// must Register(MethodTrace) explicitly.
+func WriteTrace(ctx context.Context, w io.Writer, r *Request, opts TraceOptions) error {
+	max := opts.MaxBytes
+	if max <= 0 {
+		max = DefaultMaxTraceBytes
+	}
+
+	h := r.Header.Clone()
+	if !opts.KeepSensitive {
+		for _, k := range traceSensitive {
+			h.Del(k)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(r.requestLine.String())
+	b.WriteString("\r\n")
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range h[k] {
+			b.WriteString(k)
+			b.WriteString(": ")
+			b.WriteString(v)
+			b.WriteString("\r\n")
+		}
+		if b.Len() > max {
+			break
+		}
+	}
+	b.WriteString("\r\n")
+
+	if b.Len() > max {
+		if err := writeBytes(ctx, w, StatusRequestEntityTooLarge, "text/plain; charset=utf-8",
+			[]byte("TRACE message too large\n")); err != nil {
+			return err
+		}
+		return ErrTraceTooLarge
+	}
+
+	out := Header{}
+	out.Set("Content-Type", "message/http")
+	out.Set("Content-Length", strconv.Itoa(b.Len()))
+	return WriteResponse(ctx, w, &Response{
+		StatusCode: StatusOK,
+		Header:     out,
+		Body:       strings.NewReader(b.String()),
+		Request:    r,
+	})
+}
@@ -0,0 +1,57 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/andycostintoma/httpx/internal/netx"
+)
+
+func parseTestRequest(t *testing.T, raw string) *Request {
+	t.Helper()
+	req, err := ParseRequest(netx.NewCRLFFastReader(strings.NewReader(raw)), ParseLimits{MaxLineBytes: 4096})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
+
+func TestWriteTrace(t *testing.T) {
+	r := parseTestRequest(t, "TRACE /x HTTP/1.1\r\nHost: ex.com\r\nCookie: s=1\r\nAuthorization: Basic Zm9v\r\nX-A: 1\r\n\r\n")
+	var buf bytes.Buffer
+	if err := WriteTrace(context.Background(), &buf, r, TraceOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	body := "TRACE /x HTTP/1.1\r\nHost: ex.com\r\nX-A: 1\r\n\r\n"
+	got := buf.String()
+	if !strings.HasPrefix(got, "HTTP/1.1 200 OK\r\n") || !strings.Contains(got, "Content-Type: message/http\r\n") ||
+		!strings.HasSuffix(got, "\r\n\r\n"+body) {
+		t.Fatalf("unexpected response %q", got)
+	}
+	if strings.Contains(got, "Cookie") || strings.Contains(got, "Zm9v") {
+		t.Fatalf("sensitive fields echoed: %q", got)
+	}
+
+	buf.Reset()
+	if err := WriteTrace(context.Background(), &buf, r, TraceOptions{KeepSensitive: true}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "Cookie: s=1\r\n") {
+		t.Fatalf("KeepSensitive should echo Cookie: %q", buf.String())
+	}
+}
+
+func TestWriteTraceTooLarge(t *testing.T) {
+	r := parseTestRequest(t, "TRACE / HTTP/1.1\r\nX-Big: "+strings.Repeat("a", 200)+"\r\n\r\n")
+	var buf bytes.Buffer
+	err := WriteTrace(context.Background(), &buf, r, TraceOptions{MaxBytes: 100})
+	if !errors.Is(err, ErrTraceTooLarge) {
+		t.Fatalf("expected ErrTraceTooLarge, got %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "HTTP/1.1 413 ") || strings.Contains(buf.String(), "aaaa") {
+		t.Fatalf("unexpected response %q", buf.String())
+	}
+}
@@ -20,19 +20,30 @@ type URL struct {
 //   - absolute-form: http://host/path?query
 //   - asterisk-form: * (for OPTIONS *)
 func ParseRequestURI(raw string) (*URL, error) {
+	u := &URL{}
+	if err := parseRequestURIInto(u, raw); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// parseRequestURIInto is ParseRequestURI writing into an existing URL,
+// so pooled requests can reuse their URL.
+func parseRequestURIInto(u *URL, raw string) error {
+	*u = URL{}
 	if raw == "" {
-		return nil, errors.New("empty request-target")
+		return errors.New("empty request-target")
 	}
 	if strings.ContainsAny(raw, " \r\n") {
-		return nil, errors.New("invalid characters in request-target")
+		return errors.New("invalid characters in request-target")
 	}
 
 	// OPTIONS * form
 	if raw == "*" {
-		return &URL{Path: "*"}, nil
+		u.Path = "*"
+		return nil
 	}
 
-	u := &URL{}
 	switch {
 	case strings.HasPrefix(raw, "http://"):
 		u.Scheme = "http"
@@ -41,7 +52,7 @@ func ParseRequestURI(raw string) (*URL, error) {
 		if slash == -1 {
 			u.Host = strings.ToLower(rest)
 			u.Path = "/"
-			return u, nil
+			return nil
 		}
 		u.Host = strings.ToLower(rest[:slash])
 		raw = rest[slash:]
@@ -53,7 +64,7 @@ func ParseRequestURI(raw string) (*URL, error) {
 		if slash == -1 {
 			u.Host = strings.ToLower(rest)
 			u.Path = "/"
-			return u, nil
+			return nil
 		}
 		u.Host = strings.ToLower(rest[:slash])
 		raw = rest[slash:]
@@ -73,5 +84,5 @@ func ParseRequestURI(raw string) (*URL, error) {
 	if u.Path == "" {
 		u.Path = "/"
 	}
-	return u, nil
+	return nil
 }
@@ -0,0 +1,103 @@
+package httpx
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// DefaultMaxViaHops is the hop count CheckVia allows when none is given.
+const DefaultMaxViaHops = 20
+
+var (
+	// ErrProxyLoop indicates a message that already passed through this
+	// proxy. Answer with 508 Loop Detected.
+	ErrProxyLoop = errors.New("httpx: proxy loop detected")
+	// ErrTooManyHops indicates a message that passed through more proxies
+	// than allowed. Answer with 502 Bad Gateway.
+	ErrTooManyHops = errors.New("httpx: too many proxy hops")
+)
+
+// ViaHop is one entry of a Via header (RFC 9110 §7.6.3).
+type ViaHop struct {
+	Protocol string // e.g. "1.1" or "HTTP/2.0"
+	By       string // host[:port] or pseudonym
+	Comment  string // without the parentheses
+}
+
+// ParseVia returns the hops listed in every Via field of h, oldest first.
+// Malformed entries are skipped.
+func ParseVia(h Header) []ViaHop {
+	var hops []ViaHop
+	for _, field := range h.Values("Via") {
+		for _, entry := range splitViaEntries(field) {
+			entry = strings.TrimSpace(entry)
+			proto, rest, ok := strings.Cut(entry, " ")
+			if !ok || proto == "" {
+				continue
+			}
+			rest = strings.TrimSpace(rest)
+			by, comment, _ := strings.Cut(rest, " ")
+			comment = strings.TrimSpace(comment)
+			if strings.HasPrefix(comment, "(") && strings.HasSuffix(comment, ")") {
+				comment = comment[1 : len(comment)-1]
+			}
+			hops = append(hops, ViaHop{Protocol: proto, By: by, Comment: comment})
+		}
+	}
+	return hops
+}
+
+// splitViaEntries splits a Via field on commas outside comments.
+func splitViaEntries(s string) []string {
+	var out []string
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			if depth > 0 {
+				depth--
+			}
+		case ',':
+			if depth == 0 {
+				out = append(out, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(out, s[start:])
+}
+
+// AppendVia records this proxy as the latest hop in h. The protocol is
+// written in the short form ("1.1") for HTTP, as RFC 9110 recommends.
+func AppendVia(h Header, protoMajor, protoMinor int, pseudonym string) {
+	entry := strconv.Itoa(protoMajor) + "." + strconv.Itoa(protoMinor) + " " + pseudonym
+	if prev := h.Values("Via"); len(prev) > 0 {
+		h.Set("Via", strings.Join(prev, ", ")+", "+entry)
+		return
+	}
+	h.Set("Via", entry)
+}
+
+// CheckVia guards a proxy against forwarding loops. It returns
+// ErrProxyLoop if pseudonym already appears in h's Via hops and
+// ErrTooManyHops if there are more than maxHops of them
+// (DefaultMaxViaHops when maxHops <= 0). Pseudonyms compare
+// case-insensitively.
+func CheckVia(h Header, pseudonym string, maxHops int) error {
+	if maxHops <= 0 {
+		maxHops = DefaultMaxViaHops
+	}
+	hops := ParseVia(h)
+	for _, hop := range hops {
+		if strings.EqualFold(hop.By, pseudonym) {
+			return ErrProxyLoop
+		}
+	}
+	if len(hops) > maxHops {
+		return ErrTooManyHops
+	}
+	return nil
+}
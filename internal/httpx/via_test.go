@@ -0,0 +1,56 @@
+package httpx
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseVia(t *testing.T) {
+	h := Header{}
+	h.Add("Via", "1.0 fred, 1.1 p.example.net (Apache, v2)")
+	h.Add("Via", "HTTP/2.0 edge:8443")
+	want := []ViaHop{
+		{"1.0", "fred", ""},
+		{"1.1", "p.example.net", "Apache, v2"},
+		{"HTTP/2.0", "edge:8443", ""},
+	}
+	if got := ParseVia(h); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v", got)
+	}
+}
+
+func TestAppendVia(t *testing.T) {
+	h := Header{}
+	AppendVia(h, 1, 1, "gw")
+	if got := h.Get("Via"); got != "1.1 gw" {
+		t.Fatal(got)
+	}
+	h.Add("Via", "1.0 other")
+	AppendVia(h, 1, 0, "gw2")
+	if got := h.Values("Via"); len(got) != 1 || got[0] != "1.1 gw, 1.0 other, 1.0 gw2" {
+		t.Fatal(got)
+	}
+}
+
+func TestCheckVia(t *testing.T) {
+	h := Header{}
+	if err := CheckVia(h, "gw", 0); err != nil {
+		t.Fatal(err)
+	}
+	AppendVia(h, 1, 1, "a")
+	AppendVia(h, 1, 1, "GW")
+	if err := CheckVia(h, "gw", 0); !errors.Is(err, ErrProxyLoop) {
+		t.Fatalf("got %v", err)
+	}
+
+	h = Header{}
+	h.Set("Via", strings.TrimSuffix(strings.Repeat("1.1 x, ", 4), ", "))
+	if err := CheckVia(h, "gw", 3); !errors.Is(err, ErrTooManyHops) {
+		t.Fatalf("got %v", err)
+	}
+	if err := CheckVia(h, "gw", 4); err != nil {
+		t.Fatal(err)
+	}
+}
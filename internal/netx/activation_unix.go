@@ -0,0 +1,85 @@
+//go:build unix
+
+package netx
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// systemdFD maps the i-th passed socket to its descriptor; systemd starts
+// at 3.
+func systemdFD(i int) int { return 3 + i }
+
+// ActivationListeners returns the listeners passed to the process by
+// systemd socket activation (LISTEN_PID, LISTEN_FDS), in order. It returns
+// nil when the process was not socket-activated. The variables are
+// removed from the environment so child processes do not inherit them,
+// which also means only the first call sees the sockets.
+//
+// Each listener can be served directly, or adapted with FromNetListener.
+func ActivationListeners() ([]net.Listener, error) {
+	ls, _, err := activationListeners(systemdFD)
+	return ls, err
+}
+
+// ActivationListenersByName is ActivationListeners grouped by the names
+// from LISTEN_FDNAMES (FileDescriptorName= in the .socket unit). Unnamed
+// sockets are grouped under "unknown", as systemd does.
+func ActivationListenersByName() (map[string][]net.Listener, error) {
+	ls, names, err := activationListeners(systemdFD)
+	if err != nil || ls == nil {
+		return nil, err
+	}
+	m := make(map[string][]net.Listener)
+	for i, l := range ls {
+		m[names[i]] = append(m[names[i]], l)
+	}
+	return m, nil
+}
+
+func activationListeners(fdAt func(i int) int) ([]net.Listener, []string, error) {
+	defer func() {
+		os.Unsetenv("LISTEN_PID")
+		os.Unsetenv("LISTEN_FDS")
+		os.Unsetenv("LISTEN_FDNAMES")
+	}()
+
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil, nil
+	}
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || n <= 0 {
+		return nil, nil, nil
+	}
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+
+	ls := make([]net.Listener, 0, n)
+	outNames := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		fd := fdAt(i)
+		syscall.CloseOnExec(fd)
+		name := "unknown"
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+		f := os.NewFile(uintptr(fd), name)
+		// FileListener dups the descriptor; the original is closed here.
+		l, err := net.FileListener(f)
+		f.Close()
+		if err != nil {
+			for _, l := range ls {
+				l.Close()
+			}
+			return nil, nil, fmt.Errorf("netx: activation fd %d: %w", fd, err)
+		}
+		ls = append(ls, l)
+		outNames = append(outNames, name)
+	}
+	return ls, outNames, nil
+}
@@ -0,0 +1,73 @@
+//go:build unix
+
+package netx
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"syscall"
+	"testing"
+)
+
+func TestActivationListenersNotActivated(t *testing.T) {
+	t.Setenv("LISTEN_PID", "1")
+	t.Setenv("LISTEN_FDS", "1")
+	ls, err := ActivationListeners()
+	if err != nil || ls != nil {
+		t.Fatalf("foreign LISTEN_PID should be ignored: %v %v", ls, err)
+	}
+	if _, ok := os.LookupEnv("LISTEN_FDS"); ok {
+		t.Fatal("activation variables should be cleared")
+	}
+}
+
+func TestActivationListeners(t *testing.T) {
+	// Simulate systemd by handing over two listening sockets' descriptors.
+	var fds []int
+	for i := 0; i < 2; i++ {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := ln.(*net.TCPListener).File()
+		if err != nil {
+			t.Fatal(err)
+		}
+		ln.Close()
+		// Hand over a private copy; activationListeners closes it.
+		fd, err := syscall.Dup(int(f.Fd()))
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		fds = append(fds, fd)
+	}
+
+	t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+	t.Setenv("LISTEN_FDS", "2")
+	t.Setenv("LISTEN_FDNAMES", "http:")
+	ls, names, err := activationListeners(func(i int) int { return fds[i] })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		for _, l := range ls {
+			l.Close()
+		}
+	}()
+	if len(ls) != 2 || names[0] != "http" || names[1] != "unknown" {
+		t.Fatalf("got %d listeners, names %v", len(ls), names)
+	}
+
+	go func() {
+		if c, err := ls[0].Accept(); err == nil {
+			c.Close()
+		}
+	}()
+	c, err := net.Dial("tcp", ls[0].Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Close()
+}
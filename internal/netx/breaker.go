@@ -0,0 +1,260 @@
+package netx
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is wrapped by every *CircuitOpenError.
+var ErrCircuitOpen = errors.New("netx: circuit open")
+
+// CircuitOpenError is returned while a key's circuit is open.
+type CircuitOpenError struct {
+	Key     string
+	RetryAt time.Time // when the next probe will be allowed
+}
+
+func (e *CircuitOpenError) Error() string { return ErrCircuitOpen.Error() + " for " + e.Key }
+func (e *CircuitOpenError) Unwrap() error { return ErrCircuitOpen }
+
+// BreakerState is the state of one key's circuit.
+type BreakerState int
+
+const (
+	BreakerClosed   BreakerState = iota // calls flow; outcomes are counted
+	BreakerOpen                         // calls fail fast until the open timeout passes
+	BreakerHalfOpen                     // one probe call decides whether to close again
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// Defaults for zero CircuitBreaker fields.
+const (
+	DefaultBreakerFailureRate  = 0.5
+	DefaultBreakerMinRequests  = 10
+	DefaultBreakerWindow       = 30 * time.Second
+	DefaultBreakerOpenDuration = 30 * time.Second
+)
+
+// CircuitBreaker tracks failures per key (typically a host) and fails
+// calls fast while that key looks down, so callers stop piling up on an
+// upstream that is not answering. The zero value is ready to use.
+type CircuitBreaker struct {
+	// FailureRate is the fraction of failed calls within Window that
+	// opens the circuit, once at least MinRequests calls were made.
+	FailureRate float64
+	MinRequests int
+	Window      time.Duration
+
+	// OpenDuration is how long an open circuit rejects calls before a
+	// single probe is let through.
+	OpenDuration time.Duration
+
+	// Now returns the current time; time.Now if nil.
+	Now func() time.Time
+
+	mu    sync.Mutex
+	state map[string]*breakerEntry
+}
+
+type breakerEntry struct {
+	state       BreakerState
+	windowStart time.Time
+	total, fail int
+	openUntil   time.Time
+	probing     bool
+	gen         uint64 // bumped on every state change
+}
+
+// setState moves e to state, invalidating calls admitted under the old one.
+func (e *breakerEntry) setState(state BreakerState) {
+	e.state = state
+	e.gen++
+}
+
+// breakerCall identifies one admitted call: the state generation it was
+// admitted under and whether it is the half-open probe. Outcomes from an
+// older generation are ignored.
+type breakerCall struct {
+	key   string
+	gen   uint64
+	probe bool
+}
+
+// Allow asks whether a call for key may proceed. If so, the caller must
+// report the outcome through done exactly once. If not, err is a
+// *CircuitOpenError.
+func (b *CircuitBreaker) Allow(key string) (done func(success bool), err error) {
+	c, err := b.admit(key)
+	if err != nil {
+		return nil, err
+	}
+	var once sync.Once
+	return func(success bool) {
+		once.Do(func() { b.record(c, success) })
+	}, nil
+}
+
+func (b *CircuitBreaker) admit(key string) (breakerCall, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.now()
+	e := b.entry(key, now)
+
+	switch e.state {
+	case BreakerOpen:
+		if now.Before(e.openUntil) {
+			return breakerCall{}, &CircuitOpenError{Key: key, RetryAt: e.openUntil}
+		}
+		e.setState(BreakerHalfOpen)
+		fallthrough
+	case BreakerHalfOpen:
+		if e.probing {
+			return breakerCall{}, &CircuitOpenError{Key: key, RetryAt: now.Add(b.openDuration())}
+		}
+		e.probing = true
+		return breakerCall{key: key, gen: e.gen, probe: true}, nil
+	}
+	return breakerCall{key: key, gen: e.gen}, nil
+}
+
+// State returns key's current state.
+func (b *CircuitBreaker) State(key string) BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	e, ok := b.state[key]
+	if !ok {
+		return BreakerClosed
+	}
+	if e.state == BreakerOpen && !b.now().Before(e.openUntil) {
+		return BreakerHalfOpen
+	}
+	return e.state
+}
+
+func (b *CircuitBreaker) record(c breakerCall, success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.now()
+	e := b.entry(c.key, now)
+	if e.gen != c.gen {
+		return // admitted under an earlier state; its outcome says nothing now
+	}
+
+	if c.probe {
+		e.probing = false
+		if success {
+			*e = breakerEntry{windowStart: now, gen: e.gen}
+			e.setState(BreakerClosed)
+		} else {
+			e.setState(BreakerOpen)
+			e.openUntil = now.Add(b.openDuration())
+		}
+		return
+	}
+	e.total++
+	if !success {
+		e.fail++
+	}
+	if e.total >= b.minRequests() && float64(e.fail)/float64(e.total) >= b.failureRate() {
+		e.setState(BreakerOpen)
+		e.openUntil = now.Add(b.openDuration())
+	}
+}
+
+// release gives back an admitted call without recording an outcome, so a
+// half-open circuit can admit another probe.
+func (b *CircuitBreaker) release(c breakerCall) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if e, ok := b.state[c.key]; ok && c.probe && e.gen == c.gen {
+		e.probing = false
+	}
+}
+
+// entry returns key's entry, starting a new counting window when the
+// current one has expired. b.mu must be held.
+func (b *CircuitBreaker) entry(key string, now time.Time) *breakerEntry {
+	if b.state == nil {
+		b.state = make(map[string]*breakerEntry)
+	}
+	e, ok := b.state[key]
+	if !ok {
+		e = &breakerEntry{windowStart: now}
+		b.state[key] = e
+	}
+	if e.state == BreakerClosed && now.Sub(e.windowStart) >= b.window() {
+		e.windowStart, e.total, e.fail = now, 0, 0
+	}
+	return e
+}
+
+// Dialer wraps dial so that each address gets its own circuit: failed
+// dials count against it, and while it is open dials fail fast with a
+// *CircuitOpenError. A dial cancelled by its own context is not counted.
+func (b *CircuitBreaker) Dialer(dial func(ctx context.Context, network, addr string) (net.Conn, error)) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		call, err := b.admit(addr)
+		if err != nil {
+			return nil, err
+		}
+		c, err := dial(ctx, network, addr)
+		if err != nil && ctx.Err() != nil {
+			b.release(call) // the caller gave up; say nothing about the upstream
+			return nil, err
+		}
+		b.record(call, err == nil)
+		return c, err
+	}
+}
+
+func (b *CircuitBreaker) now() time.Time {
+	if b.Now != nil {
+		return b.Now()
+	}
+	return time.Now()
+}
+
+func (b *CircuitBreaker) failureRate() float64 {
+	if b.FailureRate > 0 {
+		return b.FailureRate
+	}
+	return DefaultBreakerFailureRate
+}
+
+func (b *CircuitBreaker) minRequests() int {
+	if b.MinRequests > 0 {
+		return b.MinRequests
+	}
+	return DefaultBreakerMinRequests
+}
+
+func (b *CircuitBreaker) window() time.Duration {
+	if b.Window > 0 {
+		return b.Window
+	}
+	return DefaultBreakerWindow
+}
+
+func (b *CircuitBreaker) openDuration() time.Duration {
+	if b.OpenDuration > 0 {
+		return b.OpenDuration
+	}
+	return DefaultBreakerOpenDuration
+}
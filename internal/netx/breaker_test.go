@@ -0,0 +1,139 @@
+package netx
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerTrips(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := &CircuitBreaker{MinRequests: 4, OpenDuration: time.Minute, Now: func() time.Time { return now }}
+
+	call := func(ok bool) error {
+		done, err := b.Allow("h")
+		if err != nil {
+			return err
+		}
+		done(ok)
+		return nil
+	}
+	// 2 of 4 failures reaches the default 50% rate.
+	for _, ok := range []bool{true, false, true, false} {
+		if err := call(ok); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if b.State("h") != BreakerOpen {
+		t.Fatalf("state %v", b.State("h"))
+	}
+	var coe *CircuitOpenError
+	if err := call(true); !errors.As(err, &coe) || !errors.Is(err, ErrCircuitOpen) || !coe.RetryAt.Equal(now.Add(time.Minute)) {
+		t.Fatalf("got %v", err)
+	}
+	if b.State("other") != BreakerClosed {
+		t.Fatal("circuits must be per key")
+	}
+
+	// After the open duration a single probe is admitted.
+	now = now.Add(time.Minute)
+	if b.State("h") != BreakerHalfOpen {
+		t.Fatalf("state %v", b.State("h"))
+	}
+	probe, err := b.Allow("h")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Allow("h"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatal("second concurrent probe admitted")
+	}
+	probe(false)
+	if b.State("h") != BreakerOpen {
+		t.Fatal("failed probe must reopen")
+	}
+
+	now = now.Add(time.Minute)
+	if err := call(true); err != nil {
+		t.Fatal(err)
+	}
+	if b.State("h") != BreakerClosed {
+		t.Fatal("successful probe must close")
+	}
+}
+
+func TestCircuitBreakerStaleOutcome(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := &CircuitBreaker{MinRequests: 1, OpenDuration: time.Minute, Now: func() time.Time { return now }}
+
+	slow, err := b.Allow("h") // admitted while closed, finishes much later
+	if err != nil {
+		t.Fatal(err)
+	}
+	staleDial, _ := b.admit("h")
+	done, _ := b.Allow("h")
+	done(false)
+	if b.State("h") != BreakerOpen {
+		t.Fatalf("state %v", b.State("h"))
+	}
+
+	now = now.Add(time.Minute)
+	probe, err := b.Allow("h")
+	if err != nil {
+		t.Fatal(err)
+	}
+	slow(true)
+	if b.State("h") != BreakerHalfOpen {
+		t.Fatalf("stale success changed a half-open circuit: %v", b.State("h"))
+	}
+	b.release(staleDial)
+	if _, err := b.Allow("h"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatal("releasing a non-probe call admitted a second probe")
+	}
+	probe(false)
+	if b.State("h") != BreakerOpen {
+		t.Fatal("failed probe must reopen")
+	}
+}
+
+func TestCircuitBreakerWindow(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := &CircuitBreaker{MinRequests: 2, Window: time.Second, Now: func() time.Time { return now }}
+	done, _ := b.Allow("h")
+	done(false)
+	now = now.Add(2 * time.Second)
+	done, _ = b.Allow("h")
+	done(true)
+	if b.State("h") != BreakerClosed {
+		t.Fatal("failures from an expired window must not count")
+	}
+}
+
+func TestCircuitBreakerDialer(t *testing.T) {
+	refused := errors.New("refused")
+	calls := 0
+	b := &CircuitBreaker{MinRequests: 2}
+	dial := b.Dialer(func(ctx context.Context, network, addr string) (net.Conn, error) {
+		calls++
+		return nil, refused
+	})
+	for i := 0; i < 2; i++ {
+		if _, err := dial(context.Background(), "tcp", "a:1"); !errors.Is(err, refused) {
+			t.Fatal(err)
+		}
+	}
+	if _, err := dial(context.Background(), "tcp", "a:1"); !errors.Is(err, ErrCircuitOpen) || calls != 2 {
+		t.Fatalf("got %v after %d calls", err, calls)
+	}
+
+	// Cancelled dials are not held against the upstream.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for i := 0; i < 3; i++ {
+		dial(ctx, "tcp", "b:1")
+	}
+	if b.State("b:1") != BreakerClosed {
+		t.Fatal("cancelled dials tripped the breaker")
+	}
+}
@@ -0,0 +1,99 @@
+package netx
+
+import (
+	"context"
+	"crypto/tls"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CertificateProvider supplies the certificate for each TLS handshake. Its
+// method matches tls.Config.GetCertificate, so a provider can be installed
+// with cfg.GetCertificate = p.GetCertificate.
+type CertificateProvider interface {
+	GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error)
+}
+
+// FileCertificate serves a certificate loaded from PEM files and reloads it
+// when the files change, e.g. after a certbot renewal. Handshakes in progress
+// and established connections keep the certificate they started with.
+//
+// Reload can be called on demand (say, on SIGHUP); Watch polls.
+type FileCertificate struct {
+	CertFile string
+	KeyFile  string
+
+	cert atomic.Pointer[tls.Certificate]
+
+	mu              sync.Mutex // serializes reloads
+	certMod, keyMod time.Time
+}
+
+var _ CertificateProvider = (*FileCertificate)(nil)
+
+// NewFileCertificate loads the key pair and returns a provider for it.
+func NewFileCertificate(certFile, keyFile string) (*FileCertificate, error) {
+	f := &FileCertificate{CertFile: certFile, KeyFile: keyFile}
+	if _, err := f.reload(true); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// GetCertificate returns the current certificate.
+func (f *FileCertificate) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return f.cert.Load(), nil
+}
+
+// Reload re-reads the files if either modification time changed and
+// reports whether a new certificate was installed. On error the previous
+// certificate stays in use.
+func (f *FileCertificate) Reload() (bool, error) {
+	return f.reload(false)
+}
+
+func (f *FileCertificate) reload(force bool) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	cst, err := os.Stat(f.CertFile)
+	if err != nil {
+		return false, err
+	}
+	kst, err := os.Stat(f.KeyFile)
+	if err != nil {
+		return false, err
+	}
+	if !force && cst.ModTime().Equal(f.certMod) && kst.ModTime().Equal(f.keyMod) {
+		return false, nil
+	}
+	cert, err := tls.LoadX509KeyPair(f.CertFile, f.KeyFile)
+	if err != nil {
+		// Renewal tools write the two files separately; a mismatched
+		// pair is retried on the next call.
+		return false, err
+	}
+	f.cert.Store(&cert)
+	f.certMod, f.keyMod = cst.ModTime(), kst.ModTime()
+	return true, nil
+}
+
+// Watch calls Reload every interval until ctx is done. Reload errors are
+// passed to onError when it is non-nil and otherwise ignored, so a
+// half-written renewal never stops the watcher.
+func (f *FileCertificate) Watch(ctx context.Context, interval time.Duration, onError func(error)) error {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+			if _, err := f.Reload(); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package netx
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeKeyPair(t *testing.T, dir string, mod time.Time) *x509.Certificate {
+	t.Helper()
+	tlsCert, cert := selfSignedCert(t)
+	keyDER, err := x509.MarshalPKCS8PrivateKey(tlsCert.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPath, keyPath := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	os.Chtimes(certPath, mod, mod)
+	os.Chtimes(keyPath, mod, mod)
+	return cert
+}
+
+func leafOf(t *testing.T, f *FileCertificate) []byte {
+	t.Helper()
+	c, err := f.GetCertificate(&tls.ClientHelloInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c.Certificate[0]
+}
+
+func TestFileCertificateReload(t *testing.T) {
+	dir := t.TempDir()
+	first := writeKeyPair(t, dir, time.Unix(1000, 0))
+	f, err := NewFileCertificate(filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(leafOf(t, f)) != string(first.Raw) {
+		t.Fatal("initial certificate not served")
+	}
+
+	if changed, err := f.Reload(); changed || err != nil {
+		t.Fatalf("unchanged files should not reload: %v %v", changed, err)
+	}
+
+	second := writeKeyPair(t, dir, time.Unix(2000, 0))
+	if changed, err := f.Reload(); !changed || err != nil {
+		t.Fatalf("expected reload: %v %v", changed, err)
+	}
+	if string(leafOf(t, f)) != string(second.Raw) {
+		t.Fatal("renewed certificate not served")
+	}
+}
+
+func TestFileCertificateKeepsOldOnError(t *testing.T) {
+	dir := t.TempDir()
+	first := writeKeyPair(t, dir, time.Unix(1000, 0))
+	f, err := NewFileCertificate(filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A half-finished renewal: new certificate, old key.
+	_, other := selfSignedCert(t)
+	certPath := filepath.Join(dir, "cert.pem")
+	os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: other.Raw}), 0o600)
+	os.Chtimes(certPath, time.Unix(3000, 0), time.Unix(3000, 0))
+
+	if _, err := f.Reload(); err == nil {
+		t.Fatal("mismatched key pair should fail to load")
+	}
+	if string(leafOf(t, f)) != string(first.Raw) {
+		t.Fatal("previous certificate should stay in use")
+	}
+}
+
+func TestNewFileCertificateMissing(t *testing.T) {
+	if _, err := NewFileCertificate("/nonexistent/cert.pem", "/nonexistent/key.pem"); err == nil {
+		t.Fatal("expected error for missing files")
+	}
+}
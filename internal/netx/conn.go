@@ -0,0 +1,92 @@
+package netx
+
+import (
+	"errors"
+	"io"
+	"net"
+	"time"
+)
+
+// Conn is the minimal stream abstraction httpx serves over. Any net.Conn
+// satisfies it, but so can QUIC streams, SSH channels or tunnel endpoints
+// that provide ordered, reliable byte streams.
+type Conn interface {
+	io.ReadWriteCloser
+
+	// SetDeadline sets read and write deadlines. Transports that cannot
+	// honor deadlines may return ErrDeadlineUnsupported.
+	SetDeadline(t time.Time) error
+	SetReadDeadline(t time.Time) error
+	SetWriteDeadline(t time.Time) error
+
+	// LocalAddr and RemoteAddr identify the endpoints. Either may be nil
+	// when the transport has no meaningful address.
+	LocalAddr() net.Addr
+	RemoteAddr() net.Addr
+}
+
+// Listener yields Conns. net.Listener can be adapted with FromNetListener.
+type Listener interface {
+	Accept() (Conn, error)
+	Close() error
+	Addr() net.Addr
+}
+
+// ErrDeadlineUnsupported is returned by Conns whose transport has no deadlines.
+var ErrDeadlineUnsupported = errors.New("netx: deadlines not supported by transport")
+
+// FromNetListener adapts a net.Listener to Listener.
+func FromNetListener(l net.Listener) Listener {
+	return netListener{l}
+}
+
+type netListener struct{ l net.Listener }
+
+func (n netListener) Accept() (Conn, error) { return n.l.Accept() }
+func (n netListener) Close() error          { return n.l.Close() }
+func (n netListener) Addr() net.Addr        { return n.l.Addr() }
+
+// StreamAddr is a net.Addr for transports without network addresses.
+type StreamAddr struct {
+	Net  string // e.g. "quic", "ssh"
+	Name string // transport-specific identifier
+}
+
+func (a StreamAddr) Network() string { return a.Net }
+func (a StreamAddr) String() string  { return a.Name }
+
+// NewStreamConn wraps rwc as a Conn with the given endpoint metadata.
+// Deadline calls are forwarded when rwc implements them and otherwise
+// return ErrDeadlineUnsupported.
+func NewStreamConn(rwc io.ReadWriteCloser, local, remote net.Addr) Conn {
+	return &streamConn{ReadWriteCloser: rwc, local: local, remote: remote}
+}
+
+type streamConn struct {
+	io.ReadWriteCloser
+	local, remote net.Addr
+}
+
+func (c *streamConn) LocalAddr() net.Addr  { return c.local }
+func (c *streamConn) RemoteAddr() net.Addr { return c.remote }
+
+func (c *streamConn) SetDeadline(t time.Time) error {
+	if d, ok := c.ReadWriteCloser.(interface{ SetDeadline(time.Time) error }); ok {
+		return d.SetDeadline(t)
+	}
+	return ErrDeadlineUnsupported
+}
+
+func (c *streamConn) SetReadDeadline(t time.Time) error {
+	if d, ok := c.ReadWriteCloser.(interface{ SetReadDeadline(time.Time) error }); ok {
+		return d.SetReadDeadline(t)
+	}
+	return ErrDeadlineUnsupported
+}
+
+func (c *streamConn) SetWriteDeadline(t time.Time) error {
+	if d, ok := c.ReadWriteCloser.(interface{ SetWriteDeadline(time.Time) error }); ok {
+		return d.SetWriteDeadline(t)
+	}
+	return ErrDeadlineUnsupported
+}
@@ -0,0 +1,67 @@
+package netx
+
+import (
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// Compile-time check: every net.Conn is a Conn.
+var _ Conn = (net.Conn)(nil)
+
+type rwc struct {
+	io.Reader
+	io.Writer
+}
+
+func (rwc) Close() error { return nil }
+
+func TestStreamConnMetadataAndDeadlines(t *testing.T) {
+	local := StreamAddr{Net: "ssh", Name: "chan-1"}
+	c := NewStreamConn(rwc{}, local, nil)
+	if c.LocalAddr() != local || c.LocalAddr().Network() != "ssh" {
+		t.Fatalf("LocalAddr = %v", c.LocalAddr())
+	}
+	if err := c.SetDeadline(time.Now()); !errors.Is(err, ErrDeadlineUnsupported) {
+		t.Fatalf("expected ErrDeadlineUnsupported, got %v", err)
+	}
+
+	a, b := net.Pipe()
+	defer a.Close()
+	defer b.Close()
+	pc := NewStreamConn(a, nil, nil)
+	if err := pc.SetReadDeadline(time.Now().Add(-time.Second)); err != nil {
+		t.Fatalf("deadline should be forwarded: %v", err)
+	}
+	if _, err := pc.Read(make([]byte, 1)); err == nil {
+		t.Fatal("expected timeout from forwarded deadline")
+	}
+}
+
+func TestFromNetListener(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := FromNetListener(ln)
+	defer l.Close()
+
+	go func() {
+		c, err := net.Dial("tcp", l.Addr().String())
+		if err == nil {
+			c.Write([]byte("x"))
+			c.Close()
+		}
+	}()
+	c, err := l.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	buf := make([]byte, 1)
+	if _, err := io.ReadFull(c, buf); err != nil || buf[0] != 'x' {
+		t.Fatalf("read %q %v", buf, err)
+	}
+}
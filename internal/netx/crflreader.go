@@ -2,8 +2,14 @@ package netx
 
 import (
 	"bufio"
+	"bytes"
+	"context"
 	"errors"
+	"fmt"
 	"io"
+	"os"
+	"sync"
+	"time"
 )
 
 // ErrLineTooLong indicates that a line exceeded the configured maximum length.
@@ -15,36 +21,88 @@ var ErrPeekBeyondCap = errors.New("crlf: peek beyond internal capacity")
 // DefaultBufSize defines the buffer size used by NewCRLFFastReader.
 const DefaultBufSize = 4096
 
+// DefaultMaxPeek is the default cap on how far PeekAtLeast may grow the
+// buffer.
+const DefaultMaxPeek = 64 << 10
+
 // CRLFFastReader provides efficient, safe CRLF line reading semantics for HTTP parsing.
 // It behaves similarly to net/textproto.Reader, enforcing hard caps and RFC-compliant trimming.
 type CRLFFastReader struct {
 	br      *bufio.Reader // buffered source for efficient small reads
+	src     io.Reader     // what br reads from, kept to rebuild br on growth
 	bufSize int           // internal buffer size (for bounds checks)
+	maxPeek int           // cap for PeekAtLeast; DefaultMaxPeek if zero
+	off     int64         // bytes consumed since construction or Reset
+	lines   int64         // newlines consumed since construction or Reset
+	watch   *readWatch    // context interrupting reads, see WatchContext
 }
 
 // NewCRLFFastReader wraps r with a buffered reader of DefaultBufSize.
 func NewCRLFFastReader(r io.Reader) *CRLFFastReader {
-	return &CRLFFastReader{
-		br:      bufio.NewReaderSize(r, DefaultBufSize),
-		bufSize: DefaultBufSize,
-	}
+	return NewCRLFFastReaderSize(r, DefaultBufSize)
 }
 
-// Reset allows reusing the reader with a new underlying source.
+// NewCRLFFastReaderSize wraps r with a buffered reader of at least size bytes.
+// The buffer size also bounds Peek.
+func NewCRLFFastReaderSize(r io.Reader, size int) *CRLFFastReader {
+	cr := &CRLFFastReader{src: r}
+	cr.br = bufio.NewReaderSize(sourceReader{cr}, size)
+	cr.bufSize = cr.br.Size()
+	return cr
+}
+
+// Reset allows reusing the reader with a new underlying source. It drops
+// any watched context and the SetMaxPeek cap along with the position.
 func (r *CRLFFastReader) Reset(src io.Reader) {
+	r.off, r.lines = 0, 0
+	r.src = src
+	r.watch = nil
+	r.maxPeek = 0
 	if r.br == nil {
-		r.br = bufio.NewReaderSize(src, DefaultBufSize)
+		r.br = bufio.NewReaderSize(sourceReader{r}, DefaultBufSize)
 		r.bufSize = DefaultBufSize
 		return
 	}
-	r.br.Reset(src)
+	r.br.Reset(sourceReader{r})
+}
+
+// sourceReader feeds the buffer from the reader's current source, so
+// errors from reads interrupted by WatchContext can be reported as such.
+type sourceReader struct{ r *CRLFFastReader }
+
+func (s sourceReader) Read(p []byte) (int, error) {
+	n, err := s.r.src.Read(p)
+	if err != nil && s.r.watch != nil {
+		err = s.r.watch.mapErr(err)
+	}
+	return n, err
 }
 
+// Offset returns the number of bytes consumed from the source since the
+// reader was created or Reset, by line reads and Read alike. Peeked bytes
+// are not counted.
+func (r *CRLFFastReader) Offset() int64 { return r.off }
+
+// Line returns the 1-based number of the line the next byte belongs to.
+func (r *CRLFFastReader) Line() int64 { return r.lines + 1 }
+
+// consume accounts for p having been taken from the buffer.
+func (r *CRLFFastReader) consume(p []byte) {
+	r.off += int64(len(p))
+	r.lines += int64(bytes.Count(p, newline))
+}
+
+var newline = []byte{'\n'}
+
 // ReadLine reads a single logical line, trimming the trailing CRLF or LF.
 //
 // It enforces a maximum total line length (max). If the accumulated line exceeds
 // that limit, it returns ErrLineTooLong. The isPrefix flag mirrors bufio.Reader.ReadLine
 // semantics: true means the internal buffer filled before a newline was found.
+//
+// When the whole line fits in the internal buffer, the returned slice aliases
+// it to avoid a copy; it is then valid only until the next read or peek.
+// Callers that retain the line must copy it.
 func (r *CRLFFastReader) ReadLine(max int) (line []byte, isPrefix bool, err error) {
 	if max <= 0 {
 		return nil, false, errors.New("crlf: invalid max value")
@@ -53,23 +111,21 @@ func (r *CRLFFastReader) ReadLine(max int) (line []byte, isPrefix bool, err erro
 	var buf []byte
 	for {
 		part, perr := r.br.ReadSlice('\n')
+		r.consume(part)
 		// enforce limit before appending large chunks
 		if len(buf)+len(part) > max {
 			return nil, true, ErrLineTooLong
 		}
+		if perr == nil && buf == nil {
+			// fast path: complete line already buffered, no copy needed
+			return trimEOL(part), false, nil
+		}
 		buf = append(buf, part...)
 
 		switch {
 		case perr == nil:
 			// found newline
-			n := len(buf)
-			if n > 0 && buf[n-1] == '\n' {
-				n--
-				if n > 0 && buf[n-1] == '\r' {
-					n--
-				}
-			}
-			return buf[:n], false, nil
+			return trimEOL(buf), false, nil
 
 		case errors.Is(perr, bufio.ErrBufferFull):
 			// continue accumulating until newline found or max exceeded
@@ -87,11 +143,82 @@ func (r *CRLFFastReader) ReadLine(max int) (line []byte, isPrefix bool, err erro
 	}
 }
 
+// ReadContinuedLine reads a logical line like ReadLine, then unfolds any
+// obs-fold continuation lines (RFC 7230 §3.2.4): each following line that
+// begins with SP or HTAB is appended to the first, with the surrounding
+// whitespace replaced by a single SP. An empty line is never continued.
+//
+// max bounds the total length of the unfolded line.
+func (r *CRLFFastReader) ReadContinuedLine(max int) ([]byte, error) {
+	line, _, err := r.ReadLine(max)
+	if err != nil || len(line) == 0 {
+		return line, err
+	}
+	// Peek below may shift the internal buffer that line aliases.
+	line = append([]byte(nil), line...)
+	for {
+		next, perr := r.br.Peek(1)
+		if perr != nil || (next[0] != ' ' && next[0] != '\t') {
+			// Errors surface on the next read, not as part of this line.
+			return line, nil
+		}
+		budget := max - len(line) - 1
+		if budget <= 0 {
+			return nil, ErrLineTooLong
+		}
+		cont, _, err := r.ReadLine(budget)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		line = append(trimRightWS(line), ' ')
+		line = append(line, trimWS(cont)...)
+		if err != nil {
+			return line, err
+		}
+	}
+}
+
+// trimEOL drops a trailing LF or CRLF.
+func trimEOL(b []byte) []byte {
+	n := len(b)
+	if n > 0 && b[n-1] == '\n' {
+		n--
+		if n > 0 && b[n-1] == '\r' {
+			n--
+		}
+	}
+	return b[:n]
+}
+
+// trimRightWS trims trailing SP and HTAB.
+func trimRightWS(b []byte) []byte {
+	for len(b) > 0 && (b[len(b)-1] == ' ' || b[len(b)-1] == '\t') {
+		b = b[:len(b)-1]
+	}
+	return b
+}
+
+// trimWS trims leading and trailing SP and HTAB.
+func trimWS(b []byte) []byte {
+	for len(b) > 0 && (b[0] == ' ' || b[0] == '\t') {
+		b = b[1:]
+	}
+	return trimRightWS(b)
+}
+
+// Read implements io.Reader, draining buffered bytes first. It lets body
+// readers continue from where line parsing stopped.
+func (r *CRLFFastReader) Read(p []byte) (int, error) {
+	n, err := r.br.Read(p)
+	r.consume(p[:n])
+	return n, err
+}
+
 // Peek returns the next n bytes without advancing the reader.
 //
 // The returned slice is backed by the internal buffer and must not be modified.
 // If n exceeds the buffer size or cannot be satisfied without growing it,
-// ErrPeekBeyondCap is returned.
+// ErrPeekBeyondCap is returned; PeekAtLeast grows the buffer instead.
 func (r *CRLFFastReader) Peek(n int) ([]byte, error) {
 	if n > r.bufSize {
 		return nil, ErrPeekBeyondCap
@@ -102,3 +229,139 @@ func (r *CRLFFastReader) Peek(n int) ([]byte, error) {
 	}
 	return b, err
 }
+
+// SetMaxPeek sets how large PeekAtLeast may grow the buffer. Values below
+// the current buffer size leave it as is; zero restores DefaultMaxPeek.
+func (r *CRLFFastReader) SetMaxPeek(n int) { r.maxPeek = n }
+
+// PeekAtLeast returns at least the next n bytes without advancing the
+// reader, growing the internal buffer when n exceeds it, up to the
+// SetMaxPeek cap. It returns ErrPeekBeyondCap beyond that. The slice may
+// hold more than n bytes: everything already buffered. Like Peek, it is
+// only valid until the next read.
+//
+// It suits sniffing a protocol preface or scanning for a boundary that
+// may straddle the default buffer.
+func (r *CRLFFastReader) PeekAtLeast(n int) ([]byte, error) {
+	limit := r.maxPeek
+	if limit == 0 {
+		limit = DefaultMaxPeek
+	}
+	if n > r.bufSize {
+		if n > limit {
+			return nil, ErrPeekBeyondCap
+		}
+		r.grow(min(max(n, 2*r.bufSize), limit))
+	}
+	b, err := r.br.Peek(n)
+	if err != nil {
+		return b, err
+	}
+	return r.br.Peek(r.br.Buffered())
+}
+
+// grow replaces the buffer with one of size bytes, carrying over what is
+// already buffered.
+func (r *CRLFFastReader) grow(size int) {
+	pending, _ := r.br.Peek(r.br.Buffered())
+	carried := append([]byte(nil), pending...)
+	r.br = bufio.NewReaderSize(io.MultiReader(bytes.NewReader(carried), sourceReader{r}), size)
+	r.bufSize = r.br.Size()
+}
+
+// Discard skips the next n bytes, returning how many were skipped. It
+// fails only if the source ends or errors first.
+func (r *CRLFFastReader) Discard(n int) (int, error) {
+	done := 0
+	for done < n {
+		want := min(n-done, r.bufSize)
+		b, err := r.br.Peek(want)
+		r.consume(b)
+		r.br.Discard(len(b))
+		done += len(b)
+		if err != nil {
+			return done, err
+		}
+	}
+	return done, nil
+}
+
+// ReadFull reads exactly len(buf) bytes, as io.ReadFull does.
+func (r *CRLFFastReader) ReadFull(buf []byte) (int, error) {
+	return io.ReadFull(r, buf)
+}
+
+// readDeadliner is the part of net.Conn WatchContext needs.
+type readDeadliner interface {
+	SetReadDeadline(t time.Time) error
+}
+
+// WatchContext makes ctx being done interrupt a read blocked on the
+// source, by setting a read deadline in the past on it. Without it a
+// cancelled request only notices between reads, while a stalled client
+// can hold ReadLine or a body read for as long as the connection lives.
+// An interrupted read fails with an error matching both ctx.Err() and
+// os.ErrDeadlineExceeded.
+//
+// The source must have a SetReadDeadline method, as net.Conn does;
+// otherwise ErrDeadlineUnsupported is returned. Call stop once the
+// context no longer applies, e.g. after each request. If cancellation
+// had fired, stop clears the read deadline so the connection can be
+// read again. One context is watched at a time.
+func (r *CRLFFastReader) WatchContext(ctx context.Context) (stop func(), err error) {
+	d, ok := r.src.(readDeadliner)
+	if !ok {
+		return nil, ErrDeadlineUnsupported
+	}
+	w := &readWatch{ctx: ctx, conn: d}
+	r.watch = w
+	detach := context.AfterFunc(ctx, w.fire)
+	return func() {
+		detach()
+		w.stop()
+		if r.watch == w {
+			r.watch = nil
+		}
+	}, nil
+}
+
+// readWatch ties one context to a source's read deadline.
+type readWatch struct {
+	ctx  context.Context
+	conn readDeadliner
+
+	mu      sync.Mutex
+	fired   bool // the deadline was moved into the past
+	stopped bool
+}
+
+func (w *readWatch) fire() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.stopped {
+		return
+	}
+	w.fired = true
+	w.conn.SetReadDeadline(time.Unix(1, 0))
+}
+
+func (w *readWatch) stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.stopped = true
+	if w.fired {
+		w.conn.SetReadDeadline(time.Time{})
+	}
+}
+
+// mapErr reports a deadline error caused by cancellation as the
+// context's error.
+func (w *readWatch) mapErr(err error) error {
+	w.mu.Lock()
+	fired := w.fired
+	w.mu.Unlock()
+	if fired && errors.Is(err, os.ErrDeadlineExceeded) {
+		return fmt.Errorf("%w: %w", w.ctx.Err(), err)
+	}
+	return err
+}
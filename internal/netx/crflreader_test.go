@@ -2,7 +2,13 @@ package netx
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestReadLineCRLF(t *testing.T) {
@@ -52,3 +58,187 @@ func TestPeekBound(t *testing.T) {
 		t.Fatal(string(p))
 	}
 }
+
+func TestReadContinuedLine(t *testing.T) {
+	r := NewCRLFFastReader(bytes.NewBufferString("X-A: one  \r\n   two\r\n\tthree\r\nX-B: b\r\n\r\n"))
+	l, err := r.ReadContinuedLine(1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(l) != "X-A: one two three" {
+		t.Fatalf("got %q", l)
+	}
+	l, _ = r.ReadContinuedLine(1024)
+	if string(l) != "X-B: b" {
+		t.Fatalf("got %q", l)
+	}
+	l, _ = r.ReadContinuedLine(1024)
+	if len(l) != 0 {
+		t.Fatalf("blank line must not be continued, got %q", l)
+	}
+}
+
+func TestReadContinuedLineMax(t *testing.T) {
+	r := NewCRLFFastReader(bytes.NewBufferString("X: 12345\r\n 67890\r\n\r\n"))
+	if _, err := r.ReadContinuedLine(12); err != ErrLineTooLong {
+		t.Fatalf("expected ErrLineTooLong, got %v", err)
+	}
+}
+
+func TestReaderPosition(t *testing.T) {
+	r := NewCRLFFastReader(strings.NewReader("GET / HTTP/1.1\r\nA: b\nbody\nmore"))
+	if r.Offset() != 0 || r.Line() != 1 {
+		t.Fatal("fresh reader")
+	}
+	r.Peek(4)
+	if r.Offset() != 0 {
+		t.Fatal("peek must not advance")
+	}
+	r.ReadLine(64)
+	if r.Offset() != 16 || r.Line() != 2 {
+		t.Fatalf("after line 1: %d %d", r.Offset(), r.Line())
+	}
+	r.ReadLine(64) // bare LF
+	if r.Offset() != 21 || r.Line() != 3 {
+		t.Fatalf("after line 2: %d %d", r.Offset(), r.Line())
+	}
+	b, _ := io.ReadAll(r)
+	if r.Offset() != 21+int64(len(b)) || r.Line() != 4 {
+		t.Fatalf("after body: %d %d", r.Offset(), r.Line())
+	}
+	r.Reset(strings.NewReader("x"))
+	if r.Offset() != 0 || r.Line() != 1 {
+		t.Fatal("Reset must clear counters")
+	}
+}
+
+func TestPeekAtLeast(t *testing.T) {
+	data := strings.Repeat("0123456789", 100) + "\r\nrest"
+	r := NewCRLFFastReaderSize(strings.NewReader(data), 16)
+	if _, err := r.Peek(100); err != ErrPeekBeyondCap {
+		t.Fatalf("Peek: %v", err)
+	}
+	r.Discard(3)
+	b, err := r.PeekAtLeast(500)
+	if err != nil || len(b) < 500 || string(b[:10]) != "3456789012" {
+		t.Fatalf("PeekAtLeast: %d %v", len(b), err)
+	}
+	if r.Offset() != 3 {
+		t.Fatal("peeking must not advance")
+	}
+	// The grown buffer keeps line reads working across the old boundary.
+	line, _, err := r.ReadLine(2000)
+	if err != nil || len(line) != 997 {
+		t.Fatalf("ReadLine: %d %v", len(line), err)
+	}
+
+	r.SetMaxPeek(1024)
+	if _, err := r.PeekAtLeast(2048); err != ErrPeekBeyondCap {
+		t.Fatalf("over cap: %v", err)
+	}
+	b, err = r.PeekAtLeast(10)
+	if string(b) != "rest" || err != io.EOF {
+		t.Fatalf("short source: %q %v", b, err)
+	}
+}
+
+func TestDiscardReadFull(t *testing.T) {
+	r := NewCRLFFastReaderSize(strings.NewReader("a\nb\n"+strings.Repeat("x", 40)+"tail"), 16)
+	if n, err := r.Discard(44); n != 44 || err != nil {
+		t.Fatalf("Discard: %d %v", n, err)
+	}
+	if r.Offset() != 44 || r.Line() != 3 {
+		t.Fatalf("position %d %d", r.Offset(), r.Line())
+	}
+	buf := make([]byte, 4)
+	if n, err := r.ReadFull(buf); n != 4 || err != nil || string(buf) != "tail" {
+		t.Fatalf("ReadFull: %q %v", buf[:n], err)
+	}
+	if n, err := r.Discard(1); n != 0 || err != io.EOF {
+		t.Fatalf("Discard at EOF: %d %v", n, err)
+	}
+	if _, err := r.ReadFull(buf); err != io.EOF {
+		t.Fatalf("ReadFull at EOF: %v", err)
+	}
+}
+
+func TestWatchContext(t *testing.T) {
+	client, server := tcpPair(t)
+	defer client.Close()
+	defer server.Close()
+	r := NewCRLFFastReader(server)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stop, err := r.WatchContext(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errc := make(chan error, 1)
+	go func() {
+		_, _, err := r.ReadLine(64) // the client never sends a line
+		errc <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	select {
+	case err := <-errc:
+		if !errors.Is(err, context.Canceled) || !errors.Is(err, os.ErrDeadlineExceeded) {
+			t.Fatalf("got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("blocked read was not interrupted")
+	}
+
+	// Once stopped, the connection is usable for the next request.
+	stop()
+	client.Write([]byte("next\r\n"))
+	line, _, err := r.ReadLine(64)
+	if err != nil || string(line) != "next" {
+		t.Fatalf("after stop: %q %v", line, err)
+	}
+
+	// A watch stopped before cancellation leaves the deadline alone.
+	ctx, cancel = context.WithCancel(context.Background())
+	stop, _ = r.WatchContext(ctx)
+	stop()
+	cancel()
+	client.Write([]byte("again\r\n"))
+	if line, _, err := r.ReadLine(64); err != nil || string(line) != "again" {
+		t.Fatalf("after early stop: %q %v", line, err)
+	}
+
+	if _, err := NewCRLFFastReader(strings.NewReader("")).WatchContext(context.Background()); !errors.Is(err, ErrDeadlineUnsupported) {
+		t.Fatalf("plain reader: %v", err)
+	}
+}
+
+func TestResetClearsWatchAndMaxPeek(t *testing.T) {
+	client, server := tcpPair(t)
+	defer client.Close()
+	defer server.Close()
+	r := NewCRLFFastReader(server)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if _, err := r.WatchContext(ctx); err != nil {
+		t.Fatal(err)
+	}
+	cancel() // fires the watch, which is never stopped
+	r.SetMaxPeek(DefaultBufSize)
+
+	client2, server2 := tcpPair(t)
+	defer client2.Close()
+	defer server2.Close()
+	r.Reset(server2)
+
+	server2.SetReadDeadline(time.Now().Add(10 * time.Millisecond))
+	_, _, err := r.ReadLine(64)
+	if !errors.Is(err, os.ErrDeadlineExceeded) || errors.Is(err, context.Canceled) {
+		t.Fatalf("plain timeout reported as %v", err)
+	}
+	server2.SetReadDeadline(time.Time{})
+
+	client2.Write(make([]byte, 2*DefaultBufSize))
+	if b, err := r.PeekAtLeast(2 * DefaultBufSize); err != nil || len(b) < 2*DefaultBufSize {
+		t.Fatalf("peek after reset: %d %v", len(b), err)
+	}
+}
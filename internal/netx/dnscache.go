@@ -0,0 +1,151 @@
+package netx
+
+import (
+	"context"
+	"errors"
+	"net"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Defaults for CachingResolver.
+const (
+	DefaultDNSCacheTTL    = 30 * time.Second
+	DefaultDNSNegativeTTL = 5 * time.Second
+)
+
+// dnsPruneEvery controls how often expired entries are swept.
+const dnsPruneEvery = 1024
+
+// CachingResolver caches IP lookups in process so that high request rates
+// do not translate into one resolver query per connection. Concurrent
+// lookups of the same name share one query, and "no such host" answers are
+// cached for NegativeTTL. Other failures, such as timeouts, are not cached.
+//
+// The standard resolver does not report record TTLs, so answers are kept
+// for a fixed TTL. Its LookupIP method matches HappyEyeballsDialer.LookupIP.
+type CachingResolver struct {
+	TTL         time.Duration // lifetime of positive answers; DefaultDNSCacheTTL if zero
+	NegativeTTL time.Duration // lifetime of not-found answers; DefaultDNSNegativeTTL if zero, negative disables
+
+	// Lookup performs uncached lookups. Defaults to
+	// net.DefaultResolver.LookupIP.
+	Lookup func(ctx context.Context, network, host string) ([]net.IP, error)
+
+	Now func() time.Time // defaults to time.Now; for tests
+
+	mu       sync.Mutex
+	entries  map[string]*dnsEntry
+	inflight map[string]*dnsCall
+	inserts  int
+}
+
+type dnsEntry struct {
+	ips     []net.IP
+	err     error
+	expires time.Time
+}
+
+type dnsCall struct {
+	done chan struct{}
+	ips  []net.IP
+	err  error
+}
+
+// LookupIP returns the addresses of host for network "ip", "ip4" or "ip6",
+// answering from the cache when possible.
+func (r *CachingResolver) LookupIP(ctx context.Context, network, host string) ([]net.IP, error) {
+	key := network + "\x00" + host
+	now := r.now()
+
+	r.mu.Lock()
+	if e, ok := r.entries[key]; ok {
+		if now.Before(e.expires) {
+			r.mu.Unlock()
+			return slices.Clone(e.ips), e.err
+		}
+		delete(r.entries, key)
+	}
+	call, ok := r.inflight[key]
+	if !ok {
+		call = &dnsCall{done: make(chan struct{})}
+		if r.inflight == nil {
+			r.inflight = make(map[string]*dnsCall)
+		}
+		r.inflight[key] = call
+		// The shared query must outlive any single caller's context.
+		go r.do(context.WithoutCancel(ctx), key, network, host, call)
+	}
+	r.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return slices.Clone(call.ips), call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (r *CachingResolver) do(ctx context.Context, key, network, host string, call *dnsCall) {
+	lookup := r.Lookup
+	if lookup == nil {
+		lookup = net.DefaultResolver.LookupIP
+	}
+	call.ips, call.err = lookup(ctx, network, host)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.inflight, key)
+	close(call.done)
+
+	var ttl time.Duration
+	switch {
+	case call.err == nil:
+		ttl = r.TTL
+		if ttl == 0 {
+			ttl = DefaultDNSCacheTTL
+		}
+	case isNotFound(call.err):
+		ttl = r.NegativeTTL
+		if ttl == 0 {
+			ttl = DefaultDNSNegativeTTL
+		}
+	}
+	if ttl <= 0 {
+		return
+	}
+	now := r.now()
+	if r.entries == nil {
+		r.entries = make(map[string]*dnsEntry)
+	}
+	r.entries[key] = &dnsEntry{ips: call.ips, err: call.err, expires: now.Add(ttl)}
+	r.inserts++
+	if r.inserts >= dnsPruneEvery {
+		r.inserts = 0
+		for k, e := range r.entries {
+			if !now.Before(e.expires) {
+				delete(r.entries, k)
+			}
+		}
+	}
+}
+
+// Flush drops all cached answers.
+func (r *CachingResolver) Flush() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	clear(r.entries)
+}
+
+func (r *CachingResolver) now() time.Time {
+	if r.Now != nil {
+		return r.Now()
+	}
+	return time.Now()
+}
+
+func isNotFound(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
@@ -0,0 +1,127 @@
+package netx
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCachingResolverTTL(t *testing.T) {
+	var calls atomic.Int32
+	now := time.Unix(1000, 0)
+	r := &CachingResolver{
+		TTL: time.Minute,
+		Lookup: func(context.Context, string, string) ([]net.IP, error) {
+			calls.Add(1)
+			return []net.IP{net.ParseIP("192.0.2.1")}, nil
+		},
+		Now: func() time.Time { return now },
+	}
+	for i := 0; i < 3; i++ {
+		ips, err := r.LookupIP(context.Background(), "ip4", "example.com")
+		if err != nil || len(ips) != 1 {
+			t.Fatalf("lookup %d: %v %v", i, ips, err)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("expected 1 upstream query, got %d", calls.Load())
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := r.LookupIP(context.Background(), "ip4", "example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("expired entry should be refreshed, got %d queries", calls.Load())
+	}
+
+	r.Flush()
+	r.LookupIP(context.Background(), "ip4", "example.com")
+	if calls.Load() != 3 {
+		t.Fatalf("Flush should drop entries, got %d queries", calls.Load())
+	}
+}
+
+func TestCachingResolverNegative(t *testing.T) {
+	var calls atomic.Int32
+	notFound := &net.DNSError{Err: "no such host", Name: "nope.invalid", IsNotFound: true}
+	r := &CachingResolver{
+		Lookup: func(_ context.Context, _, host string) ([]net.IP, error) {
+			calls.Add(1)
+			if host == "flaky.example" {
+				return nil, &net.DNSError{Err: "timeout", IsTimeout: true}
+			}
+			return nil, notFound
+		},
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := r.LookupIP(context.Background(), "ip", "nope.invalid"); !errors.Is(err, notFound) {
+			t.Fatalf("expected not-found error, got %v", err)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("not-found should be cached, got %d queries", calls.Load())
+	}
+
+	for i := 0; i < 2; i++ {
+		r.LookupIP(context.Background(), "ip", "flaky.example")
+	}
+	if calls.Load() != 3 {
+		t.Fatalf("timeouts must not be cached, got %d queries", calls.Load())
+	}
+}
+
+func TestCachingResolverSingleflight(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	r := &CachingResolver{
+		Lookup: func(context.Context, string, string) ([]net.IP, error) {
+			calls.Add(1)
+			<-release
+			return []net.IP{net.ParseIP("192.0.2.1")}, nil
+		},
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := r.LookupIP(context.Background(), "ip4", "example.com"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if calls.Load() != 1 {
+		t.Fatalf("concurrent lookups should share one query, got %d", calls.Load())
+	}
+}
+
+func TestCachingResolverCallerCancel(t *testing.T) {
+	release := make(chan struct{})
+	r := &CachingResolver{
+		Lookup: func(ctx context.Context, _, _ string) ([]net.IP, error) {
+			<-release
+			return []net.IP{net.ParseIP("192.0.2.1")}, ctx.Err()
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := r.LookupIP(ctx, "ip4", "example.com"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	// The shared query keeps running and still populates the cache.
+	close(release)
+	ips, err := r.LookupIP(context.Background(), "ip4", "example.com")
+	if err != nil || len(ips) != 1 {
+		t.Fatalf("got %v, %v", ips, err)
+	}
+}
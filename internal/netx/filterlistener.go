@@ -0,0 +1,45 @@
+package netx
+
+import (
+	"errors"
+	"net"
+)
+
+// ErrConnHandled may be returned by an accept filter that has taken over
+// the connection itself, for example to tarpit it. The listener then
+// neither closes nor returns the connection.
+var ErrConnHandled = errors.New("netx: connection handled by accept filter")
+
+// FilterListener returns a Listener that passes each accepted connection
+// to filter before handing it out, so IP blocklists, connection-rate
+// limits or tarpits act at the TCP layer before any bytes are parsed.
+// A connection for which filter returns an error is closed and Accept
+// moves on to the next one, unless the error is ErrConnHandled.
+//
+// filter runs on the accepting goroutine: it should decide quickly and
+// move slow work, such as holding a tarpitted connection open, to a
+// goroutine of its own.
+func FilterListener(l net.Listener, filter func(net.Conn) error) net.Listener {
+	return &filterListener{Listener: l, filter: filter}
+}
+
+type filterListener struct {
+	net.Listener
+	filter func(net.Conn) error
+}
+
+func (l *filterListener) Accept() (net.Conn, error) {
+	for {
+		c, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		err = l.filter(c)
+		if err == nil {
+			return c, nil
+		}
+		if !errors.Is(err, ErrConnHandled) {
+			c.Close()
+		}
+	}
+}
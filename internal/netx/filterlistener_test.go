@@ -0,0 +1,67 @@
+package netx
+
+import (
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFilterListener(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		mu   sync.Mutex
+		seen int
+		held net.Conn
+	)
+	l := FilterListener(ln, func(c net.Conn) error {
+		mu.Lock()
+		defer mu.Unlock()
+		seen++
+		switch seen {
+		case 1:
+			return ErrCircuitOpen // any error rejects
+		case 2:
+			held = c
+			return ErrConnHandled
+		}
+		return nil
+	})
+	defer l.Close()
+
+	var clients []net.Conn
+	for i := 0; i < 3; i++ {
+		c, err := net.Dial("tcp", ln.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer c.Close()
+		clients = append(clients, c)
+	}
+
+	c, err := l.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	mu.Lock()
+	if seen != 3 || held == nil {
+		t.Fatalf("filter saw %d connections", seen)
+	}
+	defer held.Close()
+	mu.Unlock()
+
+	// The rejected client is hung up on; the handled one is kept open.
+	clients[0].SetReadDeadline(time.Now().Add(time.Second))
+	if _, err := clients[0].Read(make([]byte, 1)); err != io.EOF {
+		t.Fatalf("rejected client read: %v", err)
+	}
+	clients[1].SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+	if _, err := clients[1].Read(make([]byte, 1)); err == io.EOF {
+		t.Fatal("handled connection was closed")
+	}
+}
@@ -0,0 +1,110 @@
+//go:build unix
+
+package netx
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"syscall"
+)
+
+// maxHandover bounds how many listeners one handover message may carry.
+const maxHandover = 64
+
+// ErrHandoverMessage indicates a malformed listener handover message.
+var ErrHandoverMessage = errors.New("netx: malformed listener handover")
+
+// filer is implemented by *net.TCPListener and *net.UnixListener.
+type filer interface {
+	File() (*os.File, error)
+}
+
+// SendListeners passes the listening sockets of ls to the process at the
+// other end of c (SCM_RIGHTS), for a zero-downtime restart: the new binary
+// starts accepting on the same sockets while the old one drains. names
+// label the listeners and must match ls in length. The sender keeps its
+// own copies open.
+func SendListeners(c *net.UnixConn, ls []net.Listener, names []string) error {
+	if len(ls) != len(names) || len(ls) == 0 || len(ls) > maxHandover {
+		return fmt.Errorf("netx: cannot hand over %d listeners with %d names", len(ls), len(names))
+	}
+	fds := make([]int, 0, len(ls))
+	files := make([]*os.File, 0, len(ls))
+	defer func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}()
+	for _, l := range ls {
+		fl, ok := l.(filer)
+		if !ok {
+			return fmt.Errorf("netx: listener %T does not expose its file", l)
+		}
+		f, err := fl.File()
+		if err != nil {
+			return err
+		}
+		files = append(files, f)
+		fds = append(fds, int(f.Fd()))
+	}
+	for _, n := range names {
+		if strings.ContainsRune(n, '\n') {
+			return fmt.Errorf("netx: listener name %q contains a newline", n)
+		}
+	}
+	payload := []byte(strings.Join(names, "\n"))
+	_, _, err := c.WriteMsgUnix(payload, syscall.UnixRights(fds...), nil)
+	return err
+}
+
+// ReceiveListeners reads one handover message sent by SendListeners and
+// returns the listeners with their names.
+func ReceiveListeners(c *net.UnixConn) ([]net.Listener, []string, error) {
+	buf := make([]byte, 64<<10)
+	oob := make([]byte, syscall.CmsgSpace(maxHandover*4))
+	n, oobn, _, _, err := c.ReadMsgUnix(buf, oob)
+	if err != nil {
+		return nil, nil, err
+	}
+	msgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+	if err != nil {
+		return nil, nil, err
+	}
+	var fds []int
+	for i := range msgs {
+		got, err := syscall.ParseUnixRights(&msgs[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		fds = append(fds, got...)
+	}
+	names := strings.Split(string(buf[:n]), "\n")
+	if len(fds) == 0 || len(names) != len(fds) {
+		for _, fd := range fds {
+			syscall.Close(fd)
+		}
+		return nil, nil, ErrHandoverMessage
+	}
+
+	ls := make([]net.Listener, 0, len(fds))
+	for i, fd := range fds {
+		syscall.CloseOnExec(fd)
+		f := os.NewFile(uintptr(fd), names[i])
+		l, err := net.FileListener(f)
+		f.Close()
+		if err != nil {
+			for _, l := range ls {
+				l.Close()
+			}
+			for _, fd := range fds[i+1:] {
+				syscall.Close(fd)
+			}
+			return nil, nil, err
+		}
+		ls = append(ls, l)
+	}
+	return ls, names, nil
+}
@@ -0,0 +1,85 @@
+//go:build unix
+
+package netx
+
+import (
+	"net"
+	"testing"
+)
+
+func TestListenerHandover(t *testing.T) {
+	sockA, sockB := unixSocketPair(t)
+	defer sockA.Close()
+	defer sockB.Close()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := SendListeners(sockA, []net.Listener{ln}, []string{"http"}); err != nil {
+		t.Fatal(err)
+	}
+	ls, names, err := ReceiveListeners(sockB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ls) != 1 || names[0] != "http" {
+		t.Fatalf("got %d listeners, names %v", len(ls), names)
+	}
+	defer ls[0].Close()
+
+	// The old process stops accepting; the new one takes over the socket.
+	ln.Close()
+	go func() {
+		if c, err := ls[0].Accept(); err == nil {
+			c.Write([]byte("new"))
+			c.Close()
+		}
+	}()
+	c, err := net.Dial("tcp", ls[0].Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	buf := make([]byte, 3)
+	if _, err := c.Read(buf); err != nil || string(buf) != "new" {
+		t.Fatalf("read %q %v", buf, err)
+	}
+}
+
+func TestSendListenersArgs(t *testing.T) {
+	a, b := unixSocketPair(t)
+	defer a.Close()
+	defer b.Close()
+	if err := SendListeners(a, nil, nil); err == nil {
+		t.Fatal("empty handover should fail")
+	}
+}
+
+func unixSocketPair(t *testing.T) (*net.UnixConn, *net.UnixConn) {
+	t.Helper()
+	path := t.TempDir() + "/handover.sock"
+	ln, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	done := make(chan *net.UnixConn, 1)
+	go func() {
+		c, err := ln.AcceptUnix()
+		if err != nil {
+			done <- nil
+			return
+		}
+		done <- c
+	}()
+	a, err := net.DialUnix("unix", nil, &net.UnixAddr{Name: path, Net: "unix"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := <-done
+	if b == nil {
+		t.Fatal("accept failed")
+	}
+	return a, b
+}
@@ -0,0 +1,242 @@
+package netx
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+)
+
+// Defaults from RFC 8305 §8.
+const (
+	DefaultResolutionDelay = 50 * time.Millisecond
+	DefaultAttemptDelay    = 250 * time.Millisecond
+)
+
+// errNoAddresses is returned when a host resolves to no addresses at all.
+var errNoAddresses = errors.New("netx: no addresses for host")
+
+// HappyEyeballsDialer dials dual-stack hosts as described in RFC 8305: A and
+// AAAA records are resolved in parallel, addresses are interleaved by family
+// (IPv6 first), and connection attempts are staggered so a broken IPv6 path
+// costs at most AttemptDelay. The first connection to succeed wins and the
+// remaining attempts are cancelled.
+//
+// Its DialContext method has the usual dialer signature and can be used
+// wherever a func(ctx, network, addr) (net.Conn, error) is expected.
+type HappyEyeballsDialer struct {
+	// ResolutionDelay is how long to wait for the other address family
+	// once the first one has answered. Zero means DefaultResolutionDelay.
+	ResolutionDelay time.Duration
+
+	// AttemptDelay is the pause before starting the next connection
+	// attempt while earlier ones are still pending. A failed attempt
+	// starts the next one immediately. Zero means DefaultAttemptDelay.
+	AttemptDelay time.Duration
+
+	// LookupIP resolves host for network "ip4" or "ip6". Defaults to
+	// net.DefaultResolver.LookupIP.
+	LookupIP func(ctx context.Context, network, host string) ([]net.IP, error)
+
+	// Dial opens a single connection. Defaults to a zero net.Dialer.
+	Dial func(ctx context.Context, network, addr string) (net.Conn, error)
+}
+
+// DialContext connects to addr. Only the "tcp" network is raced; "tcp4",
+// "tcp6", other networks and IP-literal addresses are dialed directly.
+func (d *HappyEyeballsDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	dial := d.dialFunc()
+	if network != "tcp" {
+		return dial(ctx, network, addr)
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	if net.ParseIP(host) != nil {
+		return dial(ctx, network, addr)
+	}
+
+	ips, err := d.resolve(ctx, host)
+	if err != nil {
+		return nil, &net.OpError{Op: "dial", Net: network, Err: err}
+	}
+	return d.race(ctx, dial, ips, port)
+}
+
+func (d *HappyEyeballsDialer) dialFunc() func(context.Context, string, string) (net.Conn, error) {
+	if d.Dial != nil {
+		return d.Dial
+	}
+	var nd net.Dialer
+	return nd.DialContext
+}
+
+type lookupResult struct {
+	v6  bool
+	ips []net.IP
+	err error
+}
+
+// resolve looks up both families in parallel and returns the addresses
+// interleaved IPv6-first. After the first family answers, the other has
+// ResolutionDelay to arrive before it is abandoned.
+func (d *HappyEyeballsDialer) resolve(ctx context.Context, host string) ([]net.IP, error) {
+	lookup := d.LookupIP
+	if lookup == nil {
+		lookup = net.DefaultResolver.LookupIP
+	}
+	delay := d.ResolutionDelay
+	if delay <= 0 {
+		delay = DefaultResolutionDelay
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	ch := make(chan lookupResult, 2)
+	for _, v6 := range []bool{true, false} {
+		network := "ip4"
+		if v6 {
+			network = "ip6"
+		}
+		go func(v6 bool, network string) {
+			ips, err := lookup(ctx, network, host)
+			ch <- lookupResult{v6: v6, ips: ips, err: err}
+		}(v6, network)
+	}
+
+	var v4, v6 []net.IP
+	var firstErr error
+	record := func(r lookupResult) {
+		switch {
+		case r.err != nil:
+			if firstErr == nil {
+				firstErr = r.err
+			}
+		case r.v6:
+			v6 = r.ips
+		default:
+			v4 = r.ips
+		}
+	}
+
+	select {
+	case r := <-ch:
+		record(r)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	// Wait for the second family without delay if the first produced
+	// nothing usable.
+	var wait <-chan time.Time
+	if len(v4)+len(v6) > 0 {
+		t := time.NewTimer(delay)
+		defer t.Stop()
+		wait = t.C
+	}
+	select {
+	case r := <-ch:
+		record(r)
+	case <-wait:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	if len(v4)+len(v6) == 0 {
+		if firstErr != nil {
+			return nil, firstErr
+		}
+		return nil, errNoAddresses
+	}
+	return interleave(v6, v4), nil
+}
+
+// interleave alternates addresses from a and b, starting with a.
+func interleave(a, b []net.IP) []net.IP {
+	out := make([]net.IP, 0, len(a)+len(b))
+	for i := 0; i < len(a) || i < len(b); i++ {
+		if i < len(a) {
+			out = append(out, a[i])
+		}
+		if i < len(b) {
+			out = append(out, b[i])
+		}
+	}
+	return out
+}
+
+type dialResult struct {
+	c   net.Conn
+	err error
+}
+
+// race runs staggered connection attempts and returns the first success.
+func (d *HappyEyeballsDialer) race(ctx context.Context, dial func(context.Context, string, string) (net.Conn, error), ips []net.IP, port string) (net.Conn, error) {
+	delay := d.AttemptDelay
+	if delay <= 0 {
+		delay = DefaultAttemptDelay
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	results := make(chan dialResult, len(ips))
+	next, pending := 0, 0
+	start := func() {
+		addr := net.JoinHostPort(ips[next].String(), port)
+		next++
+		pending++
+		go func() {
+			c, err := dial(ctx, "tcp", addr)
+			results <- dialResult{c, err}
+		}()
+	}
+	// abandon cancels outstanding attempts and closes any that still
+	// manage to connect.
+	abandon := func() {
+		cancel()
+		go func(n int) {
+			for ; n > 0; n-- {
+				if r := <-results; r.c != nil {
+					r.c.Close()
+				}
+			}
+		}(pending)
+	}
+
+	var firstErr error
+	start()
+	for pending > 0 {
+		var timer *time.Timer
+		var wait <-chan time.Time
+		if next < len(ips) {
+			timer = time.NewTimer(delay)
+			wait = timer.C
+		}
+		select {
+		case r := <-results:
+			pending--
+			if r.err == nil {
+				if timer != nil {
+					timer.Stop()
+				}
+				abandon()
+				return r.c, nil
+			}
+			if firstErr == nil {
+				firstErr = r.err
+			}
+			if next < len(ips) {
+				start()
+			}
+		case <-wait:
+			start()
+		case <-ctx.Done():
+			abandon()
+			return nil, ctx.Err()
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+	cancel()
+	return nil, firstErr
+}
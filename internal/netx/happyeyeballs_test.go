@@ -0,0 +1,162 @@
+package netx
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeNet records dial attempts and answers them from a per-address script.
+type fakeNet struct {
+	mu       sync.Mutex
+	attempts []string
+	behave   map[string]string // "ok", "fail" or "hang"
+}
+
+func (f *fakeNet) dial(ctx context.Context, network, addr string) (net.Conn, error) {
+	f.mu.Lock()
+	f.attempts = append(f.attempts, addr)
+	f.mu.Unlock()
+	switch f.behave[addr] {
+	case "ok":
+		c, s := net.Pipe()
+		s.Close()
+		return c, nil
+	case "hang":
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return nil, errors.New("refused " + addr)
+}
+
+func (f *fakeNet) tried() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.attempts...)
+}
+
+func staticLookup(v6, v4 []string) func(context.Context, string, string) ([]net.IP, error) {
+	return func(_ context.Context, network, _ string) ([]net.IP, error) {
+		src := v4
+		if network == "ip6" {
+			src = v6
+		}
+		var ips []net.IP
+		for _, s := range src {
+			ips = append(ips, net.ParseIP(s))
+		}
+		return ips, nil
+	}
+}
+
+func TestHappyEyeballsFallsBackFromHangingIPv6(t *testing.T) {
+	f := &fakeNet{behave: map[string]string{"[2001:db8::1]:80": "hang", "192.0.2.1:80": "ok"}}
+	d := &HappyEyeballsDialer{
+		AttemptDelay: 20 * time.Millisecond,
+		LookupIP:     staticLookup([]string{"2001:db8::1"}, []string{"192.0.2.1"}),
+		Dial:         f.dial,
+	}
+	start := time.Now()
+	c, err := d.DialContext(context.Background(), "tcp", "example.com:80")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Close()
+	if time.Since(start) < 20*time.Millisecond {
+		t.Fatal("IPv4 attempt should have been staggered")
+	}
+	if got := f.tried(); len(got) != 2 || got[0] != "[2001:db8::1]:80" {
+		t.Fatalf("unexpected attempt order %v", got)
+	}
+}
+
+func TestHappyEyeballsFailureStartsNextImmediately(t *testing.T) {
+	f := &fakeNet{behave: map[string]string{"192.0.2.2:80": "ok"}}
+	d := &HappyEyeballsDialer{
+		AttemptDelay: time.Hour,
+		LookupIP:     staticLookup([]string{"2001:db8::1", "2001:db8::2"}, []string{"192.0.2.1", "192.0.2.2"}),
+		Dial:         f.dial,
+	}
+	c, err := d.DialContext(context.Background(), "tcp", "example.com:80")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Close()
+	want := []string{"[2001:db8::1]:80", "192.0.2.1:80", "[2001:db8::2]:80", "192.0.2.2:80"}
+	got := f.tried()
+	if len(got) != len(want) {
+		t.Fatalf("attempts = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("attempts = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestHappyEyeballsAllFail(t *testing.T) {
+	f := &fakeNet{}
+	d := &HappyEyeballsDialer{
+		LookupIP: staticLookup([]string{"2001:db8::1"}, []string{"192.0.2.1"}),
+		Dial:     f.dial,
+	}
+	_, err := d.DialContext(context.Background(), "tcp", "example.com:80")
+	if err == nil || err.Error() != "refused [2001:db8::1]:80" {
+		t.Fatalf("expected first attempt's error, got %v", err)
+	}
+}
+
+func TestHappyEyeballsSlowAAAA(t *testing.T) {
+	f := &fakeNet{behave: map[string]string{"192.0.2.1:80": "ok"}}
+	d := &HappyEyeballsDialer{
+		ResolutionDelay: 10 * time.Millisecond,
+		LookupIP: func(ctx context.Context, network, host string) ([]net.IP, error) {
+			if network == "ip6" {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}
+			return []net.IP{net.ParseIP("192.0.2.1")}, nil
+		},
+		Dial: f.dial,
+	}
+	c, err := d.DialContext(context.Background(), "tcp", "example.com:80")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Close()
+}
+
+func TestHappyEyeballsIPLiteral(t *testing.T) {
+	f := &fakeNet{behave: map[string]string{"192.0.2.9:443": "ok"}}
+	d := &HappyEyeballsDialer{
+		LookupIP: func(context.Context, string, string) ([]net.IP, error) {
+			t.Fatal("IP literals must not be resolved")
+			return nil, nil
+		},
+		Dial: f.dial,
+	}
+	c, err := d.DialContext(context.Background(), "tcp", "192.0.2.9:443")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Close()
+}
+
+func TestHappyEyeballsRealLoopback(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+
+	d := &HappyEyeballsDialer{LookupIP: staticLookup(nil, []string{"127.0.0.1"})}
+	c, err := d.DialContext(context.Background(), "tcp", net.JoinHostPort("localhost", port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Close()
+}
@@ -0,0 +1,257 @@
+package netx
+
+import (
+	"errors"
+	"hash/maphash"
+	"net"
+	"sync"
+	"time"
+)
+
+// Per-IP rejection errors returned by IPTracker.
+var (
+	ErrTooManyConns = errors.New("netx: too many connections from client")
+	ErrRequestRate  = errors.New("netx: client request rate exceeded")
+)
+
+// ipShards is the number of independently locked maps in an IPTracker.
+const ipShards = 32
+
+// ipPruneEvery controls how often a shard sweeps idle entries.
+const ipPruneEvery = 1024
+
+// IPStats is a snapshot of one client's activity.
+type IPStats struct {
+	Conns    int     // connections currently open
+	Requests uint64  // requests counted since the entry was created
+	Rate     float64 // requests per second over roughly the last second
+}
+
+// IPTracker counts open connections and request rates per client IP. Its
+// map is split into shards so busy servers do not contend on one lock.
+// Limits are optional; with both zero it only records. The zero value is
+// ready to use.
+type IPTracker struct {
+	// MaxConns caps the connections open at once from one IP; zero
+	// means unlimited.
+	MaxConns int
+
+	// MaxRate caps the requests per second from one IP, estimated over
+	// a sliding one-second window; zero means unlimited.
+	MaxRate float64
+
+	// Now returns the current time; time.Now if nil.
+	Now func() time.Time
+
+	seedOnce sync.Once
+	seed     maphash.Seed
+	shards   [ipShards]ipShard
+}
+
+type ipShard struct {
+	mu      sync.Mutex
+	entries map[string]*ipEntry
+	ops     int
+}
+
+type ipEntry struct {
+	conns    int
+	requests uint64
+	window   time.Time // start of the current one-second window
+	cur      uint64    // requests in the current window
+	prev     uint64    // requests in the previous window
+}
+
+// rate estimates requests per second at now by weighting the previous
+// window by how much of it still overlaps the last second.
+func (e *ipEntry) rate(now time.Time) float64 {
+	e.roll(now)
+	frac := float64(now.Sub(e.window)) / float64(time.Second)
+	return float64(e.prev)*(1-frac) + float64(e.cur)
+}
+
+func (e *ipEntry) roll(now time.Time) {
+	switch d := now.Sub(e.window); {
+	case d < time.Second:
+	case d < 2*time.Second:
+		e.prev, e.cur = e.cur, 0
+		e.window = e.window.Add(time.Second)
+	default:
+		e.prev, e.cur = 0, 0
+		e.window = now
+	}
+}
+
+// idle reports whether e carries no state worth keeping at now.
+func (e *ipEntry) idle(now time.Time) bool {
+	return e.conns == 0 && now.Sub(e.window) >= 2*time.Second
+}
+
+// ConnOpened records a new connection from ip. It fails with
+// ErrTooManyConns, recording nothing, when MaxConns would be exceeded.
+// Otherwise release must be called once the connection closes; extra
+// calls are ignored.
+func (t *IPTracker) ConnOpened(ip string) (release func(), err error) {
+	s := t.shard(ip)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := t.entry(s, ip)
+	if t.MaxConns > 0 && e.conns >= t.MaxConns {
+		return nil, ErrTooManyConns
+	}
+	e.conns++
+	var once sync.Once
+	return func() { once.Do(func() { t.connClosed(ip) }) }, nil
+}
+
+func (t *IPTracker) connClosed(ip string) {
+	s := t.shard(ip)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.entries[ip]; ok && e.conns > 0 {
+		e.conns--
+	}
+}
+
+// Request counts one request from ip. It returns ErrRequestRate when the
+// request would push ip over MaxRate; rejected requests are not counted,
+// so a client that backs off recovers.
+func (t *IPTracker) Request(ip string) error {
+	now := t.now()
+	s := t.shard(ip)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := t.entry(s, ip)
+	if t.MaxRate > 0 && e.rate(now)+1 > t.MaxRate {
+		return ErrRequestRate
+	}
+	e.roll(now)
+	e.cur++
+	e.requests++
+	return nil
+}
+
+// Stats returns the current counters for ip.
+func (t *IPTracker) Stats(ip string) IPStats {
+	s := t.shard(ip)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[ip]
+	if !ok {
+		return IPStats{}
+	}
+	return IPStats{Conns: e.conns, Requests: e.requests, Rate: e.rate(t.now())}
+}
+
+// Snapshot returns the counters of every tracked IP, for admin and
+// metrics endpoints. Each shard is locked in turn, so the result is not
+// a single atomic view.
+func (t *IPTracker) Snapshot() map[string]IPStats {
+	now := t.now()
+	out := make(map[string]IPStats)
+	for i := range t.shards {
+		s := &t.shards[i]
+		s.mu.Lock()
+		for ip, e := range s.entries {
+			if e.idle(now) {
+				delete(s.entries, ip)
+				continue
+			}
+			out[ip] = IPStats{Conns: e.conns, Requests: e.requests, Rate: e.rate(now)}
+		}
+		s.mu.Unlock()
+	}
+	return out
+}
+
+// Listener returns a listener that records every accepted connection
+// under its remote IP until the connection is closed. Connections over
+// MaxConns are closed at once and Accept moves on to the next one.
+func (t *IPTracker) Listener(l net.Listener) net.Listener {
+	return &ipTrackListener{Listener: l, t: t}
+}
+
+type ipTrackListener struct {
+	net.Listener
+	t *IPTracker
+}
+
+func (l *ipTrackListener) Accept() (net.Conn, error) {
+	for {
+		c, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		release, err := l.t.ConnOpened(RemoteIP(c.RemoteAddr()))
+		if err != nil {
+			c.Close()
+			continue
+		}
+		return &ipTrackConn{Conn: c, release: release}, nil
+	}
+}
+
+type ipTrackConn struct {
+	net.Conn
+	release func()
+}
+
+func (c *ipTrackConn) Close() error {
+	err := c.Conn.Close()
+	c.release()
+	return err
+}
+
+// RemoteIP returns the host part of addr, or its string form when it
+// carries no port.
+func RemoteIP(addr net.Addr) string {
+	if addr == nil {
+		return ""
+	}
+	switch a := addr.(type) {
+	case *net.TCPAddr:
+		return a.IP.String()
+	case *net.UDPAddr:
+		return a.IP.String()
+	}
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
+
+func (t *IPTracker) shard(ip string) *ipShard {
+	t.seedOnce.Do(func() { t.seed = maphash.MakeSeed() })
+	return &t.shards[maphash.String(t.seed, ip)%ipShards]
+}
+
+// entry returns ip's entry in s, creating it; s.mu must be held.
+func (t *IPTracker) entry(s *ipShard, ip string) *ipEntry {
+	now := t.now()
+	if s.entries == nil {
+		s.entries = make(map[string]*ipEntry)
+	}
+	s.ops++
+	if s.ops >= ipPruneEvery {
+		s.ops = 0
+		for k, e := range s.entries {
+			if e.idle(now) {
+				delete(s.entries, k)
+			}
+		}
+	}
+	e, ok := s.entries[ip]
+	if !ok {
+		e = &ipEntry{window: now}
+		s.entries[ip] = e
+	}
+	return e
+}
+
+func (t *IPTracker) now() time.Time {
+	if t.Now != nil {
+		return t.Now()
+	}
+	return time.Now()
+}
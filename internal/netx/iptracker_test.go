@@ -0,0 +1,109 @@
+package netx
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestIPTrackerConns(t *testing.T) {
+	tr := &IPTracker{MaxConns: 2}
+	r1, err := tr.ConnOpened("10.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tr.ConnOpened("10.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tr.ConnOpened("10.0.0.1"); !errors.Is(err, ErrTooManyConns) {
+		t.Fatalf("got %v", err)
+	}
+	if _, err := tr.ConnOpened("10.0.0.2"); err != nil {
+		t.Fatal("limit must be per IP")
+	}
+	r1()
+	r1() // double release frees one slot only
+	if got := tr.Stats("10.0.0.1").Conns; got != 1 {
+		t.Fatalf("conns = %d", got)
+	}
+	if _, err := tr.ConnOpened("10.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tr.ConnOpened("10.0.0.1"); !errors.Is(err, ErrTooManyConns) {
+		t.Fatalf("got %v", err)
+	}
+}
+
+func TestIPTrackerRate(t *testing.T) {
+	now := time.Unix(1000, 0)
+	tr := &IPTracker{MaxRate: 3, Now: func() time.Time { return now }}
+	for i := 0; i < 3; i++ {
+		if err := tr.Request("a"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tr.Request("a"); !errors.Is(err, ErrRequestRate) {
+		t.Fatalf("got %v", err)
+	}
+	if err := tr.Request("b"); err != nil {
+		t.Fatal("rate must be per IP")
+	}
+
+	// Half a second into the next window, half of the old one still counts.
+	now = now.Add(1500 * time.Millisecond)
+	if got := tr.Stats("a").Rate; got != 1.5 {
+		t.Fatalf("rate = %v", got)
+	}
+	if err := tr.Request("a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tr.Request("a"); !errors.Is(err, ErrRequestRate) {
+		t.Fatalf("got %v", err)
+	}
+	if s := tr.Stats("a"); s.Requests != 4 {
+		t.Fatalf("requests = %d", s.Requests)
+	}
+
+	// Idle entries drop out of the snapshot.
+	now = now.Add(5 * time.Second)
+	if snap := tr.Snapshot(); len(snap) != 0 {
+		t.Fatalf("snapshot %v", snap)
+	}
+}
+
+func TestIPTrackerListener(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := &IPTracker{MaxConns: 1}
+	l := tr.Listener(ln)
+	defer l.Close()
+
+	for i := 0; i < 3; i++ {
+		c, err := net.Dial("tcp", ln.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer c.Close()
+	}
+	c, err := l.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tr.Snapshot()["127.0.0.1"].Conns; got != 1 {
+		t.Fatalf("conns = %d", got)
+	}
+	c.Close()
+
+	// The slot is free again, so a queued connection is accepted.
+	c, err = l.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Close()
+	if got := tr.Stats("127.0.0.1").Conns; got != 0 {
+		t.Fatalf("conns = %d after close", got)
+	}
+}
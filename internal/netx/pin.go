@@ -0,0 +1,70 @@
+package netx
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNoPins is returned by PinSPKI when called without pins.
+	ErrNoPins = errors.New("netx: no SPKI pins given")
+
+	// ErrPinMismatch is returned by a pinning verifier when no certificate
+	// in the peer's chain matches a pin.
+	ErrPinMismatch = errors.New("netx: peer certificate does not match any pinned key")
+)
+
+// SPKIHash returns the base64-encoded SHA-256 digest of cert's
+// SubjectPublicKeyInfo, the pin format used by HPKP and most pinning tools.
+func SPKIHash(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// PinSPKI returns a peer verifier that accepts a connection only if some
+// certificate in the peer's chain has one of the given SPKI hashes (see
+// SPKIHash). It fits tls.Config.VerifyConnection on both clients and
+// servers, and runs after normal chain verification, so pins add to PKI
+// checks rather than replacing them. Pinning an intermediate or root key
+// keeps working across leaf renewals. When the chain was not verified,
+// e.g. with InsecureSkipVerify, only the leaf's key is matched.
+func PinSPKI(pins ...string) (func(tls.ConnectionState) error, error) {
+	if len(pins) == 0 {
+		return nil, ErrNoPins
+	}
+	set := make(map[string]struct{}, len(pins))
+	for _, p := range pins {
+		raw, err := base64.StdEncoding.DecodeString(p)
+		if err != nil || len(raw) != sha256.Size {
+			return nil, fmt.Errorf("netx: invalid SPKI pin %q", p)
+		}
+		set[p] = struct{}{}
+	}
+
+	return func(cs tls.ConnectionState) error {
+		// Without verified chains (verification disabled or done
+		// elsewhere) nothing ties the presented intermediates to the
+		// leaf: a peer could append a public pinned CA to its own leaf.
+		// Only the leaf, whose key the handshake proved, can match then.
+		if len(cs.VerifiedChains) == 0 {
+			if len(cs.PeerCertificates) > 0 {
+				if _, ok := set[SPKIHash(cs.PeerCertificates[0])]; ok {
+					return nil
+				}
+			}
+			return ErrPinMismatch
+		}
+		for _, chain := range cs.VerifiedChains {
+			for _, cert := range chain {
+				if _, ok := set[SPKIHash(cert)]; ok {
+					return nil
+				}
+			}
+		}
+		return ErrPinMismatch
+	}, nil
+}
@@ -0,0 +1,116 @@
+package netx
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+)
+
+func selfSignedCert(t *testing.T) (tls.Certificate, *x509.Certificate) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, cert
+}
+
+func TestPinSPKIArgs(t *testing.T) {
+	if _, err := PinSPKI(); !errors.Is(err, ErrNoPins) {
+		t.Fatalf("expected ErrNoPins, got %v", err)
+	}
+	if _, err := PinSPKI("not-base64!"); err == nil {
+		t.Fatal("expected error for malformed pin")
+	}
+}
+
+func TestPinSPKIHandshake(t *testing.T) {
+	tlsCert, cert := selfSignedCert(t)
+	_, other := selfSignedCert(t)
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{tlsCert}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			c.(*tls.Conn).Handshake()
+			c.Close()
+		}
+	}()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	dial := func(pin string) error {
+		verify, err := PinSPKI(pin)
+		if err != nil {
+			return err
+		}
+		c, err := tls.Dial("tcp", ln.Addr().String(), &tls.Config{RootCAs: pool, VerifyConnection: verify})
+		if err != nil {
+			return err
+		}
+		return c.Close()
+	}
+
+	if err := dial(SPKIHash(cert)); err != nil {
+		t.Fatalf("matching pin should succeed: %v", err)
+	}
+	if err := dial(SPKIHash(other)); !errors.Is(err, ErrPinMismatch) {
+		t.Fatalf("expected ErrPinMismatch, got %v", err)
+	}
+}
+
+func TestPinSPKIUnverifiedChain(t *testing.T) {
+	_, pinned := selfSignedCert(t)
+	_, attacker := selfSignedCert(t)
+	verify, err := PinSPKI(SPKIHash(pinned))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Unverified: a pinned certificate after the leaf proves nothing.
+	if err := verify(tls.ConnectionState{PeerCertificates: []*x509.Certificate{attacker, pinned}}); !errors.Is(err, ErrPinMismatch) {
+		t.Fatalf("pinned cert in non-leaf position accepted: %v", err)
+	}
+	if err := verify(tls.ConnectionState{PeerCertificates: []*x509.Certificate{pinned, attacker}}); err != nil {
+		t.Fatalf("pinned leaf: %v", err)
+	}
+	if err := verify(tls.ConnectionState{}); !errors.Is(err, ErrPinMismatch) {
+		t.Fatalf("no certificates: %v", err)
+	}
+
+	// Verified chains may match at any position.
+	cs := tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{attacker, pinned}}}
+	if err := verify(cs); err != nil {
+		t.Fatalf("verified chain: %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package netx
+
+import (
+	"bufio"
+	"io"
+	"sync"
+)
+
+// Pools of default-sized readers and writers, so servers can reuse
+// per-connection buffers instead of allocating 4 KiB twice per accept.
+var (
+	readerPool = sync.Pool{
+		New: func() any { return NewCRLFFastReader(nil) },
+	}
+	writerPool = sync.Pool{
+		New: func() any { return bufio.NewWriterSize(nil, DefaultBufSize) },
+	}
+)
+
+// AcquireReader returns a pooled CRLFFastReader reading from r.
+// Call ReleaseReader when the connection is done with it.
+func AcquireReader(r io.Reader) *CRLFFastReader {
+	cr := readerPool.Get().(*CRLFFastReader)
+	cr.Reset(r)
+	return cr
+}
+
+// ReleaseReader returns cr to the pool. Buffered but unread data is
+// discarded, and cr must not be used afterwards. Readers not of the default
+// size are dropped rather than pooled.
+func ReleaseReader(cr *CRLFFastReader) {
+	if cr == nil || cr.bufSize != DefaultBufSize {
+		return
+	}
+	cr.Reset(nil)
+	readerPool.Put(cr)
+}
+
+// AcquireWriter returns a pooled bufio.Writer of DefaultBufSize writing to w.
+func AcquireWriter(w io.Writer) *bufio.Writer {
+	bw := writerPool.Get().(*bufio.Writer)
+	bw.Reset(w)
+	return bw
+}
+
+// ReleaseWriter returns bw to the pool. Callers must Flush first; unflushed
+// data is discarded. Writers not of the default size are dropped.
+func ReleaseWriter(bw *bufio.Writer) {
+	if bw == nil || bw.Size() != DefaultBufSize {
+		return
+	}
+	bw.Reset(nil)
+	writerPool.Put(bw)
+}
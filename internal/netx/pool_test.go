@@ -0,0 +1,55 @@
+package netx
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestNewCRLFFastReaderSize(t *testing.T) {
+	line := strings.Repeat("a", 100)
+	r := NewCRLFFastReaderSize(strings.NewReader(line+"\r\n"), 64)
+	if _, err := r.Peek(64); err != nil {
+		t.Fatalf("Peek within size: %v", err)
+	}
+	if _, err := r.Peek(65); err != ErrPeekBeyondCap {
+		t.Fatalf("expected ErrPeekBeyondCap, got %v", err)
+	}
+	l, _, err := r.ReadLine(1024)
+	if err != nil || string(l) != line {
+		t.Fatalf("ReadLine = %q, %v", l, err)
+	}
+}
+
+func TestAcquireReleaseReader(t *testing.T) {
+	r := AcquireReader(strings.NewReader("one\r\nleftover"))
+	l, _, _ := r.ReadLine(1024)
+	if string(l) != "one" {
+		t.Fatalf("got %q", l)
+	}
+	ReleaseReader(r)
+
+	r = AcquireReader(strings.NewReader("two\r\n"))
+	defer ReleaseReader(r)
+	l, _, _ = r.ReadLine(1024)
+	if string(l) != "two" {
+		t.Fatalf("pooled reader leaked old data: %q", l)
+	}
+}
+
+func TestAcquireReleaseWriter(t *testing.T) {
+	var a, b bytes.Buffer
+	w := AcquireWriter(&a)
+	w.WriteString("hello")
+	w.Flush()
+	ReleaseWriter(w)
+
+	w = AcquireWriter(&b)
+	w.WriteString("world")
+	w.Flush()
+	ReleaseWriter(w)
+
+	if a.String() != "hello" || b.String() != "world" {
+		t.Fatalf("a=%q b=%q", a.String(), b.String())
+	}
+}
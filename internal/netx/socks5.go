@@ -0,0 +1,230 @@
+package netx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+// SOCKS5 protocol constants (RFC 1928, RFC 1929).
+const (
+	socksVersion     = 0x05
+	socksAuthNone    = 0x00
+	socksAuthUserPW  = 0x02
+	socksAuthNoMatch = 0xff
+	socksCmdConnect  = 0x01
+	socksAtypIPv4    = 0x01
+	socksAtypDomain  = 0x03
+	socksAtypIPv6    = 0x04
+	socksUserPWVer   = 0x01
+)
+
+var (
+	// ErrSOCKSNoAuthMethod is returned when the proxy accepts none of the
+	// offered authentication methods.
+	ErrSOCKSNoAuthMethod = errors.New("netx: socks5 proxy rejected all authentication methods")
+
+	// ErrSOCKSAuthFailed is returned when username/password authentication fails.
+	ErrSOCKSAuthFailed = errors.New("netx: socks5 authentication failed")
+
+	// ErrSOCKSProtocol is returned for malformed proxy replies.
+	ErrSOCKSProtocol = errors.New("netx: socks5 protocol error")
+)
+
+// SOCKSReplyError is a non-success reply code from a SOCKS5 CONNECT.
+type SOCKSReplyError byte
+
+var socksReplyText = [...]string{
+	1: "general SOCKS server failure",
+	2: "connection not allowed by ruleset",
+	3: "network unreachable",
+	4: "host unreachable",
+	5: "connection refused",
+	6: "TTL expired",
+	7: "command not supported",
+	8: "address type not supported",
+}
+
+func (e SOCKSReplyError) Error() string {
+	if int(e) < len(socksReplyText) && socksReplyText[e] != "" {
+		return "netx: socks5 connect failed: " + socksReplyText[e]
+	}
+	return "netx: socks5 connect failed: reply code " + strconv.Itoa(int(e))
+}
+
+// SOCKS5Dialer connects to destinations through a SOCKS5 proxy. Host names
+// are sent to the proxy unresolved, so name resolution happens on the
+// proxy side. Its DialContext method has the usual dialer signature.
+type SOCKS5Dialer struct {
+	ProxyAddr string // host:port of the proxy
+
+	// Username and Password enable RFC 1929 authentication when Username
+	// is non-empty. Each is limited to 255 bytes.
+	Username string
+	Password string
+
+	// Dial reaches the proxy. Defaults to a zero net.Dialer.
+	Dial func(ctx context.Context, network, addr string) (net.Conn, error)
+}
+
+// DialContext connects to addr via the proxy. Only TCP networks are
+// supported.
+func (d *SOCKS5Dialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	switch network {
+	case "tcp", "tcp4", "tcp6":
+	default:
+		return nil, &net.OpError{Op: "dial", Net: network, Err: net.UnknownNetworkError(network)}
+	}
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("netx: invalid port %q", portStr)
+	}
+	if len(host) > 255 {
+		return nil, fmt.Errorf("netx: host name too long for socks5: %q", host)
+	}
+
+	dial := d.Dial
+	if dial == nil {
+		var nd net.Dialer
+		dial = nd.DialContext
+	}
+	c, err := dial(ctx, "tcp", d.ProxyAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	// Bound the handshake by ctx: once it is done, a past deadline
+	// unblocks any pending read or write.
+	stop := context.AfterFunc(ctx, func() { c.SetDeadline(time.Unix(1, 0)) })
+
+	err = d.handshake(c, host, uint16(port))
+	if stopped := stop(); err != nil || !stopped {
+		c.Close()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, &net.OpError{Op: "socks5", Net: network, Addr: socksAddr(addr), Err: err}
+	}
+	c.SetDeadline(time.Time{})
+	return c, nil
+}
+
+func (d *SOCKS5Dialer) handshake(c net.Conn, host string, port uint16) error {
+	if len(d.Username) > 255 || len(d.Password) > 255 {
+		return errors.New("netx: socks5 credentials longer than 255 bytes")
+	}
+
+	// Method negotiation.
+	greeting := []byte{socksVersion, 1, socksAuthNone}
+	if d.Username != "" {
+		greeting = []byte{socksVersion, 2, socksAuthNone, socksAuthUserPW}
+	}
+	if _, err := c.Write(greeting); err != nil {
+		return err
+	}
+	var buf [2]byte
+	if _, err := io.ReadFull(c, buf[:]); err != nil {
+		return err
+	}
+	if buf[0] != socksVersion {
+		return ErrSOCKSProtocol
+	}
+	switch buf[1] {
+	case socksAuthNone:
+	case socksAuthUserPW:
+		if d.Username == "" {
+			return ErrSOCKSProtocol
+		}
+		if err := d.authenticate(c); err != nil {
+			return err
+		}
+	case socksAuthNoMatch:
+		return ErrSOCKSNoAuthMethod
+	default:
+		return ErrSOCKSProtocol
+	}
+
+	// CONNECT request.
+	req := []byte{socksVersion, socksCmdConnect, 0}
+	if ip := net.ParseIP(host); ip != nil {
+		if ip4 := ip.To4(); ip4 != nil {
+			req = append(req, socksAtypIPv4)
+			req = append(req, ip4...)
+		} else {
+			req = append(req, socksAtypIPv6)
+			req = append(req, ip.To16()...)
+		}
+	} else {
+		req = append(req, socksAtypDomain, byte(len(host)))
+		req = append(req, host...)
+	}
+	req = append(req, byte(port>>8), byte(port))
+	if _, err := c.Write(req); err != nil {
+		return err
+	}
+
+	// Reply: VER REP RSV ATYP BND.ADDR BND.PORT.
+	var hdr [4]byte
+	if _, err := io.ReadFull(c, hdr[:]); err != nil {
+		return err
+	}
+	if hdr[0] != socksVersion {
+		return ErrSOCKSProtocol
+	}
+	if hdr[1] != 0 {
+		return SOCKSReplyError(hdr[1])
+	}
+	var skip int
+	switch hdr[3] {
+	case socksAtypIPv4:
+		skip = net.IPv4len
+	case socksAtypIPv6:
+		skip = net.IPv6len
+	case socksAtypDomain:
+		if _, err := io.ReadFull(c, buf[:1]); err != nil {
+			return err
+		}
+		skip = int(buf[0])
+	default:
+		return ErrSOCKSProtocol
+	}
+	_, err := io.CopyN(io.Discard, c, int64(skip+2))
+	return err
+}
+
+// authenticate runs RFC 1929 username/password sub-negotiation.
+func (d *SOCKS5Dialer) authenticate(c net.Conn) error {
+	msg := make([]byte, 0, 3+len(d.Username)+len(d.Password))
+	msg = append(msg, socksUserPWVer, byte(len(d.Username)))
+	msg = append(msg, d.Username...)
+	msg = append(msg, byte(len(d.Password)))
+	msg = append(msg, d.Password...)
+	if _, err := c.Write(msg); err != nil {
+		return err
+	}
+	var resp [2]byte
+	if _, err := io.ReadFull(c, resp[:]); err != nil {
+		return err
+	}
+	if resp[0] != socksUserPWVer {
+		return ErrSOCKSProtocol
+	}
+	if resp[1] != 0 {
+		return ErrSOCKSAuthFailed
+	}
+	return nil
+}
+
+// socksAddr names the destination in errors.
+type socksAddr string
+
+func (a socksAddr) Network() string { return "socks5" }
+func (a socksAddr) String() string  { return string(a) }
@@ -0,0 +1,163 @@
+package netx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// fakeSOCKS5 runs a one-connection SOCKS5 server. It records the CONNECT
+// destination, answers with reply, and then echoes the stream.
+type fakeSOCKS5 struct {
+	user, pass string
+	reply      byte
+	gotDest    chan []byte
+}
+
+func (f *fakeSOCKS5) serve(t *testing.T, ln net.Listener) {
+	c, err := ln.Accept()
+	if err != nil {
+		return
+	}
+	defer c.Close()
+
+	var hdr [2]byte
+	io.ReadFull(c, hdr[:])
+	methods := make([]byte, hdr[1])
+	io.ReadFull(c, methods)
+
+	if f.user != "" {
+		if !bytes.Contains(methods, []byte{socksAuthUserPW}) {
+			c.Write([]byte{5, socksAuthNoMatch})
+			return
+		}
+		c.Write([]byte{5, socksAuthUserPW})
+		var b [1]byte
+		io.ReadFull(c, b[:]) // version
+		io.ReadFull(c, b[:])
+		user := make([]byte, b[0])
+		io.ReadFull(c, user)
+		io.ReadFull(c, b[:])
+		pass := make([]byte, b[0])
+		io.ReadFull(c, pass)
+		if string(user) != f.user || string(pass) != f.pass {
+			c.Write([]byte{1, 1})
+			return
+		}
+		c.Write([]byte{1, 0})
+	} else {
+		c.Write([]byte{5, socksAuthNone})
+	}
+
+	var req [4]byte
+	io.ReadFull(c, req[:])
+	var dest []byte
+	switch req[3] {
+	case socksAtypIPv4:
+		dest = make([]byte, 4+2)
+	case socksAtypIPv6:
+		dest = make([]byte, 16+2)
+	case socksAtypDomain:
+		var n [1]byte
+		io.ReadFull(c, n[:])
+		dest = make([]byte, int(n[0])+2)
+	}
+	io.ReadFull(c, dest)
+	f.gotDest <- dest
+
+	c.Write([]byte{5, f.reply, 0, socksAtypIPv4, 10, 0, 0, 1, 0x1f, 0x90})
+	if f.reply == 0 {
+		io.Copy(c, c)
+	}
+}
+
+func startSOCKS5(t *testing.T, f *fakeSOCKS5) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	f.gotDest = make(chan []byte, 1)
+	go f.serve(t, ln)
+	return ln.Addr().String()
+}
+
+func TestSOCKS5DialDomain(t *testing.T) {
+	addr := startSOCKS5(t, &fakeSOCKS5{})
+	d := &SOCKS5Dialer{ProxyAddr: addr}
+	c, err := d.DialContext(context.Background(), "tcp", "example.com:443")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	c.Write([]byte("ping"))
+	buf := make([]byte, 4)
+	if _, err := io.ReadFull(c, buf); err != nil || string(buf) != "ping" {
+		t.Fatalf("echo failed: %q %v", buf, err)
+	}
+}
+
+func TestSOCKS5DialUserPassAndIP(t *testing.T) {
+	f := &fakeSOCKS5{user: "alice", pass: "s3cret"}
+	addr := startSOCKS5(t, f)
+	d := &SOCKS5Dialer{ProxyAddr: addr, Username: "alice", Password: "s3cret"}
+	c, err := d.DialContext(context.Background(), "tcp", "192.0.2.1:80")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Close()
+	if got := <-f.gotDest; !bytes.Equal(got, []byte{192, 0, 2, 1, 0, 80}) {
+		t.Fatalf("unexpected destination %v", got)
+	}
+}
+
+func TestSOCKS5Errors(t *testing.T) {
+	addr := startSOCKS5(t, &fakeSOCKS5{user: "alice", pass: "s3cret"})
+	d := &SOCKS5Dialer{ProxyAddr: addr, Username: "alice", Password: "wrong"}
+	if _, err := d.DialContext(context.Background(), "tcp", "example.com:80"); !errors.Is(err, ErrSOCKSAuthFailed) {
+		t.Fatalf("expected ErrSOCKSAuthFailed, got %v", err)
+	}
+
+	addr = startSOCKS5(t, &fakeSOCKS5{user: "alice"})
+	d = &SOCKS5Dialer{ProxyAddr: addr}
+	if _, err := d.DialContext(context.Background(), "tcp", "example.com:80"); !errors.Is(err, ErrSOCKSNoAuthMethod) {
+		t.Fatalf("expected ErrSOCKSNoAuthMethod, got %v", err)
+	}
+
+	addr = startSOCKS5(t, &fakeSOCKS5{reply: 5})
+	d = &SOCKS5Dialer{ProxyAddr: addr}
+	_, err := d.DialContext(context.Background(), "tcp", "example.com:80")
+	var re SOCKSReplyError
+	if !errors.As(err, &re) || re != 5 {
+		t.Fatalf("expected connection refused reply, got %v", err)
+	}
+}
+
+func TestSOCKS5ContextCancel(t *testing.T) {
+	// A proxy that accepts but never answers.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		c, err := ln.Accept()
+		if err == nil {
+			defer c.Close()
+			io.Copy(io.Discard, c)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	d := &SOCKS5Dialer{ProxyAddr: ln.Addr().String()}
+	if _, err := d.DialContext(ctx, "tcp", "example.com:80"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
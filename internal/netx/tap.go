@@ -0,0 +1,59 @@
+package netx
+
+import (
+	"io"
+	"net"
+)
+
+// Tap returns a connection that mirrors every byte read from c to in and
+// every byte written to c to out, for capturing raw wire traffic while
+// debugging. Either writer may be nil. Mirror write errors are ignored so
+// a broken capture never breaks the connection.
+func Tap(c net.Conn, in, out io.Writer) net.Conn {
+	return &tapConn{Conn: c, in: in, out: out}
+}
+
+type tapConn struct {
+	net.Conn
+	in, out io.Writer
+}
+
+func (c *tapConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	if n > 0 && c.in != nil {
+		c.in.Write(p[:n])
+	}
+	return n, err
+}
+
+func (c *tapConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	if n > 0 && c.out != nil {
+		c.out.Write(p[:n])
+	}
+	return n, err
+}
+
+// TapListener wraps l so that hook chooses, per accepted connection, where
+// its traffic is mirrored. A hook returning two nil writers leaves the
+// connection untapped.
+func TapListener(l net.Listener, hook func(c net.Conn) (in, out io.Writer)) net.Listener {
+	return &tapListener{Listener: l, hook: hook}
+}
+
+type tapListener struct {
+	net.Listener
+	hook func(net.Conn) (io.Writer, io.Writer)
+}
+
+func (l *tapListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	in, out := l.hook(c)
+	if in == nil && out == nil {
+		return c, nil
+	}
+	return Tap(c, in, out), nil
+}
@@ -0,0 +1,80 @@
+package netx
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"sync"
+	"testing"
+)
+
+// lockedBuffer is a bytes.Buffer safe for concurrent writers.
+type lockedBuffer struct {
+	mu sync.Mutex
+	b  bytes.Buffer
+}
+
+func (l *lockedBuffer) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.b.Write(p)
+}
+
+func (l *lockedBuffer) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.b.String()
+}
+
+func TestTapListener(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var in, out lockedBuffer
+	tl := TapListener(ln, func(net.Conn) (io.Writer, io.Writer) { return &in, &out })
+	defer tl.Close()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c, err := tl.Accept()
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		buf := make([]byte, 4)
+		io.ReadFull(c, buf)
+		c.Write([]byte("pong"))
+	}()
+
+	c, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	c.Write([]byte("ping"))
+	buf := make([]byte, 4)
+	if _, err := io.ReadFull(c, buf); err != nil {
+		t.Fatal(err)
+	}
+	<-done
+	if in.String() != "ping" || out.String() != "pong" {
+		t.Fatalf("captured in=%q out=%q", in.String(), out.String())
+	}
+}
+
+func TestTapUntapped(t *testing.T) {
+	a, b := net.Pipe()
+	defer a.Close()
+	defer b.Close()
+	var out bytes.Buffer
+	tc := Tap(a, nil, &out)
+	go b.Read(make([]byte, 2))
+	if _, err := tc.Write([]byte("hi")); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "hi" {
+		t.Fatalf("got %q", out.String())
+	}
+}
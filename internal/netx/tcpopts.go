@@ -0,0 +1,124 @@
+package netx
+
+import (
+	"context"
+	"net"
+	"syscall"
+	"time"
+)
+
+// TCPOptions are socket options applied to accepted and dialed TCP
+// connections. The zero value keeps Go's defaults (TCP_NODELAY on, 15s
+// keep-alive probes, kernel buffer sizes).
+type TCPOptions struct {
+	// DisableNoDelay turns Nagle's algorithm back on. Go sets
+	// TCP_NODELAY by default, which suits request/response traffic.
+	DisableNoDelay bool
+
+	// KeepAlive configures TCP keep-alive probes. The zero value keeps
+	// Go's defaults; set Enable false with Idle -1 to turn probes off.
+	KeepAlive net.KeepAliveConfig
+
+	// ReadBuffer and WriteBuffer set SO_RCVBUF and SO_SNDBUF when positive.
+	ReadBuffer  int
+	WriteBuffer int
+
+	// DeferAccept sets TCP_DEFER_ACCEPT on listeners so Accept only
+	// returns once the client has sent data, for up to this long. It is
+	// honored on Linux and ignored elsewhere.
+	DeferAccept time.Duration
+}
+
+// Apply sets the options on c. Connections that are not TCP are left alone.
+func (o TCPOptions) Apply(c net.Conn) error {
+	tc, ok := c.(*net.TCPConn)
+	if !ok {
+		return nil
+	}
+	if o.DisableNoDelay {
+		if err := tc.SetNoDelay(false); err != nil {
+			return err
+		}
+	}
+	if o.KeepAlive != (net.KeepAliveConfig{}) {
+		if err := tc.SetKeepAliveConfig(o.KeepAlive); err != nil {
+			return err
+		}
+	}
+	if o.ReadBuffer > 0 {
+		if err := tc.SetReadBuffer(o.ReadBuffer); err != nil {
+			return err
+		}
+	}
+	if o.WriteBuffer > 0 {
+		if err := tc.SetWriteBuffer(o.WriteBuffer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Listen opens a TCP listener with DeferAccept applied, whose accepted
+// connections carry the other options.
+func (o TCPOptions) Listen(ctx context.Context, network, addr string) (net.Listener, error) {
+	lc := net.ListenConfig{KeepAliveConfig: o.KeepAlive}
+	if o.DeferAccept > 0 {
+		secs := int((o.DeferAccept + time.Second - 1) / time.Second)
+		lc.Control = func(_, _ string, rc syscall.RawConn) error {
+			var serr error
+			if err := rc.Control(func(fd uintptr) { serr = setDeferAccept(fd, secs) }); err != nil {
+				return err
+			}
+			return serr
+		}
+	}
+	l, err := lc.Listen(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+	return TuneListener(l, o), nil
+}
+
+// TuneListener returns a listener that applies o to every accepted
+// connection. A connection whose options cannot be set is closed and
+// skipped rather than failing Accept.
+func TuneListener(l net.Listener, o TCPOptions) net.Listener {
+	return &tunedListener{Listener: l, opts: o}
+}
+
+type tunedListener struct {
+	net.Listener
+	opts TCPOptions
+}
+
+func (l *tunedListener) Accept() (net.Conn, error) {
+	for {
+		c, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		if err := l.opts.Apply(c); err != nil {
+			c.Close()
+			continue
+		}
+		return c, nil
+	}
+}
+
+// Dialer returns a dial function applying o to each new connection.
+func (o TCPOptions) Dialer(d *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	if d == nil {
+		d = &net.Dialer{}
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		c, err := d.DialContext(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		if err := o.Apply(c); err != nil {
+			c.Close()
+			return nil, err
+		}
+		return c, nil
+	}
+}
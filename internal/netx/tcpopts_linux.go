@@ -0,0 +1,7 @@
+package netx
+
+import "syscall"
+
+func setDeferAccept(fd uintptr, secs int) error {
+	return syscall.SetsockoptInt(int(fd), syscall.IPPROTO_TCP, syscall.TCP_DEFER_ACCEPT, secs)
+}
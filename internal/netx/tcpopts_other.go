@@ -0,0 +1,6 @@
+//go:build !linux
+
+package netx
+
+// setDeferAccept is a no-op where TCP_DEFER_ACCEPT does not exist.
+func setDeferAccept(fd uintptr, secs int) error { return nil }
@@ -0,0 +1,56 @@
+package netx
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestTCPOptionsListenAndDial(t *testing.T) {
+	opts := TCPOptions{
+		KeepAlive:   net.KeepAliveConfig{Enable: true, Idle: 30 * time.Second, Interval: 5 * time.Second, Count: 3},
+		ReadBuffer:  64 << 10,
+		WriteBuffer: 64 << 10,
+		DeferAccept: time.Second,
+	}
+	ln, err := opts.Listen(context.Background(), "tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		c, err := ln.Accept()
+		if err == nil {
+			accepted <- c
+		}
+	}()
+
+	dial := TCPOptions{DisableNoDelay: true}.Dialer(nil)
+	c, err := dial(context.Background(), "tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	// With TCP_DEFER_ACCEPT the server only sees the connection once
+	// data arrives.
+	c.Write([]byte("x"))
+
+	select {
+	case sc := <-accepted:
+		sc.Close()
+	case <-time.After(5 * time.Second):
+		t.Fatal("accept did not complete")
+	}
+}
+
+func TestTCPOptionsApplyNonTCP(t *testing.T) {
+	a, b := net.Pipe()
+	defer a.Close()
+	defer b.Close()
+	if err := (TCPOptions{DisableNoDelay: true, ReadBuffer: 1}).Apply(a); err != nil {
+		t.Fatalf("non-TCP connections should be ignored: %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package netx
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"sync"
+	"time"
+)
+
+// DefaultTicketKeysKept is the number of session ticket keys a
+// TicketKeyRotator retains when Keep is zero.
+const DefaultTicketKeysKept = 3
+
+// TicketKeyRotator rotates the session ticket keys of a server tls.Config.
+// Each rotation installs a fresh random key for issuing tickets and keeps
+// the previous Keep-1 keys for resuming sessions, so a ticket stays usable
+// for roughly Keep rotation intervals. Replicas that must resume each
+// other's sessions need shared keys and should not use this type.
+type TicketKeyRotator struct {
+	Config *tls.Config
+	Keep   int // keys retained, newest first; DefaultTicketKeysKept if zero
+
+	mu   sync.Mutex
+	keys [][32]byte
+}
+
+// Rotate generates a new key and installs it.
+func (r *TicketKeyRotator) Rotate() error {
+	var k [32]byte
+	if _, err := rand.Read(k[:]); err != nil {
+		return err
+	}
+	keep := r.Keep
+	if keep <= 0 {
+		keep = DefaultTicketKeysKept
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keys = append([][32]byte{k}, r.keys...)
+	if len(r.keys) > keep {
+		r.keys = r.keys[:keep]
+	}
+	r.Config.SetSessionTicketKeys(r.keys)
+	return nil
+}
+
+// Run rotates immediately and then every interval until ctx is done, at
+// which point it returns ctx.Err().
+func (r *TicketKeyRotator) Run(ctx context.Context, interval time.Duration) error {
+	if err := r.Rotate(); err != nil {
+		return err
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+			if err := r.Rotate(); err != nil {
+				return err
+			}
+		}
+	}
+}
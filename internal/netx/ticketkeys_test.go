@@ -0,0 +1,86 @@
+package netx
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestTicketKeyRotatorKeepsNewest(t *testing.T) {
+	r := &TicketKeyRotator{Config: &tls.Config{}, Keep: 2}
+	var seen [][32]byte
+	for i := 0; i < 4; i++ {
+		if err := r.Rotate(); err != nil {
+			t.Fatal(err)
+		}
+		seen = append(seen, r.keys[0])
+	}
+	if len(r.keys) != 2 || r.keys[0] != seen[3] || r.keys[1] != seen[2] {
+		t.Fatal("rotator should keep the two newest keys, newest first")
+	}
+}
+
+func TestTicketKeyRotatorResumption(t *testing.T) {
+	tlsCert, cert := selfSignedCert(t)
+	srvCfg := &tls.Config{Certificates: []tls.Certificate{tlsCert}, MaxVersion: tls.VersionTLS12}
+	r := &TicketKeyRotator{Config: srvCfg, Keep: 2}
+	if err := r.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", srvCfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			c.Write([]byte("x"))
+			c.Close()
+		}
+	}()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	cliCfg := &tls.Config{RootCAs: pool, ClientSessionCache: tls.NewLRUClientSessionCache(4)}
+	resumed := func() bool {
+		c, err := tls.Dial("tcp", ln.Addr().String(), cliCfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer c.Close()
+		io.ReadAll(c)
+		return c.ConnectionState().DidResume
+	}
+
+	resumed() // obtain a ticket
+	r.Rotate()
+	if !resumed() {
+		t.Fatal("ticket from the previous key should still resume")
+	}
+	r.Rotate()
+	r.Rotate()
+	if resumed() {
+		t.Fatal("ticket older than Keep rotations should not resume")
+	}
+}
+
+func TestTicketKeyRotatorRun(t *testing.T) {
+	r := &TicketKeyRotator{Config: &tls.Config{}}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if err := r.Run(ctx, 5*time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if len(r.keys) != DefaultTicketKeysKept {
+		t.Fatalf("expected %d keys after several rotations, got %d", DefaultTicketKeysKept, len(r.keys))
+	}
+}
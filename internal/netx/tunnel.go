@@ -0,0 +1,171 @@
+package netx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrTunnelIdle is returned by Tunnel when neither direction carried data
+// for the configured idle timeout.
+var ErrTunnelIdle = errors.New("netx: tunnel idle timeout")
+
+// TunnelOptions configures Tunnel.
+type TunnelOptions struct {
+	// IdleTimeout ends the tunnel once neither direction has carried data
+	// for this long. Zero disables it. Watching for activity means every
+	// read goes through Tunnel, which gives up the kernel splice fast path.
+	IdleTimeout time.Duration
+}
+
+// TunnelStats reports how many bytes a tunnel carried each way.
+type TunnelStats struct {
+	AToB int64 // bytes read from a and written to b
+	BToA int64 // bytes read from b and written to a
+}
+
+// Tunnel copies data between a and b in both directions until both sides
+// have finished, as for CONNECT, WebSocket proxying or a hijacked
+// connection. Copies go through io.Copy, so TCP-to-TCP tunnels use splice
+// where the platform supports it.
+//
+// When one side reaches EOF, the other is half-closed (CloseWrite) so the
+// peer sees the end of stream while the opposite direction drains; conns
+// that cannot half-close are closed instead, ending the tunnel. A copy
+// error, ctx being done or the idle timeout tears both directions down at
+// once. Tunnel closes a and b before returning.
+//
+// The error is nil when both directions ended cleanly, ctx.Err() after
+// cancellation, ErrTunnelIdle after the idle timeout, or the first copy
+// error otherwise.
+func Tunnel(ctx context.Context, a, b net.Conn, opts TunnelOptions) (TunnelStats, error) {
+	t := &tunnel{a: a, b: b}
+	defer a.Close()
+	defer b.Close()
+
+	stop := context.AfterFunc(ctx, func() { t.abort(ctx.Err()) })
+	defer stop()
+
+	if opts.IdleTimeout > 0 {
+		t.idle = opts.IdleTimeout
+		t.touch()
+		// Store the timer before arming it: checkIdle re-arms through it.
+		timer := time.AfterFunc(time.Hour, t.checkIdle)
+		timer.Stop()
+		t.timer.Store(timer)
+		timer.Reset(opts.IdleTimeout)
+		defer timer.Stop()
+	}
+
+	var stats TunnelStats
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		stats.AToB = t.pipe(b, a)
+	}()
+	go func() {
+		defer wg.Done()
+		stats.BToA = t.pipe(a, b)
+	}()
+	wg.Wait()
+	return stats, t.err()
+}
+
+type tunnel struct {
+	a, b net.Conn
+
+	idle     time.Duration
+	lastSeen atomic.Int64 // unix nanos of the last successful read
+	timer    atomic.Pointer[time.Timer]
+
+	mu      sync.Mutex
+	torn    bool  // deadlines were expired to stop both copies
+	cause   error // why the tunnel was torn down, if not a copy error
+	copyErr error // first copy error seen
+}
+
+// pipe copies src to dst, then half-closes dst.
+func (t *tunnel) pipe(dst, src net.Conn) int64 {
+	var r io.Reader = src
+	if t.idle > 0 {
+		r = &activityReader{r: src, t: t}
+	}
+	n, err := io.Copy(dst, r)
+	if err != nil {
+		t.fail(err)
+		return n
+	}
+	if cw, ok := dst.(interface{ CloseWrite() error }); ok {
+		if err := cw.CloseWrite(); err != nil {
+			t.fail(err)
+		}
+		return n
+	}
+	t.abort(nil)
+	return n
+}
+
+// abort tears both directions down by expiring their deadlines. cause is
+// reported as the tunnel's error unless an earlier one was recorded.
+func (t *tunnel) abort(cause error) {
+	t.mu.Lock()
+	if !t.torn {
+		t.torn, t.cause = true, cause
+	}
+	t.mu.Unlock()
+	past := time.Unix(1, 0)
+	t.a.SetDeadline(past)
+	t.b.SetDeadline(past)
+}
+
+// fail records a copy error and tears the tunnel down. Errors caused by
+// the teardown itself are not recorded.
+func (t *tunnel) fail(err error) {
+	t.mu.Lock()
+	if t.copyErr == nil && !errors.Is(err, net.ErrClosed) && !(t.torn && errors.Is(err, os.ErrDeadlineExceeded)) {
+		t.copyErr = err
+	}
+	t.mu.Unlock()
+	t.abort(nil)
+}
+
+func (t *tunnel) err() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.cause != nil {
+		return t.cause
+	}
+	return t.copyErr
+}
+
+func (t *tunnel) touch() { t.lastSeen.Store(time.Now().UnixNano()) }
+
+// checkIdle runs from the idle timer: it aborts the tunnel if it has been
+// quiet for the whole timeout, and otherwise re-arms for the remainder.
+func (t *tunnel) checkIdle() {
+	quiet := time.Since(time.Unix(0, t.lastSeen.Load()))
+	if quiet >= t.idle {
+		t.abort(ErrTunnelIdle)
+		return
+	}
+	t.timer.Load().Reset(t.idle - quiet)
+}
+
+type activityReader struct {
+	r io.Reader
+	t *tunnel
+}
+
+func (r *activityReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.t.touch()
+	}
+	return n, err
+}
@@ -0,0 +1,127 @@
+package netx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// tcpPair returns the two ends of a loopback TCP connection.
+func tcpPair(t *testing.T) (net.Conn, net.Conn) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	c, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, s
+}
+
+func TestTunnelHalfClose(t *testing.T) {
+	client, a := tcpPair(t)
+	b, upstream := tcpPair(t)
+	defer client.Close()
+	defer upstream.Close()
+
+	done := make(chan struct{})
+	var stats TunnelStats
+	var terr error
+	go func() {
+		stats, terr = Tunnel(context.Background(), a, b, TunnelOptions{})
+		close(done)
+	}()
+
+	// The client finishes sending; the upstream sees EOF but can still answer.
+	client.Write([]byte("request"))
+	client.(*net.TCPConn).CloseWrite()
+	got, err := io.ReadAll(upstream)
+	if err != nil || string(got) != "request" {
+		t.Fatalf("upstream read %q %v", got, err)
+	}
+	upstream.Write([]byte("a longer response"))
+	upstream.Close()
+	got, err = io.ReadAll(client)
+	if err != nil || string(got) != "a longer response" {
+		t.Fatalf("client read %q %v", got, err)
+	}
+
+	<-done
+	if terr != nil || stats.AToB != 7 || stats.BToA != 17 {
+		t.Fatalf("stats %+v, err %v", stats, terr)
+	}
+}
+
+func TestTunnelCancel(t *testing.T) {
+	client, a := net.Pipe()
+	b, upstream := net.Pipe()
+	defer client.Close()
+	defer upstream.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() {
+		_, err := Tunnel(ctx, a, b, TunnelOptions{})
+		errc <- err
+	}()
+	cancel()
+	select {
+	case err := <-errc:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Tunnel did not return after cancel")
+	}
+}
+
+func TestTunnelIdleTimeout(t *testing.T) {
+	client, a := tcpPair(t)
+	b, upstream := tcpPair(t)
+	defer client.Close()
+	defer upstream.Close()
+
+	errc := make(chan error, 1)
+	go func() {
+		_, err := Tunnel(context.Background(), a, b, TunnelOptions{IdleTimeout: 100 * time.Millisecond})
+		errc <- err
+	}()
+
+	// Steady traffic in one direction keeps the tunnel alive.
+	buf := make([]byte, 1)
+	for i := 0; i < 4; i++ {
+		time.Sleep(50 * time.Millisecond)
+		client.Write([]byte("x"))
+		if _, err := io.ReadFull(upstream, buf); err != nil {
+			t.Fatal(err)
+		}
+	}
+	select {
+	case err := <-errc:
+		t.Fatalf("tunnel ended while active: %v", err)
+	default:
+	}
+
+	select {
+	case err := <-errc:
+		if !errors.Is(err, ErrTunnelIdle) {
+			t.Fatalf("got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("idle tunnel was not closed")
+	}
+	if _, err := io.ReadAll(client); err != nil && !strings.Contains(err.Error(), "reset") {
+		t.Fatalf("client after idle close: %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package netx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strings"
+)
+
+// ErrSocketInUse is returned by ListenUnix when another process is
+// accepting on the socket path.
+var ErrSocketInUse = errors.New("netx: unix socket already in use")
+
+// SplitUnixURL extracts the socket path from a "unix:///path/to/sock" URL.
+// ok is false for anything else.
+func SplitUnixURL(s string) (path string, ok bool) {
+	path, ok = strings.CutPrefix(s, "unix://")
+	if !ok || !strings.HasPrefix(path, "/") {
+		return "", false
+	}
+	return path, true
+}
+
+// ListenUnix listens on a Unix domain socket at path and sets its
+// permissions to perm (zero keeps the umask default). A stale socket file
+// left by a crashed process is removed first; a live one yields
+// ErrSocketInUse, and a non-socket file at path is never touched. The
+// socket file is removed when the listener is closed.
+func ListenUnix(path string, perm fs.FileMode) (net.Listener, error) {
+	if err := removeStaleSocket(path); err != nil {
+		return nil, err
+	}
+	ln, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	if err != nil {
+		return nil, err
+	}
+	// net.UnixListener unlinks the socket file on Close.
+	if perm != 0 {
+		if err := os.Chmod(path, perm); err != nil {
+			ln.Close()
+			return nil, err
+		}
+	}
+	return ln, nil
+}
+
+func removeStaleSocket(path string) error {
+	fi, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if fi.Mode()&fs.ModeSocket == 0 {
+		return fmt.Errorf("netx: %s exists and is not a socket", path)
+	}
+	if c, err := net.Dial("unix", path); err == nil {
+		c.Close()
+		return fmt.Errorf("%w: %s", ErrSocketInUse, path)
+	}
+	return os.Remove(path)
+}
+
+// UnixDialer returns a dial function that connects to the socket at path
+// whatever address it is asked for, so a client can send requests for
+// e.g. "http://localhost/..." over a sidecar's socket.
+func UnixDialer(path string) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, _, _ string) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, "unix", path)
+	}
+}
@@ -0,0 +1,92 @@
+package netx
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSplitUnixURL(t *testing.T) {
+	if p, ok := SplitUnixURL("unix:///run/app.sock"); !ok || p != "/run/app.sock" {
+		t.Fatalf("got %q %v", p, ok)
+	}
+	for _, s := range []string{"unix://relative.sock", "http://x/", "/run/app.sock"} {
+		if _, ok := SplitUnixURL(s); ok {
+			t.Fatalf("%q should not parse", s)
+		}
+	}
+}
+
+func TestListenUnix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.sock")
+	ln, err := ListenUnix(path, 0o660)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(path)
+	if err != nil || fi.Mode().Perm() != 0o660 {
+		t.Fatalf("socket mode = %v, %v", fi.Mode(), err)
+	}
+
+	// A live socket must not be stolen.
+	if _, err := ListenUnix(path, 0); !errors.Is(err, ErrSocketInUse) {
+		t.Fatalf("expected ErrSocketInUse, got %v", err)
+	}
+
+	// The in-use probe above is still queued, so serve every connection.
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			c.Write([]byte("hi"))
+			c.Close()
+		}
+	}()
+	c, err := UnixDialer(path)(context.Background(), "tcp", "localhost:80")
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 2)
+	if _, err := c.Read(buf); err != nil || string(buf) != "hi" {
+		t.Fatalf("read %q %v", buf, err)
+	}
+	c.Close()
+
+	ln.Close()
+	if _, err := os.Lstat(path); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("socket file should be removed on Close, got %v", err)
+	}
+}
+
+func TestListenUnixStaleAndForeign(t *testing.T) {
+	dir := t.TempDir()
+
+	// Leave a stale socket behind, as a crashed process would.
+	stale := filepath.Join(dir, "stale.sock")
+	l, err := net.ListenUnix("unix", &net.UnixAddr{Name: stale, Net: "unix"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.SetUnlinkOnClose(false)
+	l.Close()
+	ln, err := ListenUnix(stale, 0)
+	if err != nil {
+		t.Fatalf("stale socket should be replaced: %v", err)
+	}
+	ln.Close()
+
+	regular := filepath.Join(dir, "file")
+	os.WriteFile(regular, []byte("keep me"), 0o600)
+	if _, err := ListenUnix(regular, 0); err == nil {
+		t.Fatal("regular file must not be replaced")
+	}
+	if b, _ := os.ReadFile(regular); string(b) != "keep me" {
+		t.Fatal("regular file was modified")
+	}
+}